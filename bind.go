@@ -0,0 +1,292 @@
+package formbuilder
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// bind.go 实现提交数据到结构体的绑定
+// 先按组件类型规范化提交数据，再按结构体字段的form标签赋值
+
+// 绑定失败的错误信息
+var (
+	errBindType     = errors.New("数据类型不正确")
+	errBindOverflow = errors.New("数值超出范围")
+)
+
+// timeType time.Time的反射类型
+var timeType = reflect.TypeOf(time.Time{})
+
+// Bind 将提交数据绑定到结构体
+// dst必须是结构体指针，字段通过form标签与表单字段对应，没有form标签或标签为"-"的字段会被忽略；
+// 只绑定表单中声明且处于显示状态的字段，未声明的字段和未激活control分支中的字段即使被提交也不会赋值，
+// 防止客户端覆盖结构体中不属于表单的字段；
+// 数据先经过Normalize按组件类型转换（Checkbox为切片、DatePicker为time.Time、Cascader为路径切片等），
+// 再转换为结构体字段的类型，实现了encoding.TextUnmarshaler的类型（如decimal）按文本解析。
+// 转换失败的字段以FieldErrors返回，其余字段仍会被赋值。
+// Bind不执行清理器，传入的数据应来自ParseRequest或已经过Sanitize
+//
+// 使用示例：
+//
+//	type Product struct {
+//	    Name     string    `form:"name"`
+//	    Price    float64   `form:"price"`
+//	    Tags     []string  `form:"tags"`
+//	    OnSale   time.Time `form:"on_sale"`
+//	    Category []int     `form:"category"`
+//	}
+//
+//	var p Product
+//	if err := form.Bind(values, &p); err != nil {
+//	    // err为FieldErrors，与ValidateData的错误格式相同
+//	}
+func (f *Form) Bind(values map[string]interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind destination must be a non-nil struct pointer, got %T", dst)
+	}
+
+	normalized, err := f.Normalize(values)
+	var errs FieldErrors
+	failed := make(map[string]bool)
+	if err != nil {
+		errs = append(errs, err.(FieldErrors)...)
+		for _, fe := range errs {
+			failed[fe.Field] = true
+		}
+	}
+
+	active := make(map[string]bool)
+	for _, field := range f.ActiveFields(values) {
+		active[field] = true
+	}
+
+	for _, bf := range bindFields(rv.Elem().Type(), nil, make(map[reflect.Type]bool)) {
+		value, ok := normalized[bf.name]
+		if !ok || failed[bf.name] || !active[bf.name] {
+			continue
+		}
+		if err := assignValue(fieldByIndex(rv.Elem(), bf.index), value); err != nil {
+			errs = append(errs, &FieldError{Field: bf.name, Rule: "type", Message: err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// BindRequest 解析请求数据并绑定到结构体
+// 请求数据通过ParseRequest解析，未在表单中声明的字段被忽略
+func (f *Form) BindRequest(r *http.Request, dst interface{}) error {
+	values, err := ParseRequest(f, r)
+	if err != nil {
+		return err
+	}
+	return f.Bind(values, dst)
+}
+
+// boundField 结构体中带form标签的字段
+type boundField struct {
+	name  string
+	index []int // 字段在结构体中的索引路径，经过匿名嵌入的结构体时包含多级
+}
+
+// bindFields 收集结构体类型中带form标签的字段
+// 匿名嵌入的结构体（包括结构体指针）会被展开
+func bindFields(t reflect.Type, prefix []int, visiting map[reflect.Type]bool) []boundField {
+	if visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var fields []boundField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("form")
+		name := strings.Split(tag, ",")[0]
+		index := append(append([]int(nil), prefix...), i)
+
+		if sf.Anonymous && tag == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct && sf.IsExported() {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, bindFields(ft, index, visiting)...)
+			}
+			continue
+		}
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, boundField{name: name, index: index})
+	}
+	return fields
+}
+
+// fieldByIndex 按索引路径取得字段
+// 只在需要赋值时为路径上为nil的嵌入结构体指针分配内存，没有被赋值的嵌入指针保持nil
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// assignValue 将值转换为目标类型并赋值
+func assignValue(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(elem.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+
+	if dst.Type() == timeType {
+		t, ok := parseDateValue(src)
+		if !ok {
+			return errNotDate
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if u, ok := textUnmarshaler(dst); ok {
+		s, ok := stringValue(src)
+		if !ok {
+			return errBindType
+		}
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return errBindType
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		if s, ok := stringValue(src); ok {
+			dst.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, err := normalizeBool(src); err == nil {
+			dst.SetBool(b.(bool))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := normalizeInt(src)
+		if err != nil {
+			return err
+		}
+		if i == nil {
+			dst.SetInt(0)
+			return nil
+		}
+		n := i.(int64)
+		if dst.OverflowInt(n) {
+			return errBindOverflow
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := normalizeInt(src)
+		if err != nil {
+			return err
+		}
+		if i == nil {
+			dst.SetUint(0)
+			return nil
+		}
+		n := i.(int64)
+		if n < 0 || dst.OverflowUint(uint64(n)) {
+			return errBindOverflow
+		}
+		dst.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := normalizeFloat(src, 0)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			dst.SetFloat(0)
+			return nil
+		}
+		if dst.OverflowFloat(f.(float64)) {
+			return errBindOverflow
+		}
+		dst.SetFloat(f.(float64))
+		return nil
+	case reflect.Slice, reflect.Array:
+		return assignList(dst, src)
+	}
+	return errBindType
+}
+
+// assignList 将数组值逐个元素赋值给切片或数组
+// 单个值视为只有一个元素的数组
+func assignList(dst reflect.Value, src interface{}) error {
+	items, ok := sliceItems(src)
+	if !ok {
+		items = []interface{}{src}
+	}
+	if s, ok := src.(string); ok && s == "" {
+		items = nil
+	}
+
+	if dst.Kind() == reflect.Array {
+		if len(items) != dst.Len() {
+			return errBindType
+		}
+		for i, item := range items {
+			if err := assignValue(dst.Index(i), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	list := reflect.MakeSlice(dst.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := assignValue(list.Index(i), item); err != nil {
+			return err
+		}
+	}
+	dst.Set(list)
+	return nil
+}
+
+// textUnmarshaler 返回目标值的encoding.TextUnmarshaler实现
+func textUnmarshaler(dst reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if !dst.CanAddr() {
+		return nil, false
+	}
+	u, ok := dst.Addr().Interface().(encoding.TextUnmarshaler)
+	return u, ok
+}
@@ -0,0 +1,210 @@
+package formbuilder
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bind_test.go 测试提交数据到结构体的绑定
+
+// testCents 测试用的文本解析类型
+type testCents int64
+
+// UnmarshalText 实现encoding.TextUnmarshaler接口
+func (c *testCents) UnmarshalText(text []byte) error {
+	f, ok := toFloat(string(text))
+	if !ok {
+		return errors.New("invalid amount")
+	}
+	*c = testCents(f*100 + 0.5)
+	return nil
+}
+
+// testAudit 测试用的嵌入结构体
+type testAudit struct {
+	Remark string `form:"remark"`
+}
+
+// testProduct 测试用的绑定目标
+type testProduct struct {
+	testAudit
+	Name     string       `form:"name"`
+	Qty      int          `form:"qty"`
+	Price    testCents    `form:"price"`
+	OnSale   bool         `form:"on_sale"`
+	Status   int8         `form:"status"`
+	Tags     []string     `form:"tags"`
+	Area     []int        `form:"area"`
+	Period   [2]time.Time `form:"period"`
+	Release  *time.Time   `form:"release"`
+	Extra    interface{}  `form:"extra"`
+	Ignored  string       `form:"-"`
+	Untagged string
+}
+
+// createBindForm 创建测试用的表单
+func createBindForm() *Form {
+	return NewElmForm("/submit", []Component{
+		NewInput("name", "名称"),
+		NewInputNumber("qty", "数量").Precision(0),
+		NewInputNumber("price", "价格").Precision(2),
+		NewSwitch("on_sale", "上架"),
+		NewSwitch("status", "状态").ActiveValue(1).InactiveValue(0),
+		NewCheckbox("tags", "标签"),
+		NewCascader("area", "地区"),
+		NewDatePicker("period", "周期").DateType("daterange").ValueFormat("yyyy-MM-dd"),
+		NewDatePicker("release", "发布日期").ValueFormat("yyyy-MM-dd"),
+		NewInput("remark", "备注"),
+	}, nil)
+}
+
+// TestFormBind 测试结构体绑定
+func TestFormBind(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var p testProduct
+		p.Untagged = "keep"
+		err := createBindForm().Bind(map[string]interface{}{
+			"name":     "手机",
+			"qty":      float64(3),
+			"price":    9.99,
+			"on_sale":  true,
+			"status":   "1",
+			"tags":     []interface{}{"new", "hot"},
+			"area":     []interface{}{float64(11), float64(1101)},
+			"period":   []interface{}{"2024-05-01", "2024-05-07"},
+			"release":  "2024-06-01",
+			"remark":   "备注",
+			"extra":    map[string]interface{}{"a": "b"},
+			"Ignored":  "x",
+			"Untagged": "x",
+		}, &p)
+		require.NoError(t, err)
+
+		assert.Equal(t, "手机", p.Name)
+		assert.Equal(t, 3, p.Qty)
+		assert.Equal(t, testCents(999), p.Price)
+		assert.True(t, p.OnSale)
+		assert.Equal(t, int8(1), p.Status)
+		assert.Equal(t, []string{"new", "hot"}, p.Tags)
+		assert.Equal(t, []int{11, 1101}, p.Area)
+		assert.Equal(t, "2024-05-07", p.Period[1].Format("2006-01-02"))
+		require.NotNil(t, p.Release)
+		assert.Equal(t, "2024-06-01", p.Release.Format("2006-01-02"))
+		assert.Equal(t, "备注", p.Remark)
+		assert.Nil(t, p.Extra, "未在表单中声明的字段不绑定")
+		assert.Empty(t, p.Ignored)
+		assert.Equal(t, "keep", p.Untagged)
+	})
+
+	t.Run("FieldErrors", func(t *testing.T) {
+		var p testProduct
+		err := createBindForm().Bind(map[string]interface{}{
+			"name":   "手机",
+			"qty":    "abc",
+			"status": float64(1),
+			"area":   []interface{}{"bj"},
+		}, &p)
+
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Equal(t, map[string][]string{
+			"qty":  {"请输入数字"},
+			"area": {"请输入数字"},
+		}, errs.ByField())
+		assert.Equal(t, "手机", p.Name, "其余字段仍应被赋值")
+		assert.Equal(t, int8(1), p.Status)
+	})
+
+	t.Run("HiddenFields", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewSwitch("on_sale", "上架").Control([]ControlRule{
+				{Value: true, Rule: []Component{NewInputNumber("qty", "数量").Precision(0)}},
+			}),
+		}, nil)
+		var p testProduct
+		require.NoError(t, form.Bind(map[string]interface{}{"on_sale": false, "qty": float64(3)}, &p))
+		assert.Zero(t, p.Qty, "未激活分支中的字段不绑定")
+
+		require.NoError(t, form.Bind(map[string]interface{}{"on_sale": true, "qty": float64(3)}, &p))
+		assert.Equal(t, 3, p.Qty)
+	})
+
+	t.Run("EmbeddedPointer", func(t *testing.T) {
+		type Audit struct {
+			Remark string `form:"remark"`
+		}
+		type order struct {
+			*Audit
+			Name string `form:"name"`
+		}
+		var o order
+		require.NoError(t, createBindForm().Bind(map[string]interface{}{"name": "手机"}, &o))
+		assert.Nil(t, o.Audit, "没有对应的值时不分配嵌入的指针")
+
+		require.NoError(t, createBindForm().Bind(map[string]interface{}{"remark": "备注"}, &o))
+		require.NotNil(t, o.Audit)
+		assert.Equal(t, "备注", o.Remark)
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		var p testProduct
+		err := NewElmForm("/submit", []Component{NewInput("status", "状态")}, nil).
+			Bind(map[string]interface{}{"status": "300"}, &p)
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Equal(t, "数值超出范围", errs[0].Message)
+	})
+
+	t.Run("InvalidDestination", func(t *testing.T) {
+		var p testProduct
+		err := createBindForm().Bind(map[string]interface{}{}, p)
+		assert.Error(t, err)
+		var errs FieldErrors
+		assert.False(t, errors.As(err, &errs))
+	})
+}
+
+// TestFormBindRequest 测试从请求绑定
+func TestFormBindRequest(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/submit",
+			strings.NewReader(`{"name":"手机","qty":2,"tags":["a"]}`))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		var p testProduct
+		require.NoError(t, createBindForm().BindRequest(req, &p))
+		assert.Equal(t, 2, p.Qty)
+		assert.Equal(t, []string{"a"}, p.Tags)
+	})
+
+	t.Run("URLEncoded", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("name", "手机")
+		form.Set("qty", "5")
+		form.Add("tags[]", "a")
+		form.Set("on_sale", "on")
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var p testProduct
+		require.NoError(t, createBindForm().BindRequest(req, &p))
+		assert.Equal(t, 5, p.Qty)
+		assert.Equal(t, []string{"a"}, p.Tags)
+		assert.True(t, p.OnSale)
+	})
+
+	t.Run("BadJSON", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`{`))
+		req.Header.Set("Content-Type", "application/json")
+		var p testProduct
+		assert.Error(t, createBindForm().BindRequest(req, &p))
+	})
+}
@@ -0,0 +1,262 @@
+package formbuilder
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// captcha.go 实现Captcha验证码组件
+// 组件渲染为带验证码图片的输入框，挑战ID以隐藏字段随表单提交，
+// 点击图片时向图片接口请求新的挑战，接口签发的挑战ID写回隐藏字段。
+// 答案由CaptchaVerifier校验，ValidateData会自动执行，内置图片验证码见ImageCaptcha
+
+// DefaultCaptchaURL 验证码图片接口的默认地址前缀，完整地址为 前缀 + "/" + 字段名
+const DefaultCaptchaURL = "/formbuilder/captcha"
+
+// CaptchaIDSuffix 挑战ID隐藏字段的后缀，字段名为 验证码字段名 + 后缀
+const CaptchaIDSuffix = "_captcha_id"
+
+// errInvalidCaptcha 验证码错误
+var errInvalidCaptcha = errors.New("验证码错误或已过期")
+
+// CaptchaVerifier 验证码校验接口
+// 同时实现http.Handler时，Form.Mount会在图片地址上注册该Handler：
+// 请求参数id为挑战ID；带有new参数时应签发新的挑战ID并通过CaptchaIDHeader响应头返回，
+// 点击图片刷新验证码时使用
+type CaptchaVerifier interface {
+	// Verify 校验挑战ID对应的答案，答案错误或挑战不存在时返回错误
+	Verify(ctx context.Context, id, answer string) error
+}
+
+// CaptchaIssuer 签发挑战ID的校验器
+// CaptchaVerifier实现此接口时，Captcha组件的挑战ID由IssueID签发，否则使用随机ID
+type CaptchaIssuer interface {
+	IssueID() (string, error)
+}
+
+// Captcha 验证码组件
+// 每次渲染生成新的挑战ID，表单需要按请求创建。
+// ParseRules按验证规则中的标记还原验证码组件，但无法还原CaptchaVerifier，
+// 还原的组件在通过Verifier设置校验器之前校验总是失败
+//
+// 使用示例：
+//
+//	var captcha = formbuilder.NewImageCaptcha(nil)
+//
+//	Elm.Captcha("captcha", "验证码", captcha)
+type Captcha struct {
+	Builder[*Captcha]
+	verifier CaptchaVerifier
+	imageURL string
+	id       string
+}
+
+// NewCaptcha 创建验证码组件
+// imageURL为验证码图片地址，默认为 DefaultCaptchaURL + "/" + 字段名
+func NewCaptcha(field, title string, verifier CaptchaVerifier, imageURL ...string) *Captcha {
+	captcha := &Captcha{}
+	captcha.data = &ComponentData{
+		Field:    field,
+		Title:    title,
+		RuleType: "input",
+		Props: map[string]interface{}{
+			"type":         "text",
+			"placeholder":  "请输入验证码",
+			"autocomplete": "off",
+		},
+	}
+	captcha.imageURL = DefaultCaptchaURL + "/" + field
+	if len(imageURL) > 0 && imageURL[0] != "" {
+		captcha.imageURL = imageURL[0]
+	}
+	captcha.data.Validate = []ValidateRule{captchaRule{captcha: captcha}}
+	captcha.inst = captcha
+	return captcha.Verifier(verifier)
+}
+
+// Verifier 设置校验器并重新生成挑战ID
+// 用于为ParseRules、LoadFormRule还原的验证码组件设置校验器
+func (c *Captcha) Verifier(verifier CaptchaVerifier) *Captcha {
+	c.verifier = verifier
+	c.id = newCaptchaID()
+	if issuer, ok := verifier.(CaptchaIssuer); ok {
+		// 签发失败时挑战ID为空，校验总是失败，点击图片可以重新获取
+		c.id, _ = issuer.IssueID()
+	}
+	return c
+}
+
+// Placeholder 设置占位符文本
+func (c *Captcha) Placeholder(text string) *Captcha {
+	c.data.Props["placeholder"] = text
+	return c
+}
+
+// GetField 实现Component接口
+func (c *Captcha) GetField() string {
+	return c.data.Field
+}
+
+// GetType 实现Component接口
+func (c *Captcha) GetType() string {
+	return c.data.RuleType
+}
+
+// IDField 返回挑战ID隐藏字段的字段名
+func (c *Captcha) IDField() string {
+	return c.data.Field + CaptchaIDSuffix
+}
+
+// Build 实现Component接口
+// 验证码图片以append插槽加入输入框
+func (c *Captcha) Build() map[string]interface{} {
+	rule := buildComponent(c.data)
+	img := map[string]interface{}{
+		"type": "img",
+		"slot": "append",
+		"attrs": map[string]interface{}{
+			"src":   c.src(c.id),
+			"alt":   "验证码",
+			"title": "看不清？点击刷新",
+		},
+		"style": map[string]interface{}{
+			"display":       "block",
+			"height":        "30px",
+			"cursor":        "pointer",
+			"verticalAlign": "middle",
+		},
+		"on": map[string]interface{}{
+			"click": c.refreshScript(),
+		},
+	}
+	children, _ := rule["children"].([]map[string]interface{})
+	rule["children"] = append(children, img)
+	return rule
+}
+
+// endpoints 实现endpointProvider接口
+func (c *Captcha) endpoints() map[string]http.Handler {
+	handler, ok := c.verifier.(http.Handler)
+	if !ok {
+		return nil
+	}
+	return map[string]http.Handler{searchPath(c.imageURL): handler}
+}
+
+// src 返回挑战ID对应的图片地址
+func (c *Captcha) src(id string) string {
+	sep := "?"
+	if strings.Contains(c.imageURL, "?") {
+		sep = "&"
+	}
+	return c.imageURL + sep + "id=" + id
+}
+
+// refreshScript 返回点击图片时刷新验证码的函数
+// 带new参数请求图片接口，将响应头中新签发的挑战ID写入隐藏字段，清空已输入的答案并显示新图片
+func (c *Captcha) refreshScript() JSFunc {
+	return JSFunc(`function(e) {
+    var img = e.target;
+    var url = ` + jsLiteral(c.imageURL) + `;
+    fetch(url + (url.indexOf('?') < 0 ? '?' : '&') + 'new=1', {cache: 'no-store', credentials: 'same-origin'}).then(function(res) {
+        var id = res.headers.get(` + jsLiteral(CaptchaIDHeader) + `);
+        if (!res.ok || !id) {
+            throw new Error('captcha refresh failed');
+        }
+        return res.blob().then(function(blob) {
+            var api = window.$fApi;
+            if (api) {
+                api.setValue(` + jsLiteral(c.IDField()) + `, id);
+                api.setValue(` + jsLiteral(c.data.Field) + `, '');
+            }
+            if (img.src.indexOf('blob:') === 0) {
+                URL.revokeObjectURL(img.src);
+            }
+            img.src = URL.createObjectURL(blob);
+        });
+    }).catch(function(err) {
+        console.error(err);
+    });
+}`)
+}
+
+// loadRule 实现ruleLoader接口
+// 从验证码图片的地址还原imageURL并移除图片，图片和挑战ID由Build重新生成；
+// ParseRules还原的captchaRule关联到本组件
+func (c *Captcha) loadRule(rest map[string]interface{}) []string {
+	for i, rule := range c.data.Validate {
+		if _, ok := rule.(captchaRule); ok {
+			c.data.Validate[i] = captchaRule{captcha: c}
+		}
+	}
+	var children []Component
+	for _, child := range c.data.Children {
+		if url, ok := captchaImageURL(child); ok {
+			c.imageURL = url
+			continue
+		}
+		children = append(children, child)
+	}
+	c.data.Children = children
+	return []string{"children"}
+}
+
+// captchaImageURL 从Build生成的验证码图片中取出不含挑战ID的图片地址
+func captchaImageURL(child Component) (string, bool) {
+	if child.GetType() != "img" {
+		return "", false
+	}
+	img := child.Build()
+	attrs, _ := img["attrs"].(map[string]interface{})
+	src, _ := attrs["src"].(string)
+	i := strings.LastIndex(src, "id=")
+	if img["slot"] != "append" || i < 1 || src[i-1] != '?' && src[i-1] != '&' {
+		return "", false
+	}
+	return src[:i-1], true
+}
+
+// newCaptchaID 生成随机的挑战ID
+func newCaptchaID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// captchaRule 验证码组件的验证规则
+// 前端只检查必填，服务端调用CaptchaVerifier校验答案，未设置校验器时校验失败。
+// ParseRules按规则名称识别验证码组件
+type captchaRule struct {
+	captcha *Captcha
+}
+
+// ToMap 实现ValidateRule接口
+func (r captchaRule) ToMap() map[string]interface{} {
+	rule := map[string]interface{}{
+		"required": true,
+		"message":  "请输入验证码",
+		"trigger":  "blur",
+	}
+	return withRuleData(rule, "captcha", nil)
+}
+
+// Check 实现Checker接口
+// 挑战ID取自提交数据中的IDField字段
+func (r captchaRule) Check(value interface{}, vc *ValidateContext) error {
+	answer, _ := stringValue(value)
+	if strings.TrimSpace(answer) == "" {
+		return newRuleError("captcha", "", "请输入验证码")
+	}
+	if r.captcha == nil || r.captcha.verifier == nil {
+		return newRuleError("captcha", "", errInvalidCaptcha.Error())
+	}
+	id, _ := stringValue(vc.Values[r.captcha.IDField()])
+	if id == "" || r.captcha.verifier.Verify(vc.context(), id, answer) != nil {
+		return newRuleError("captcha", "", errInvalidCaptcha.Error())
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package formbuilder
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math/big"
+	mrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// captcha_image.go 实现图片验证码
+// 挑战由标准库image包绘制为PNG，答案保存在CaptchaStore中。
+// ImageCaptcha同时实现CaptchaVerifier和http.Handler，
+// 交给Captcha组件后由Form.Mount注册图片接口，ValidateData自动校验答案。
+// 挑战ID由服务端签发并带有HMAC，图片接口不接受客户端自行生成的ID
+
+// CaptchaMode 图片验证码的挑战类型
+type CaptchaMode int
+
+const (
+	// CaptchaDigits 识别图片中的数字
+	CaptchaDigits CaptchaMode = iota
+
+	// CaptchaMath 计算图片中的算式，如 "7+3=?"
+	CaptchaMath
+)
+
+// 图片验证码的默认配置
+const (
+	DefaultCaptchaLength = 4   // 数字验证码的默认位数
+	DefaultCaptchaWidth  = 120 // 图片默认宽度
+	DefaultCaptchaHeight = 40  // 图片默认高度
+)
+
+// CaptchaIDHeader 刷新验证码时返回新挑战ID的响应头
+const CaptchaIDHeader = "X-Captcha-Id"
+
+// captchaNonceSize 挑战ID中随机数和签名的字节数
+const captchaNonceSize = 16
+
+// ImageCaptcha 图片验证码
+//
+// 使用示例：
+//
+//	var captcha = formbuilder.NewImageCaptcha(formbuilder.NewMemoryCaptchaStore(0))
+//
+//	form := formbuilder.NewElmForm("/comment", []formbuilder.Component{
+//	    formbuilder.Elm.Textarea("content", "内容"),
+//	    formbuilder.Elm.Captcha("captcha", "验证码", captcha),
+//	}, nil)
+//	if err := form.Mount(mux); err != nil {
+//	    log.Fatal(err)
+//	}
+type ImageCaptcha struct {
+	// Store 挑战存储
+	Store CaptchaStore
+
+	// Mode 挑战类型，默认为CaptchaDigits
+	Mode CaptchaMode
+
+	// Length 数字验证码的位数，默认为DefaultCaptchaLength
+	Length int
+
+	// Width 图片宽度，默认为DefaultCaptchaWidth
+	Width int
+
+	// Height 图片高度，默认为DefaultCaptchaHeight
+	Height int
+
+	// Key 签发挑战ID的密钥，为空时使用进程内随机生成的密钥
+	// 多实例部署时需要设置相同的密钥，否则其他实例签发的ID无法获取图片
+	Key []byte
+
+	keyOnce sync.Once
+	key     []byte
+}
+
+// NewImageCaptcha 创建图片验证码
+// store为nil时使用有效期为DefaultCaptchaTTL的内存存储
+func NewImageCaptcha(store CaptchaStore) *ImageCaptcha {
+	if store == nil {
+		store = NewMemoryCaptchaStore(0)
+	}
+	return &ImageCaptcha{Store: store}
+}
+
+// Verify 实现CaptchaVerifier接口
+// 无论答案是否正确，挑战都会被删除，答错后需要刷新验证码
+func (c *ImageCaptcha) Verify(ctx context.Context, id, answer string) error {
+	expected, err := c.Store.Take(ctx, id)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(answer)), []byte(expected)) != 1 {
+		return errInvalidCaptcha
+	}
+	return nil
+}
+
+// IssueID 实现CaptchaIssuer接口
+// 挑战ID由随机数和Key对其的签名组成，ServeHTTP只为签名有效的ID生成挑战
+func (c *ImageCaptcha) IssueID() (string, error) {
+	nonce := make([]byte, captchaNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce) + hex.EncodeToString(c.signID(nonce)), nil
+}
+
+// ServeHTTP 输出验证码图片
+// 接收GET请求，id参数为IssueID签发的挑战ID，每次请求生成新的挑战并覆盖相同ID之前的答案；
+// 带有new参数时签发新的挑战ID，通过CaptchaIDHeader响应头返回，供前端刷新验证码。
+// 存储已满时返回503
+func (c *ImageCaptcha) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+		return
+	}
+	query := r.URL.Query()
+	id := query.Get("id")
+	if query.Has("new") {
+		var err error
+		if id, err = c.IssueID(); err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Code: http.StatusInternalServerError, Message: "生成验证码失败"})
+			return
+		}
+	} else if !c.validID(id) {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Code: http.StatusBadRequest, Message: "invalid captcha id"})
+		return
+	}
+
+	img, err := c.Generate(r.Context(), id)
+	if errors.Is(err, ErrCaptchaStoreFull) {
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Code: http.StatusServiceUnavailable, Message: "验证码请求过多，请稍后再试"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Code: http.StatusInternalServerError, Message: "生成验证码失败"})
+		return
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Code: http.StatusInternalServerError, Message: "生成验证码失败"})
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set(CaptchaIDHeader, id)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Generate 为id生成新的挑战，保存答案并返回绘制好的图片
+func (c *ImageCaptcha) Generate(ctx context.Context, id string) (image.Image, error) {
+	text, answer, err := c.challenge()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Store.Set(ctx, id, answer); err != nil {
+		return nil, err
+	}
+	return drawCaptcha(text, withDefaultInt(c.Width, DefaultCaptchaWidth), withDefaultInt(c.Height, DefaultCaptchaHeight)), nil
+}
+
+// challenge 生成挑战的显示文本和答案
+func (c *ImageCaptcha) challenge() (text, answer string, err error) {
+	if c.Mode == CaptchaMath {
+		return mathChallenge()
+	}
+	digits := make([]byte, withDefaultInt(c.Length, DefaultCaptchaLength))
+	for i := range digits {
+		n, err := randomInt(10)
+		if err != nil {
+			return "", "", err
+		}
+		digits[i] = byte('0' + n)
+	}
+	return string(digits), string(digits), nil
+}
+
+// mathChallenge 生成一位数的加、减、乘法算式，减法的结果不为负数
+func mathChallenge() (text, answer string, err error) {
+	var nums [3]int
+	for i, n := range []int{9, 9, 3} {
+		if nums[i], err = randomInt(n); err != nil {
+			return "", "", err
+		}
+	}
+	a, b := nums[0]+1, nums[1]+1
+	var result int
+	var op byte
+	switch nums[2] {
+	case 0:
+		op, result = '+', a+b
+	case 1:
+		if a < b {
+			a, b = b, a
+		}
+		op, result = '-', a-b
+	default:
+		op, result = 'x', a*b
+	}
+	return strconv.Itoa(a) + string(op) + strconv.Itoa(b) + "=?", strconv.Itoa(result), nil
+}
+
+// randomInt 返回[0, n)范围内的安全随机数
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
+
+// withDefaultInt 值不大于0时返回默认值
+func withDefaultInt(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// validID 检查挑战ID是否由IssueID签发
+func (c *ImageCaptcha) validID(id string) bool {
+	raw, err := hex.DecodeString(id)
+	if err != nil || len(raw) != captchaNonceSize*2 {
+		return false
+	}
+	return hmac.Equal(raw[captchaNonceSize:], c.signID(raw[:captchaNonceSize]))
+}
+
+// signID 计算挑战ID随机数部分的签名
+func (c *ImageCaptcha) signID(nonce []byte) []byte {
+	key := c.Key
+	if len(key) == 0 {
+		c.keyOnce.Do(func() {
+			c.key = make([]byte, 32)
+			_, _ = rand.Read(c.key)
+		})
+		key = c.key
+	}
+	return signHMAC(key, append([]byte("captcha:"), nonce...))[:captchaNonceSize]
+}
+
+// captchaGlyphs 5x7点阵字形，每行的低5位从左到右表示像素
+var captchaGlyphs = map[byte][7]uint8{
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'+': {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000},
+	'-': {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'x': {0b00000, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b00000},
+	'=': {0b00000, 0b00000, 0b11111, 0b00000, 0b11111, 0b00000, 0b00000},
+	'?': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+}
+
+// drawCaptcha 绘制验证码图片
+// 字符按可用空间放大并随机偏移，背景加入干扰点，前景加入干扰线
+func drawCaptcha(text string, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bg := color.RGBA{R: uint8(235 + mrand.IntN(20)), G: uint8(235 + mrand.IntN(20)), B: uint8(235 + mrand.IntN(20)), A: 255}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, bg)
+		}
+	}
+	for i := 0; i < width*height/12; i++ {
+		img.SetRGBA(mrand.IntN(width), mrand.IntN(height), randomInk(120))
+	}
+
+	// 每个字符占6列（5列字形加1列间距），按宽高中较小的比例放大
+	scale := max(1, min((width-8)/(len(text)*6), (height-6)/7))
+	left := (width - len(text)*6*scale) / 2
+	top := (height - 7*scale) / 2
+	for i := 0; i < len(text); i++ {
+		glyph := captchaGlyphs[text[i]]
+		ink := randomInk(0)
+		x0 := left + i*6*scale + mrand.IntN(scale+1) - scale/2
+		y0 := top + mrand.IntN(scale+1) - scale/2
+		for row, bits := range glyph {
+			for col := 0; col < 5; col++ {
+				if bits&(1<<(4-col)) == 0 {
+					continue
+				}
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						setPixel(img, x0+col*scale+dx, y0+row*scale+dy, ink)
+					}
+				}
+			}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		drawLine(img, 0, mrand.IntN(height), width-1, mrand.IntN(height), randomInk(40))
+	}
+	return img
+}
+
+// randomInk 返回随机的深色，base越大颜色越浅
+func randomInk(base int) color.RGBA {
+	return color.RGBA{R: uint8(base + mrand.IntN(100)), G: uint8(base + mrand.IntN(100)), B: uint8(base + mrand.IntN(100)), A: 255}
+}
+
+// setPixel 设置像素，超出图片范围时忽略
+func setPixel(img *image.RGBA, x, y int, c color.RGBA) {
+	if image.Pt(x, y).In(img.Rect) {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// drawLine 使用Bresenham算法绘制直线
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := x1-x0, -(y1 - y0)
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy > 0 {
+		dy = -dy
+	}
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	for e := dx + dy; ; {
+		setPixel(img, x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package formbuilder
+
+import (
+	"context"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captcha_image_test.go 测试图片验证码
+
+// failingCaptchaStore 写入总是失败的存储
+type failingCaptchaStore struct{}
+
+func (failingCaptchaStore) Set(ctx context.Context, id, answer string) error {
+	return errors.New("store down")
+}
+
+func (failingCaptchaStore) Take(ctx context.Context, id string) (string, error) {
+	return "", errors.New("store down")
+}
+
+// TestImageCaptchaChallenge 测试挑战的生成
+func TestImageCaptchaChallenge(t *testing.T) {
+	t.Run("Digits", func(t *testing.T) {
+		text, answer, err := (&ImageCaptcha{Length: 6}).challenge()
+		require.NoError(t, err)
+		assert.Regexp(t, `^\d{6}$`, text)
+		assert.Equal(t, text, answer)
+	})
+
+	t.Run("Math", func(t *testing.T) {
+		pattern := regexp.MustCompile(`^([1-9])([+\-x])([1-9])=\?$`)
+		for i := 0; i < 100; i++ {
+			text, answer, err := (&ImageCaptcha{Mode: CaptchaMath}).challenge()
+			require.NoError(t, err)
+			m := pattern.FindStringSubmatch(text)
+			require.NotNil(t, m, text)
+			a, _ := strconv.Atoi(m[1])
+			b, _ := strconv.Atoi(m[3])
+			expected := map[string]int{"+": a + b, "-": a - b, "x": a * b}[m[2]]
+			assert.GreaterOrEqual(t, expected, 0, text)
+			assert.Equal(t, strconv.Itoa(expected), answer, text)
+			for j := 0; j < len(text); j++ {
+				assert.Contains(t, captchaGlyphs, text[j], "所有字符都有字形")
+			}
+		}
+	})
+}
+
+// TestImageCaptchaHandler 测试验证码图片接口和答案校验
+func TestImageCaptchaHandler(t *testing.T) {
+	store := NewMemoryCaptchaStore(0)
+	captcha := NewImageCaptcha(store)
+	id, err := captcha.IssueID()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		captcha.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	t.Run("Image", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/formbuilder/captcha/code?id="+id)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		img, err := png.Decode(rec.Body)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultCaptchaWidth, img.Bounds().Dx())
+		assert.Equal(t, DefaultCaptchaHeight, img.Bounds().Dy())
+		assert.Equal(t, id, rec.Header().Get(CaptchaIDHeader))
+		assert.Equal(t, 1, store.Len())
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/formbuilder/captcha/code?new=1&id="+id)
+		require.Equal(t, http.StatusOK, rec.Code)
+		issued := rec.Header().Get(CaptchaIDHeader)
+		assert.NotEqual(t, id, issued, "new参数忽略请求中的ID，签发新的挑战ID")
+		assert.True(t, captcha.validID(issued))
+		assert.Contains(t, store.entries, issued)
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		_, err := captcha.Generate(ctx, id)
+		require.NoError(t, err)
+		answer := store.entries[id].answer
+		assert.NoError(t, captcha.Verify(ctx, id, " "+answer+" "))
+		assert.ErrorIs(t, captcha.Verify(ctx, id, answer), ErrCaptchaNotFound, "答案只能使用一次")
+
+		_, err = captcha.Generate(ctx, id)
+		require.NoError(t, err)
+		assert.ErrorIs(t, captcha.Verify(ctx, id, "wrong"), errInvalidCaptcha)
+		assert.ErrorIs(t, captcha.Verify(ctx, id, store.entries[id].answer), ErrCaptchaNotFound, "答错后挑战失效")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/?id="+id).Code)
+		other, err := NewImageCaptcha(nil).IssueID()
+		require.NoError(t, err)
+		for _, bad := range []string{"", "short", "../../../../etc/passwd", id + "!", newCaptchaID(), id[:32] + other[32:], other} {
+			assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/?id="+bad).Code, bad)
+		}
+
+		failing := NewImageCaptcha(failingCaptchaStore{})
+		rec := httptest.NewRecorder()
+		failing.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?new=1", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		full := NewImageCaptcha(NewMemoryCaptchaStore(0, 1))
+		rec = httptest.NewRecorder()
+		full.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?new=1", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = httptest.NewRecorder()
+		full.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?new=1", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "存储已满时拒绝新的挑战")
+	})
+
+	t.Run("SharedKey", func(t *testing.T) {
+		a := &ImageCaptcha{Store: store, Key: []byte("shared")}
+		b := &ImageCaptcha{Store: store, Key: []byte("shared")}
+		issued, err := a.IssueID()
+		require.NoError(t, err)
+		assert.True(t, b.validID(issued), "相同密钥的实例互相认可签发的ID")
+		assert.False(t, captcha.validID(issued))
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		img := drawCaptcha("12345678", 60, 20)
+		assert.Equal(t, 60, img.Bounds().Dx())
+		img = drawCaptcha("7x8=?", 200, 80)
+		assert.Equal(t, 80, img.Bounds().Dy())
+	})
+}
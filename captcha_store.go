@@ -0,0 +1,127 @@
+package formbuilder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// captcha_store.go 定义验证码挑战的存储
+// 生成验证码时保存答案，验证时取出并删除，每个挑战只能验证一次。
+// 内置内存实现，多实例部署时可以基于Redis等实现CaptchaStore
+
+// DefaultCaptchaTTL 验证码的默认有效期
+const DefaultCaptchaTTL = 5 * time.Minute
+
+// DefaultMaxCaptchas 内存存储默认最多保存的挑战数
+const DefaultMaxCaptchas = 100000
+
+// captchaSweepSize 内存存储清理过期挑战的最小条目数
+const captchaSweepSize = 1024
+
+// ErrCaptchaNotFound 验证码挑战不存在或已过期
+// CaptchaStore的实现在挑战不存在时返回此错误
+var ErrCaptchaNotFound = errors.New("captcha not found")
+
+// ErrCaptchaStoreFull 存储的挑战数已达上限
+// ImageCaptcha生成挑战时遇到此错误返回503
+var ErrCaptchaStoreFull = errors.New("captcha store is full")
+
+// CaptchaStore 验证码挑战的存储
+type CaptchaStore interface {
+	// Set 保存挑战的答案，相同ID的挑战会被覆盖，存储已满时返回ErrCaptchaStoreFull
+	Set(ctx context.Context, id, answer string) error
+
+	// Take 取出并删除挑战的答案，不存在或已过期时返回ErrCaptchaNotFound
+	Take(ctx context.Context, id string) (string, error)
+}
+
+// MemoryCaptchaStore 内存验证码存储
+// 挑战在TTL后过期，过期的挑战在写入时批量清理；清理后挑战数仍达到上限时拒绝新的挑战。
+// 适用于单实例部署
+type MemoryCaptchaStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	limit     int
+	entries   map[string]captchaEntry
+	nextSweep int
+	now       func() time.Time
+}
+
+// captchaEntry 内存存储中的挑战
+type captchaEntry struct {
+	answer    string
+	expiresAt time.Time
+}
+
+// NewMemoryCaptchaStore 创建内存验证码存储
+// ttl为挑战的有效期，不大于0时使用DefaultCaptchaTTL；
+// maxEntries为最多保存的挑战数，省略或为0时使用DefaultMaxCaptchas，小于0时不限制
+func NewMemoryCaptchaStore(ttl time.Duration, maxEntries ...int) *MemoryCaptchaStore {
+	if ttl <= 0 {
+		ttl = DefaultCaptchaTTL
+	}
+	limit := DefaultMaxCaptchas
+	if len(maxEntries) > 0 && maxEntries[0] != 0 {
+		limit = maxEntries[0]
+	}
+	return &MemoryCaptchaStore{
+		ttl:       ttl,
+		limit:     limit,
+		entries:   make(map[string]captchaEntry),
+		nextSweep: captchaSweepSize,
+		now:       time.Now,
+	}
+}
+
+// Set 实现CaptchaStore接口
+func (s *MemoryCaptchaStore) Set(ctx context.Context, id, answer string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if len(s.entries) >= s.nextSweep {
+		s.sweep(now)
+	}
+	if _, ok := s.entries[id]; !ok && s.limit > 0 && len(s.entries) >= s.limit {
+		s.sweep(now)
+		if len(s.entries) >= s.limit {
+			return ErrCaptchaStoreFull
+		}
+	}
+	s.entries[id] = captchaEntry{answer: answer, expiresAt: now.Add(s.ttl)}
+	return nil
+}
+
+// Take 实现CaptchaStore接口
+func (s *MemoryCaptchaStore) Take(ctx context.Context, id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[id]
+	if !ok {
+		return "", ErrCaptchaNotFound
+	}
+	delete(s.entries, id)
+	if !s.now().Before(entry.expiresAt) {
+		return "", ErrCaptchaNotFound
+	}
+	return entry.answer, nil
+}
+
+// Len 返回当前保存的挑战数量，包括尚未清理的过期挑战
+func (s *MemoryCaptchaStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// sweep 清理过期的挑战
+// 下一次清理在条目数翻倍后进行，使清理的开销分摊到每次写入
+func (s *MemoryCaptchaStore) sweep(now time.Time) {
+	for id, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, id)
+		}
+	}
+	s.nextSweep = max(len(s.entries)*2, captchaSweepSize)
+}
@@ -0,0 +1,85 @@
+package formbuilder
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captcha_store_test.go 测试验证码挑战的内存存储
+
+// TestMemoryCaptchaStore 测试内存存储的读写和过期
+func TestMemoryCaptchaStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newStore := func() *MemoryCaptchaStore {
+		store := NewMemoryCaptchaStore(time.Minute)
+		store.now = func() time.Time { return now }
+		return store
+	}
+
+	t.Run("TakeOnce", func(t *testing.T) {
+		store := newStore()
+		require.NoError(t, store.Set(ctx, "a", "1234"))
+		answer, err := store.Take(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "1234", answer)
+
+		_, err = store.Take(ctx, "a")
+		assert.ErrorIs(t, err, ErrCaptchaNotFound, "挑战只能取出一次")
+		_, err = store.Take(ctx, "missing")
+		assert.ErrorIs(t, err, ErrCaptchaNotFound)
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		store := newStore()
+		require.NoError(t, store.Set(ctx, "a", "1111"))
+		require.NoError(t, store.Set(ctx, "a", "2222"))
+		answer, err := store.Take(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "2222", answer)
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		store := newStore()
+		require.NoError(t, store.Set(ctx, "a", "1234"))
+		store.now = func() time.Time { return now.Add(time.Minute) }
+		_, err := store.Take(ctx, "a")
+		assert.ErrorIs(t, err, ErrCaptchaNotFound)
+		assert.Zero(t, store.Len(), "过期的挑战在读取时删除")
+	})
+
+	t.Run("Sweep", func(t *testing.T) {
+		store := newStore()
+		for i := 0; i < captchaSweepSize; i++ {
+			require.NoError(t, store.Set(ctx, "old"+strconv.Itoa(i), "1"))
+		}
+		store.now = func() time.Time { return now.Add(2 * time.Minute) }
+		require.NoError(t, store.Set(ctx, "new", "2"))
+		assert.Equal(t, 1, store.Len(), "写入时清理过期的挑战")
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		store := NewMemoryCaptchaStore(time.Minute, 2)
+		store.now = func() time.Time { return now }
+		require.NoError(t, store.Set(ctx, "a", "1"))
+		require.NoError(t, store.Set(ctx, "b", "2"))
+		assert.ErrorIs(t, store.Set(ctx, "c", "3"), ErrCaptchaStoreFull)
+		assert.NoError(t, store.Set(ctx, "a", "4"), "覆盖已有的挑战不受上限限制")
+
+		store.now = func() time.Time { return now.Add(time.Minute) }
+		assert.NoError(t, store.Set(ctx, "c", "3"), "达到上限时先清理过期的挑战")
+		assert.Equal(t, 1, store.Len())
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		store := NewMemoryCaptchaStore(0)
+		assert.Equal(t, DefaultCaptchaTTL, store.ttl)
+		assert.Equal(t, DefaultMaxCaptchas, store.limit)
+		assert.Equal(t, -1, NewMemoryCaptchaStore(0, -1).limit)
+	})
+}
@@ -0,0 +1,163 @@
+package formbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captcha_test.go 测试Captcha验证码组件
+
+// stubVerifier 答案固定为 "42" 的校验器，记录收到的挑战ID
+type stubVerifier struct {
+	ids []string
+}
+
+func (v *stubVerifier) Verify(ctx context.Context, id, answer string) error {
+	v.ids = append(v.ids, id)
+	if answer != "42" {
+		return errInvalidCaptcha
+	}
+	return nil
+}
+
+// TestCaptchaBuild 测试验证码组件的规则生成
+func TestCaptchaBuild(t *testing.T) {
+	captcha := Elm.Captcha("code", "验证码", NewImageCaptcha(nil))
+	rule := captcha.Build()
+
+	assert.Equal(t, "input", rule["type"])
+	assert.Equal(t, "code", rule["field"])
+	assert.Equal(t, "请输入验证码", rule["props"].(map[string]interface{})["placeholder"])
+	assert.Equal(t, []map[string]interface{}{{"required": true, "message": "请输入验证码", "trigger": "blur", "__rule": map[string]interface{}{"name": "captcha"}}}, rule["validate"])
+
+	children := rule["children"].([]map[string]interface{})
+	require.Len(t, children, 1)
+	img := children[0]
+	assert.Equal(t, "img", img["type"])
+	assert.Equal(t, "append", img["slot"])
+	assert.Equal(t, "/formbuilder/captcha/code?id="+captcha.id, img["attrs"].(map[string]interface{})["src"])
+	click := img["on"].(map[string]interface{})["click"].(JSFunc)
+	assert.True(t, strings.HasPrefix(string(click), "function(e)"))
+	assert.Contains(t, click, `'new=1'`, "刷新时由服务端签发挑战ID")
+	assert.Contains(t, click, `res.headers.get("X-Captcha-Id")`)
+	assert.Contains(t, click, `api.setValue("code_captcha_id", id);`)
+	assert.True(t, captcha.verifier.(*ImageCaptcha).validID(captcha.id), "挑战ID由ImageCaptcha签发")
+
+	_, err := json.Marshal(rule)
+	assert.NoError(t, err)
+
+	custom := NewCaptcha("code", "验证码", &stubVerifier{}, "/api/captcha?v=1")
+	src := custom.Build()["children"].([]map[string]interface{})[0]["attrs"].(map[string]interface{})["src"]
+	assert.Equal(t, "/api/captcha?v=1&id="+custom.id, src)
+	assert.Empty(t, custom.endpoints(), "校验器不是http.Handler时不注册接口")
+	assert.NotEqual(t, captcha.id, custom.id, "每个组件生成新的挑战ID")
+}
+
+// TestFormCaptcha 测试表单注入挑战ID并在验证时校验答案
+func TestFormCaptcha(t *testing.T) {
+	verifier := &stubVerifier{}
+	newForm := func() (*Form, *Captcha) {
+		captcha := NewCaptcha("code", "验证码", verifier)
+		return NewElmForm("/submit", []Component{NewInput("name", "名称"), captcha}, nil), captcha
+	}
+
+	t.Run("HiddenID", func(t *testing.T) {
+		form, captcha := newForm()
+		rules := form.FormRule()
+		last := rules[len(rules)-1]
+		assert.Equal(t, "hidden", last["type"])
+		assert.Equal(t, "code_captcha_id", last["field"])
+		assert.Equal(t, captcha.id, last["value"])
+	})
+
+	t.Run("ParseAndValidate", func(t *testing.T) {
+		form, captcha := newForm()
+		body := `{"name":"a","code":"42","code_captcha_id":"` + captcha.id + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		values, err := ParseRequest(form, req, ParseOptions{Strict: true})
+		require.NoError(t, err, "挑战ID字段不是未知字段")
+
+		verifier.ids = nil
+		assert.NoError(t, form.ValidateData(values))
+		assert.Equal(t, []string{captcha.id}, verifier.ids)
+	})
+
+	t.Run("Rejects", func(t *testing.T) {
+		form, captcha := newForm()
+		for name, values := range map[string]map[string]interface{}{
+			"Wrong":   {"code": "41", "code_captcha_id": captcha.id},
+			"Empty":   {"code": " ", "code_captcha_id": captcha.id},
+			"Missing": {"code_captcha_id": captcha.id},
+			"NoID":    {"code": "42"},
+		} {
+			err := form.ValidateData(values)
+			assert.ErrorIs(t, err, ErrCaptcha, name)
+			assert.ErrorIs(t, err, &FieldError{Field: "code"}, name)
+		}
+	})
+
+	t.Run("Mount", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{Iview.Captcha("code", "验证码", NewImageCaptcha(nil))}, nil)
+		mux := http.NewServeMux()
+		require.NoError(t, form.Mount(mux))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/formbuilder/captcha/code?new=1", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("EndToEnd", func(t *testing.T) {
+		store := NewMemoryCaptchaStore(0)
+		captcha := NewCaptcha("code", "验证码", NewImageCaptcha(store))
+		form := NewElmForm("/submit", []Component{captcha}, nil)
+		_, err := captcha.verifier.(*ImageCaptcha).Generate(context.Background(), captcha.id)
+		require.NoError(t, err)
+		answer := store.entries[captcha.id].answer
+
+		values := map[string]interface{}{"code": answer, captcha.IDField(): captcha.id}
+		assert.NoError(t, form.ValidateData(values))
+		assert.ErrorIs(t, form.ValidateData(values), ErrCaptcha, "验证码只能使用一次")
+	})
+}
+
+// TestCaptchaParseRules 测试验证码组件从规则还原后仍然校验答案
+func TestCaptchaParseRules(t *testing.T) {
+	verifier := &stubVerifier{}
+	original := NewCaptcha("code", "验证码", verifier, "/api/captcha?v=1").Placeholder("答案")
+	data, err := json.Marshal([]map[string]interface{}{original.Build()})
+	require.NoError(t, err)
+
+	rules, err := ParseRules(data)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	captcha, ok := rules[0].(*Captcha)
+	require.True(t, ok, "按验证规则的标记还原为Captcha")
+	assert.Equal(t, "/api/captcha?v=1", captcha.imageURL)
+	assert.Empty(t, captcha.data.Children, "验证码图片由Build重新生成")
+	assert.Empty(t, captcha.data.AppendRule)
+	assert.NotEqual(t, original.id, captcha.id, "重新生成挑战ID")
+
+	rule := captcha.Build()
+	want := original.Build()
+	for _, r := range []map[string]interface{}{rule, want} {
+		delete(r["children"].([]map[string]interface{})[0]["attrs"].(map[string]interface{}), "src")
+	}
+	assert.Equal(t, want, rule)
+
+	form := NewElmForm("/submit", rules, nil)
+	values := map[string]interface{}{"code": "42", "code_captcha_id": captcha.id}
+	assert.ErrorIs(t, form.ValidateData(values), ErrCaptcha, "未设置校验器时校验失败")
+
+	captcha.Verifier(verifier)
+	values["code_captcha_id"] = captcha.id
+	assert.NoError(t, form.ValidateData(values))
+	assert.Equal(t, []string{captcha.id}, verifier.ids)
+}
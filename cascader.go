@@ -1,11 +1,26 @@
 package formbuilder
 
+import (
+	"context"
+	"fmt"
+)
+
 // cascader.go 实现Cascader级联选择器组件
 
+const (
+	// maxCascaderPaths 多选时一次提交的最大路径数，限制服务端验证的开销
+	maxCascaderPaths = 200
+
+	// maxCascaderDepth 提交路径的最大层级
+	maxCascaderDepth = 32
+)
+
 // Cascader 级联选择器组件
 type Cascader struct {
 	Builder[*Cascader]
 	options []Option
+	loader  NodeLoader // 懒加载函数，见LazySource
+	loadURL string     // 懒加载接口地址
 }
 
 // NewCascader 创建级联选择器
@@ -80,6 +95,92 @@ func (c *Cascader) Size(size string) *Cascader {
 	return c
 }
 
+// Normalize 实现Normalizer接口
+// 选中路径转换为[]string，多选（props.multiple）时转换为[][]string
+func (c *Cascader) Normalize(value interface{}) (interface{}, error) {
+	props, _ := c.data.Props["props"].(map[string]interface{})
+	if !propBool(props, "multiple") {
+		return normalizeStrings(value)
+	}
+	items, ok := sliceItems(value)
+	if !ok {
+		return nil, errNotList
+	}
+	paths := make([][]string, 0, len(items))
+	for _, item := range items {
+		path, err := toStrings(item)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// checkOptions 实现optionChecker接口
+// 检查完整路径是否存在于选项树中；未开启checkStrictly时必须选择到叶子节点，
+// emitPath为false时只检查选中的节点值；设置了LazySource时沿路径调用懒加载函数检查。
+// 初始值中的选中项允许经过禁用的节点。多选最多maxCascaderPaths项，路径最多maxCascaderDepth级。
+// 未设置选项（选项由前端加载）和手动配置的懒加载不检查；
+// LazySource与emitPath为false同时使用时无法得到完整路径，拒绝提交
+func (c *Cascader) checkOptions(ctx context.Context, value, defaults interface{}) error {
+	props, _ := c.data.Props["props"].(map[string]interface{})
+	strict := propBool(props, "checkStrictly")
+	emitPath := true
+	if v, ok := props["emitPath"].(bool); ok {
+		emitPath = v
+	}
+	var lazy *lazyLoader
+	switch {
+	case c.loader != nil && emitPath:
+		lazy = &lazyLoader{load: c.loader, cache: make(map[string][]Option)}
+	case c.loader != nil:
+		return errUnverifiableOption
+	case len(c.options) == 0, propBool(props, "lazy"):
+		return nil
+	}
+
+	multiple := propBool(props, "multiple")
+	selections := []interface{}{value}
+	if multiple {
+		items, ok := sliceItems(value)
+		if !ok {
+			return errInvalidOption
+		}
+		if len(items) > maxCascaderPaths {
+			return fmt.Errorf("最多只能选择%d项", maxCascaderPaths)
+		}
+		selections = items
+	}
+
+	for _, selection := range selections {
+		allowDisabled := containsSelection(defaults, selection, multiple)
+		if !emitPath {
+			if !findOptionNode(c.options, selection, strict, allowDisabled) {
+				return errInvalidOption
+			}
+			continue
+		}
+		path, ok := sliceItems(selection)
+		if !ok {
+			path = []interface{}{selection}
+		}
+		if len(path) > maxCascaderDepth {
+			return errInvalidOption
+		}
+		if lazy != nil {
+			if err := lazy.checkPath(ctx, path, strict, allowDisabled); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := checkOptionPath(c.options, path, strict, allowDisabled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetField 实现Component接口
 func (c *Cascader) GetField() string {
 	return c.data.Field
@@ -93,21 +194,40 @@ func (c *Cascader) GetType() string {
 // Build 实现Component接口
 func (c *Cascader) Build() map[string]interface{} {
 	result := buildComponent(c.data)
+	if c.loader != nil {
+		props := make(map[string]interface{}, len(c.data.Props)+1)
+		for k, v := range c.data.Props {
+			props[k] = v
+		}
+		props["props"] = c.lazyProps()
+		result["props"] = props
+	}
 	if len(c.options) > 0 {
 		opts := make([]map[string]interface{}, len(c.options))
 		for i, opt := range c.options {
 			opts[i] = opt.ToMap()
 		}
 
-		// 确保 props 对象存在
-		if result["props"] == nil {
-			result["props"] = make(map[string]interface{})
-		}
-
-		// 将 options 添加到 props 内部
-		if props, ok := result["props"].(map[string]interface{}); ok {
-			props["options"] = opts
+		// 将 options 添加到 props 内部，复制props以免修改组件数据
+		props := make(map[string]interface{})
+		if origin, ok := result["props"].(map[string]interface{}); ok {
+			for k, v := range origin {
+				props[k] = v
+			}
 		}
+		props["options"] = opts
+		result["props"] = props
 	}
 	return result
 }
+
+// loadOptions 实现optionLoader接口
+// 级联选项位于props.options中
+func (c *Cascader) loadOptions(rest map[string]interface{}) {
+	options, ok := parseOptions(c.data.Props["options"])
+	if !ok {
+		return
+	}
+	c.options = options
+	delete(c.data.Props, "options")
+}
@@ -0,0 +1,170 @@
+package formbuilder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// cascader_lazy.go 实现Cascader组件的懒加载
+// 子选项由Go函数按父节点路径加载，前端lazyLoad通过JSON接口调用，
+// 服务端验证时沿提交的路径逐级调用同一个函数，适用于组织架构、商品类目等大型选项树
+
+// DefaultNodeLoadURL 懒加载接口的默认地址前缀，完整地址为 前缀 + "/" + 字段名
+const DefaultNodeLoadURL = "/formbuilder/nodes"
+
+// NodeLoader 懒加载函数
+// path为父节点的值路径，为空时返回第一级选项。
+// 返回的选项设置Leaf为true时前端不再展开；未设置时加载结果为空的节点视为叶子节点
+type NodeLoader func(ctx context.Context, path []string) ([]Option, error)
+
+// NodeLoadResponse 懒加载接口的响应
+//
+// 响应示例：
+//
+//	{"code": 200, "message": "ok", "data": [{"value": "hd", "label": "海淀区", "leaf": true}]}
+type NodeLoadResponse struct {
+	Code    int                      `json:"code"`
+	Message string                   `json:"message"`
+	Data    []map[string]interface{} `json:"data"`
+}
+
+// LazySource 设置懒加载函数，由Form.Mount注册加载接口并生成props.lazy和props.lazyLoad
+// url为加载接口地址，默认为 DefaultNodeLoadURL + "/" + 字段名。
+// 仅支持Element UI；服务端验证需要完整路径，与emitPath为false同时使用时ValidateData拒绝提交
+//
+// 使用示例：
+//
+//	Elm.Cascader("dept", "部门").LazySource(func(ctx context.Context, path []string) ([]formbuilder.Option, error) {
+//	    parent := ""
+//	    if len(path) > 0 {
+//	        parent = path[len(path)-1]
+//	    }
+//	    return deptRepo.Children(ctx, parent)
+//	})
+func (c *Cascader) LazySource(loader NodeLoader, loadURL ...string) *Cascader {
+	c.loader = loader
+	c.loadURL = DefaultNodeLoadURL + "/" + c.data.Field
+	if len(loadURL) > 0 && loadURL[0] != "" {
+		c.loadURL = loadURL[0]
+	}
+	return c
+}
+
+// endpoints 实现endpointProvider接口
+func (c *Cascader) endpoints() map[string]http.Handler {
+	if c.loader == nil {
+		return nil
+	}
+	return map[string]http.Handler{searchPath(c.loadURL): NodeLoaderHandler(c.loader)}
+}
+
+// NodeLoaderHandler 返回懒加载接口
+// 接收GET请求，按顺序重复的path参数为父节点路径，返回NodeLoadResponse
+func NodeLoaderHandler(loader NodeLoader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+			return
+		}
+
+		options, err := loader(r.Context(), r.URL.Query()["path"])
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Code: http.StatusInternalServerError, Message: "加载失败"})
+			return
+		}
+
+		resp := NodeLoadResponse{Code: http.StatusOK, Message: "ok", Data: make([]map[string]interface{}, len(options))}
+		for i, opt := range options {
+			resp.Data[i] = opt.ToMap()
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// lazyProps 返回加入lazy和lazyLoad的props.props
+// 复制原有配置，不修改CascaderProps传入的map
+func (c *Cascader) lazyProps() map[string]interface{} {
+	props := make(map[string]interface{})
+	if origin, ok := c.data.Props["props"].(map[string]interface{}); ok {
+		for k, v := range origin {
+			props[k] = v
+		}
+	}
+	props["lazy"] = true
+	props["lazyLoad"] = JSFunc(`function(node, resolve) {
+    var url = ` + jsLiteral(c.loadURL) + `;
+    var path = node && !node.root && node.pathValues ? node.pathValues : [];
+    var query = path.map(function(v) { return 'path=' + encodeURIComponent(v); }).join('&');
+    fetch(url + (query ? (url.indexOf('?') < 0 ? '?' : '&') + query : ''),
+        {credentials: 'same-origin', headers: {'Accept': 'application/json'}}).then(function(res) {
+        return res.json();
+    }).then(function(res) {
+        resolve((res && res.data) || []);
+    }).catch(function() {
+        resolve([]);
+    });
+}`)
+	return props
+}
+
+// lazyLoader 带缓存的懒加载函数，一次验证中相同路径只加载一次
+type lazyLoader struct {
+	load  NodeLoader
+	cache map[string][]Option
+}
+
+// children 返回path的子选项
+func (l *lazyLoader) children(ctx context.Context, path []string) ([]Option, error) {
+	key := strings.Join(path, "\x00")
+	if options, ok := l.cache[key]; ok {
+		return options, nil
+	}
+	options, err := l.load(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	l.cache[key] = options
+	return options, nil
+}
+
+// checkPath 沿路径逐级加载并检查节点是否存在
+// strict为false时路径必须以叶子节点结束；allowDisabled为true时允许路径经过禁用的节点
+func (l *lazyLoader) checkPath(ctx context.Context, path []interface{}, strict, allowDisabled bool) error {
+	if len(path) == 0 {
+		return errInvalidOption
+	}
+	parents := make([]string, 0, len(path))
+	for i, item := range path {
+		options, err := l.children(ctx, parents)
+		if err != nil {
+			return errOptionLookup
+		}
+		opt, ok := matchOption(options, item)
+		if !ok || opt.Disabled && !allowDisabled {
+			return errInvalidOption
+		}
+		parents = append(parents, optionPathValue(opt.Value))
+
+		if i == len(path)-1 && !strict && !opt.Leaf {
+			children, err := l.children(ctx, parents)
+			if err != nil {
+				return errOptionLookup
+			}
+			if len(children) > 0 {
+				return errInvalidOption
+			}
+		}
+	}
+	return nil
+}
+
+// optionPathValue 将选项值转换为传给NodeLoader的路径元素
+func optionPathValue(value interface{}) string {
+	if s, ok := stringValue(value); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
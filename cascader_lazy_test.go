@@ -0,0 +1,192 @@
+package formbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cascader_lazy_test.go 测试Cascader组件的懒加载
+
+// testAreaTree 测试用的地区树，按路径返回子节点
+var testAreaTree = map[string][]Option{
+	"":        {{Value: "bj", Label: "北京"}, {Value: "sh", Label: "上海"}, {Value: "xz", Label: "已撤销", Disabled: true}},
+	"bj":      {{Value: "hd", Label: "海淀区"}, {Value: "cy", Label: "朝阳区", Leaf: true}},
+	"bj/hd":   {{Value: 1, Label: "中关村街道", Leaf: true}},
+	"sh":      {},
+	"bj/hd/1": {{Value: "x", Label: "不应加载"}},
+}
+
+// countingLoader 返回记录调用次数的懒加载函数
+func countingLoader(calls *[]string) NodeLoader {
+	return func(ctx context.Context, path []string) ([]Option, error) {
+		key := strings.Join(path, "/")
+		*calls = append(*calls, key)
+		return testAreaTree[key], nil
+	}
+}
+
+// TestCascaderLazySource 测试懒加载的规则生成
+func TestCascaderLazySource(t *testing.T) {
+	origin := map[string]interface{}{"checkStrictly": true}
+	var calls []string
+	cascader := NewCascader("area", "地区").CascaderProps(origin).LazySource(countingLoader(&calls))
+
+	rule := cascader.Build()
+	props := rule["props"].(map[string]interface{})["props"].(map[string]interface{})
+	assert.Equal(t, true, props["lazy"])
+	assert.Equal(t, true, props["checkStrictly"], "保留CascaderProps的配置")
+	lazyLoad := string(props["lazyLoad"].(JSFunc))
+	assert.True(t, strings.HasPrefix(lazyLoad, "function(node, resolve)"))
+	assert.Contains(t, lazyLoad, `var url = "/formbuilder/nodes/area";`)
+	assert.NotContains(t, origin, "lazy", "不修改传入的map")
+	assert.NotContains(t, cascader.data.Props["props"], "lazyLoad")
+
+	_, err := json.Marshal(rule)
+	assert.NoError(t, err)
+	assert.Empty(t, calls, "生成规则时不调用加载函数")
+
+	custom := NewCascader("area", "地区").LazySource(countingLoader(&calls), "/api/areas?v=2")
+	assert.Contains(t, custom.endpoints(), "/api/areas")
+}
+
+// TestNodeLoaderHandler 测试懒加载接口
+func TestNodeLoaderHandler(t *testing.T) {
+	var calls []string
+	form := NewElmForm("/submit", []Component{
+		NewCascader("area", "地区").LazySource(countingLoader(&calls)),
+	}, nil)
+	mux := http.NewServeMux()
+	require.NoError(t, form.Mount(mux))
+
+	load := func(target string) (int, NodeLoadResponse) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var resp NodeLoadResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	t.Run("Root", func(t *testing.T) {
+		code, resp := load("/formbuilder/nodes/area")
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, resp.Data, 3)
+		assert.Equal(t, "bj", resp.Data[0]["value"])
+		assert.Equal(t, true, resp.Data[2]["disabled"])
+	})
+
+	t.Run("Children", func(t *testing.T) {
+		_, resp := load("/formbuilder/nodes/area?path=bj&path=hd")
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, map[string]interface{}{"value": float64(1), "label": "中关村街道", "leaf": true}, resp.Data[0])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/formbuilder/nodes/area", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+		failing := NodeLoaderHandler(func(ctx context.Context, path []string) ([]Option, error) {
+			return nil, errors.New("db down")
+		})
+		rec = httptest.NewRecorder()
+		failing.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+// TestCascaderLazyValidate 测试沿路径验证提交的值
+func TestCascaderLazyValidate(t *testing.T) {
+	newForm := func(loader NodeLoader, props map[string]interface{}) *Form {
+		cascader := NewCascader("area", "地区")
+		if props != nil {
+			cascader.CascaderProps(props)
+		}
+		return NewElmForm("/submit", []Component{cascader.LazySource(loader)}, nil)
+	}
+
+	t.Run("LeafPaths", func(t *testing.T) {
+		var calls []string
+		form := newForm(countingLoader(&calls), nil)
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"area": []interface{}{"bj", "hd", "1"}}))
+		assert.Equal(t, []string{"", "bj", "bj/hd"}, calls, "Leaf节点不再加载子节点")
+
+		calls = nil
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"area": []interface{}{"sh"}}), "没有子节点的节点视为叶子")
+		assert.Equal(t, []string{"", "sh"}, calls)
+	})
+
+	t.Run("InvalidPaths", func(t *testing.T) {
+		var calls []string
+		form := newForm(countingLoader(&calls), nil)
+		for _, path := range [][]interface{}{
+			{"bj"},       // 非叶子节点
+			{"bj", "xx"}, // 不存在
+			{"xz"},       // 禁用
+			{"sh", "pd"}, // 没有子节点
+		} {
+			err := form.ValidateData(map[string]interface{}{"area": path})
+			assert.ErrorIs(t, err, &FieldError{Field: "area", Rule: "option"}, "%v", path)
+		}
+	})
+
+	t.Run("CheckStrictly", func(t *testing.T) {
+		var calls []string
+		form := newForm(countingLoader(&calls), map[string]interface{}{"checkStrictly": true})
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"area": []interface{}{"bj"}}))
+	})
+
+	t.Run("MultipleCache", func(t *testing.T) {
+		var calls []string
+		form := newForm(countingLoader(&calls), map[string]interface{}{"multiple": true})
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"area": []interface{}{
+			[]interface{}{"bj", "cy"},
+			[]interface{}{"bj", "hd", 1},
+		}}))
+		assert.Equal(t, []string{"", "bj", "bj/hd"}, calls, "相同路径只加载一次")
+	})
+
+	t.Run("EmitPathFalse", func(t *testing.T) {
+		var calls []string
+		form := newForm(countingLoader(&calls), map[string]interface{}{"emitPath": false})
+		err := form.ValidateData(map[string]interface{}{"area": "anything"})
+		assert.ErrorIs(t, err, &FieldError{Field: "area", Rule: "option"}, "无法得到完整路径时拒绝提交")
+		assert.Empty(t, calls)
+	})
+
+	t.Run("TooManyPaths", func(t *testing.T) {
+		var calls []string
+		form := newForm(countingLoader(&calls), map[string]interface{}{"multiple": true})
+		paths := make([]interface{}, maxCascaderPaths+1)
+		for i := range paths {
+			paths[i] = []interface{}{"bj", "cy"}
+		}
+		err := form.ValidateData(map[string]interface{}{"area": paths})
+		assert.ErrorIs(t, err, &FieldError{Field: "area", Rule: "option"})
+		assert.Empty(t, calls, "超出数量时不调用加载函数")
+
+		deep := make([]interface{}, maxCascaderDepth+1)
+		for i := range deep {
+			deep[i] = "bj"
+		}
+		err = form.ValidateData(map[string]interface{}{"area": []interface{}{deep}})
+		assert.ErrorIs(t, err, &FieldError{Field: "area", Rule: "option"})
+		assert.Empty(t, calls)
+	})
+
+	t.Run("LoaderError", func(t *testing.T) {
+		form := newForm(func(ctx context.Context, path []string) ([]Option, error) {
+			return nil, errors.New("db down")
+		}, nil)
+		err := form.ValidateData(map[string]interface{}{"area": []interface{}{"bj"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), errOptionLookup.Error())
+	})
+}
@@ -1,5 +1,7 @@
 package formbuilder
 
+import "context"
+
 // checkbox.go 实现Checkbox复选框组件
 
 // Checkbox 复选框组件
@@ -74,6 +76,21 @@ func (c *Checkbox) CheckedColor(color string) *Checkbox {
 	return c
 }
 
+// Normalize 实现Normalizer接口
+// 选中的值逐个转换为匹配选项的Value（保留选项值的Go类型），结果为[]interface{}
+func (c *Checkbox) Normalize(value interface{}) (interface{}, error) {
+	return normalizeOptionValues(c.options, value)
+}
+
+// checkOptions 实现optionChecker接口
+// 每个选中的值都必须是可选的选项
+func (c *Checkbox) checkOptions(ctx context.Context, value, defaults interface{}) error {
+	if len(c.options) == 0 {
+		return nil
+	}
+	return checkOptionValues(c.options, value, defaults)
+}
+
 // GetField 实现Component接口
 func (c *Checkbox) GetField() string {
 	return c.data.Field
@@ -96,3 +113,11 @@ func (c *Checkbox) Build() map[string]interface{} {
 	}
 	return result
 }
+
+// loadOptions 实现optionLoader接口
+func (c *Checkbox) loadOptions(rest map[string]interface{}) {
+	if options, ok := parseOptions(rest["options"]); ok {
+		c.options = options
+		delete(rest, "options")
+	}
+}
@@ -0,0 +1,622 @@
+package formbuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// chunk_store.go 定义分片上传的会话存储
+// 分片上传期间的会话状态和已接收的分片保存在ChunkStore中，
+// 合并完成后再写入Storage，内置内存和本地目录两种实现
+
+// ErrSessionNotFound 上传会话不存在
+// ChunkStore的实现在会话不存在或已过期时返回此错误
+var ErrSessionNotFound = errors.New("upload session not found")
+
+// ErrChunkStoreFull 会话数量或分片总字节数达到上限
+// 上传接口对此错误返回503，客户端可以稍后重试
+var ErrChunkStoreFull = errors.New("chunk store is full")
+
+const (
+	// DefaultChunkSessionTTL 上传会话的默认有效期，从创建时开始计算
+	DefaultChunkSessionTTL = 24 * time.Hour
+
+	// DefaultMaxChunkSessions 默认的最大会话数
+	DefaultMaxChunkSessions = 1000
+
+	// DefaultMaxChunkBytes 内存存储默认的分片总字节数上限
+	DefaultMaxChunkBytes = 512 << 20
+
+	// DefaultMaxFileChunkBytes 本地目录存储默认的分片总字节数上限
+	DefaultMaxFileChunkBytes = 10 << 30
+)
+
+// chunkSweepInterval 本地目录存储自动清理过期会话的最小间隔
+const chunkSweepInterval = 10 * time.Minute
+
+// ChunkStoreOptions 会话存储选项
+type ChunkStoreOptions struct {
+	// TTL 会话有效期，过期的会话及其分片会被清理，不大于0时使用DefaultChunkSessionTTL
+	TTL time.Duration
+
+	// MaxSessions 同时存在的最大会话数，为0时使用DefaultMaxChunkSessions，小于0时不限制
+	MaxSessions int
+
+	// MaxBytes 已接收分片的总字节数上限，小于0时不限制。
+	// 为0时内存存储使用DefaultMaxChunkBytes，本地目录存储使用DefaultMaxFileChunkBytes
+	MaxBytes int64
+}
+
+// ttl 返回会话有效期
+func (o ChunkStoreOptions) ttl() time.Duration {
+	if o.TTL <= 0 {
+		return DefaultChunkSessionTTL
+	}
+	return o.TTL
+}
+
+// maxSessions 返回最大会话数，0表示不限制
+func (o ChunkStoreOptions) maxSessions() int {
+	switch {
+	case o.MaxSessions < 0:
+		return 0
+	case o.MaxSessions == 0:
+		return DefaultMaxChunkSessions
+	}
+	return o.MaxSessions
+}
+
+// maxBytes 返回分片总字节数上限，0表示不限制
+// MaxBytes为0时使用defaultMax
+func (o ChunkStoreOptions) maxBytes(defaultMax int64) int64 {
+	switch {
+	case o.MaxBytes < 0:
+		return 0
+	case o.MaxBytes == 0:
+		return defaultMax
+	}
+	return o.MaxBytes
+}
+
+// ChunkSession 分片上传会话
+type ChunkSession struct {
+	ID        string    `json:"id"`                 // 会话ID
+	Name      string    `json:"name"`               // 原始文件名
+	Size      int64     `json:"size"`               // 文件总字节数
+	ChunkSize int64     `json:"chunkSize"`          // 分片字节数，最后一个分片可以更小
+	Checksum  string    `json:"checksum,omitempty"` // 整个文件的SHA-256（十六进制），为空时不校验
+	Uploaded  []int     `json:"uploaded"`           // 已接收的分片序号，升序排列
+	CreatedAt time.Time `json:"createdAt"`          // 创建时间
+}
+
+// Total 返回分片总数
+func (s *ChunkSession) Total() int {
+	if s.ChunkSize <= 0 {
+		return 0
+	}
+	return int((s.Size + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// Complete 判断全部分片是否已接收
+func (s *ChunkSession) Complete() bool {
+	return len(s.Uploaded) == s.Total()
+}
+
+// chunkLength 返回指定分片应有的字节数
+func (s *ChunkSession) chunkLength(index int) int64 {
+	if index == s.Total()-1 {
+		return s.Size - int64(index)*s.ChunkSize
+	}
+	return s.ChunkSize
+}
+
+// markUploaded 记录已接收的分片
+func (s *ChunkSession) markUploaded(index int) {
+	i := sort.SearchInts(s.Uploaded, index)
+	if i < len(s.Uploaded) && s.Uploaded[i] == index {
+		return
+	}
+	s.Uploaded = append(s.Uploaded, 0)
+	copy(s.Uploaded[i+1:], s.Uploaded[i:])
+	s.Uploaded[i] = index
+}
+
+// ChunkStore 分片上传的会话存储
+type ChunkStore interface {
+	// Session 返回上传会话，不存在时返回ErrSessionNotFound
+	Session(ctx context.Context, id string) (*ChunkSession, error)
+
+	// SaveSession 保存上传会话
+	SaveSession(ctx context.Context, session *ChunkSession) error
+
+	// WriteChunk 保存分片内容，相同序号的分片会被覆盖
+	WriteChunk(ctx context.Context, id string, index int, content io.Reader) error
+
+	// OpenChunk 读取分片内容
+	OpenChunk(ctx context.Context, id string, index int) (io.ReadCloser, error)
+
+	// Delete 删除会话及其全部分片
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryChunkStore 内存会话存储
+// 适用于单实例部署，进程重启后未完成的上传需要重新开始。
+// 过期的会话在创建新会话或空间不足时清理，会话数量和分片总字节数达到上限时返回ErrChunkStoreFull
+type MemoryChunkStore struct {
+	mu       sync.Mutex
+	opts     ChunkStoreOptions
+	sessions map[string]ChunkSession
+	chunks   map[string]map[int][]byte
+	bytes    int64 // 已接收分片的总字节数
+	now      func() time.Time
+}
+
+// NewMemoryChunkStore 创建内存会话存储
+func NewMemoryChunkStore(opts ...ChunkStoreOptions) *MemoryChunkStore {
+	s := &MemoryChunkStore{
+		sessions: make(map[string]ChunkSession),
+		chunks:   make(map[string]map[int][]byte),
+		now:      time.Now,
+	}
+	if len(opts) > 0 {
+		s.opts = opts[0]
+	}
+	return s
+}
+
+// Session 实现ChunkStore接口
+func (s *MemoryChunkStore) Session(ctx context.Context, id string) (*ChunkSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if s.expired(&session, s.now()) {
+		s.remove(id)
+		return nil, ErrSessionNotFound
+	}
+	session.Uploaded = append([]int(nil), session.Uploaded...)
+	return &session, nil
+}
+
+// SaveSession 实现ChunkStore接口
+// 创建新会话前清理过期的会话，会话数量达到上限时返回ErrChunkStoreFull
+func (s *MemoryChunkStore) SaveSession(ctx context.Context, session *ChunkSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if _, ok := s.sessions[session.ID]; !ok {
+		s.sweep(now)
+		if max := s.opts.maxSessions(); max > 0 && len(s.sessions) >= max {
+			return ErrChunkStoreFull
+		}
+	}
+	saved := *session
+	saved.Uploaded = append([]int(nil), session.Uploaded...)
+	if saved.CreatedAt.IsZero() {
+		saved.CreatedAt = now
+	}
+	s.sessions[session.ID] = saved
+	return nil
+}
+
+// WriteChunk 实现ChunkStore接口
+// 分片总字节数超过上限时先清理过期的会话，仍然超过时返回ErrChunkStoreFull
+func (s *MemoryChunkStore) WriteChunk(ctx context.Context, id string, index int, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	session, ok := s.sessions[id]
+	if !ok || s.expired(&session, now) {
+		s.remove(id)
+		return ErrSessionNotFound
+	}
+	delta := int64(len(data) - len(s.chunks[id][index]))
+	if max := s.opts.maxBytes(DefaultMaxChunkBytes); max > 0 && s.bytes+delta > max {
+		s.sweep(now)
+		if s.bytes+delta > max {
+			return ErrChunkStoreFull
+		}
+	}
+	if s.chunks[id] == nil {
+		s.chunks[id] = make(map[int][]byte)
+	}
+	s.chunks[id][index] = data
+	s.bytes += delta
+	return nil
+}
+
+// OpenChunk 实现ChunkStore接口
+func (s *MemoryChunkStore) OpenChunk(ctx context.Context, id string, index int) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.chunks[id][index]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete 实现ChunkStore接口
+func (s *MemoryChunkStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(id)
+	return nil
+}
+
+// expired 判断会话是否已过期
+func (s *MemoryChunkStore) expired(session *ChunkSession, now time.Time) bool {
+	return now.Sub(session.CreatedAt) > s.opts.ttl()
+}
+
+// remove 删除会话及其分片，调用方需持有锁
+func (s *MemoryChunkStore) remove(id string) {
+	for _, data := range s.chunks[id] {
+		s.bytes -= int64(len(data))
+	}
+	delete(s.sessions, id)
+	delete(s.chunks, id)
+}
+
+// sweep 清理过期的会话，调用方需持有锁
+func (s *MemoryChunkStore) sweep(now time.Time) {
+	for id, session := range s.sessions {
+		if s.expired(&session, now) {
+			s.remove(id)
+		}
+	}
+}
+
+// FileChunkStore 本地目录会话存储
+// 每个会话一个子目录，包含session.json和以序号命名的分片文件，进程重启后可以继续上传。
+// 过期的会话在读取时视为不存在，创建新会话时自动清理（最多每10分钟一次），也可以定时调用Sweep。
+// 会话数量和分片总字节数达到上限时先清理过期的会话，仍然超过时返回ErrChunkStoreFull；
+// 用量按目录中的文件统计，多个进程共用同一目录时上限可能被短暂超过
+type FileChunkStore struct {
+	// Dir 保存会话的目录
+	Dir string
+
+	// TTL 会话有效期，不大于0时使用DefaultChunkSessionTTL
+	TTL time.Duration
+
+	// MaxSessions 同时存在的最大会话数，为0时使用DefaultMaxChunkSessions，小于0时不限制
+	MaxSessions int
+
+	// MaxBytes 已接收分片的总字节数上限，为0时使用DefaultMaxFileChunkBytes，小于0时不限制
+	MaxBytes int64
+
+	mu        sync.Mutex
+	lastSweep time.Time
+	bytes     int64 // 已接收分片的总字节数
+	counted   bool  // bytes是否有效，删除会话后需要重新统计
+}
+
+// NewFileChunkStore 创建本地目录会话存储
+func NewFileChunkStore(dir string, opts ...ChunkStoreOptions) *FileChunkStore {
+	s := &FileChunkStore{Dir: dir}
+	if len(opts) > 0 {
+		s.TTL = opts[0].TTL
+		s.MaxSessions = opts[0].MaxSessions
+		s.MaxBytes = opts[0].MaxBytes
+	}
+	return s
+}
+
+// options 返回存储选项
+func (s *FileChunkStore) options() ChunkStoreOptions {
+	return ChunkStoreOptions{TTL: s.TTL, MaxSessions: s.MaxSessions, MaxBytes: s.MaxBytes}
+}
+
+// ttl 返回会话有效期
+func (s *FileChunkStore) ttl() time.Duration {
+	return s.options().ttl()
+}
+
+// Sweep 删除过期的会话目录
+// 会话文件无法读取时按目录的修改时间判断
+func (s *FileChunkStore) Sweep(ctx context.Context) error {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if !entry.IsDir() || !isSessionID(entry.Name()) {
+			continue
+		}
+		created, err := s.createdAt(entry)
+		if err != nil {
+			continue
+		}
+		if now.Sub(created) > s.ttl() {
+			if err := s.remove(entry.Name()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createdAt 返回会话目录的创建时间
+func (s *FileChunkStore) createdAt(entry os.DirEntry) (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, entry.Name(), "session.json"))
+	if err == nil {
+		var session ChunkSession
+		if json.Unmarshal(data, &session) == nil && !session.CreatedAt.IsZero() {
+			return session.CreatedAt, nil
+		}
+	}
+	info, err := entry.Info()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// autoSweep 距上次清理超过chunkSweepInterval时清理过期的会话
+func (s *FileChunkStore) autoSweep(ctx context.Context) {
+	s.mu.Lock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) < chunkSweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+	_ = s.Sweep(ctx)
+}
+
+// Session 实现ChunkStore接口
+func (s *FileChunkStore) Session(ctx context.Context, id string) (*ChunkSession, error) {
+	if !isSessionID(id) {
+		return nil, ErrSessionNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir, id, "session.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session ChunkSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	if time.Since(session.CreatedAt) > s.ttl() {
+		s.remove(id)
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+// SaveSession 实现ChunkStore接口
+// 先写入临时文件再重命名，避免中断时留下不完整的会话文件；
+// 创建新会话时清理过期的会话，会话数量达到上限时返回ErrChunkStoreFull
+func (s *FileChunkStore) SaveSession(ctx context.Context, session *ChunkSession) error {
+	if !isSessionID(session.ID) {
+		return ErrSessionNotFound
+	}
+	dir := filepath.Join(s.Dir, session.ID)
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		s.autoSweep(ctx)
+		if err := s.createSessionDir(ctx, dir); err != nil {
+			return err
+		}
+	}
+	if session.CreatedAt.IsZero() {
+		saved := *session
+		saved.CreatedAt = time.Now()
+		session = &saved
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, "session.json.tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, "session.json"))
+}
+
+// WriteChunk 实现ChunkStore接口
+// 分片总字节数超过上限时先清理过期的会话，仍然超过时返回ErrChunkStoreFull
+func (s *FileChunkStore) WriteChunk(ctx context.Context, id string, index int, content io.Reader) error {
+	if !isSessionID(id) {
+		return ErrSessionNotFound
+	}
+	target := s.chunkPath(id, index)
+	tmp := target + ".tmp"
+	file, err := os.Create(tmp)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	size, err := io.Copy(file, content)
+	if err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	full, err := s.commitChunk(tmp, target, size)
+	if err == nil && full {
+		if err = s.Sweep(ctx); err == nil {
+			if full, err = s.commitChunk(tmp, target, size); err == nil && full {
+				err = ErrChunkStoreFull
+			}
+		}
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// commitChunk 在总字节数不超过上限时将临时文件重命名为分片文件，超过上限时返回true
+func (s *FileChunkStore) commitChunk(tmp, target string, size int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.counted {
+		total, err := s.countBytes()
+		if err != nil {
+			return false, err
+		}
+		s.bytes, s.counted = total, true
+	}
+	var old int64
+	if info, err := os.Stat(target); err == nil {
+		old = info.Size()
+	}
+	delta := size - old
+	if max := s.options().maxBytes(DefaultMaxFileChunkBytes); max > 0 && s.bytes+delta > max {
+		return true, nil
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, ErrSessionNotFound
+		}
+		return false, err
+	}
+	s.bytes += delta
+	return false, nil
+}
+
+// createSessionDir 在会话数量未达到上限时创建会话目录
+// 达到上限时先清理过期的会话，仍然达到上限时返回ErrChunkStoreFull
+func (s *FileChunkStore) createSessionDir(ctx context.Context, dir string) error {
+	full, err := s.mkdirUnderLimit(dir)
+	if err != nil || !full {
+		return err
+	}
+	if err := s.Sweep(ctx); err != nil {
+		return err
+	}
+	if full, err = s.mkdirUnderLimit(dir); err == nil && full {
+		return ErrChunkStoreFull
+	}
+	return err
+}
+
+// mkdirUnderLimit 会话数量未达到上限时创建会话目录，达到上限时返回true
+func (s *FileChunkStore) mkdirUnderLimit(dir string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if max := s.options().maxSessions(); max > 0 {
+		entries, err := os.ReadDir(s.Dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+		n := 0
+		for _, entry := range entries {
+			if entry.IsDir() && isSessionID(entry.Name()) {
+				n++
+			}
+		}
+		if n >= max {
+			return true, nil
+		}
+	}
+	return false, os.MkdirAll(dir, 0o755)
+}
+
+// countBytes 统计全部会话目录中分片文件的总字节数，调用方需持有锁
+func (s *FileChunkStore) countBytes() (int64, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() || !isSessionID(entry.Name()) {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(s.Dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if filepath.Ext(file.Name()) != ".part" {
+				continue
+			}
+			if info, err := file.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+	}
+	return total, nil
+}
+
+// remove 删除会话目录，下次写入分片时重新统计总字节数
+func (s *FileChunkStore) remove(id string) error {
+	err := os.RemoveAll(filepath.Join(s.Dir, id))
+	s.mu.Lock()
+	s.counted = false
+	s.mu.Unlock()
+	return err
+}
+
+// OpenChunk 实现ChunkStore接口
+func (s *FileChunkStore) OpenChunk(ctx context.Context, id string, index int) (io.ReadCloser, error) {
+	if !isSessionID(id) {
+		return nil, ErrSessionNotFound
+	}
+	return os.Open(s.chunkPath(id, index))
+}
+
+// Delete 实现ChunkStore接口
+func (s *FileChunkStore) Delete(ctx context.Context, id string) error {
+	if !isSessionID(id) {
+		return ErrSessionNotFound
+	}
+	return s.remove(id)
+}
+
+// chunkPath 返回分片文件的路径
+func (s *FileChunkStore) chunkPath(id string, index int) string {
+	return filepath.Join(s.Dir, id, strconv.Itoa(index)+".part")
+}
+
+// isSessionID 判断是否为合法的会话ID（32位十六进制）
+// 会话ID会作为目录名使用，必须拒绝路径分隔符等字符
+func isSessionID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package formbuilder
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunk_store_test.go 测试分片上传的会话存储
+
+// TestChunkSession 测试会话的分片计算
+func TestChunkSession(t *testing.T) {
+	session := &ChunkSession{Size: 2500, ChunkSize: 1000}
+	assert.Equal(t, 3, session.Total())
+	assert.Equal(t, int64(1000), session.chunkLength(0))
+	assert.Equal(t, int64(500), session.chunkLength(2))
+
+	session.markUploaded(2)
+	session.markUploaded(0)
+	session.markUploaded(2)
+	assert.Equal(t, []int{0, 2}, session.Uploaded)
+	assert.False(t, session.Complete())
+
+	session.markUploaded(1)
+	assert.True(t, session.Complete())
+}
+
+// TestChunkStores 测试内存和本地目录会话存储
+func TestChunkStores(t *testing.T) {
+	stores := map[string]ChunkStore{
+		"Memory": NewMemoryChunkStore(),
+		"File":   NewFileChunkStore(t.TempDir()),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id := newSessionID()
+
+			_, err := store.Session(ctx, id)
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+			assert.ErrorIs(t, store.WriteChunk(ctx, id, 0, strings.NewReader("a")), ErrSessionNotFound)
+
+			session := &ChunkSession{ID: id, Name: "a.txt", Size: 6, ChunkSize: 3, Uploaded: []int{}, CreatedAt: time.Now()}
+			require.NoError(t, store.SaveSession(ctx, session))
+			require.NoError(t, store.WriteChunk(ctx, id, 1, strings.NewReader("def")))
+			session.markUploaded(1)
+			require.NoError(t, store.SaveSession(ctx, session))
+
+			loaded, err := store.Session(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, "a.txt", loaded.Name)
+			assert.Equal(t, []int{1}, loaded.Uploaded)
+
+			chunk, err := store.OpenChunk(ctx, id, 1)
+			require.NoError(t, err)
+			data, _ := io.ReadAll(chunk)
+			chunk.Close()
+			assert.Equal(t, "def", string(data))
+
+			_, err = store.OpenChunk(ctx, id, 0)
+			assert.Error(t, err)
+
+			require.NoError(t, store.Delete(ctx, id))
+			_, err = store.Session(ctx, id)
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+	}
+}
+
+// TestFileChunkStoreInvalidID 测试本地目录存储拒绝非法的会话ID
+func TestFileChunkStoreInvalidID(t *testing.T) {
+	store := NewFileChunkStore(t.TempDir())
+	for _, id := range []string{"", "../../etc", strings.Repeat("g", 32), strings.Repeat("A", 32)} {
+		_, err := store.Session(context.Background(), id)
+		assert.ErrorIs(t, err, ErrSessionNotFound, id)
+		assert.Error(t, store.SaveSession(context.Background(), &ChunkSession{ID: id}), id)
+	}
+}
+
+// TestMemoryChunkStoreLimits 测试内存存储的过期清理和容量上限
+func TestMemoryChunkStoreLimits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	newSession := func() *ChunkSession {
+		return &ChunkSession{ID: newSessionID(), Name: "a.txt", Size: 6, ChunkSize: 3, Uploaded: []int{}, CreatedAt: now}
+	}
+
+	t.Run("Expired", func(t *testing.T) {
+		store := NewMemoryChunkStore(ChunkStoreOptions{TTL: time.Hour})
+		session := newSession()
+		require.NoError(t, store.SaveSession(ctx, session))
+		require.NoError(t, store.WriteChunk(ctx, session.ID, 0, strings.NewReader("abc")))
+
+		store.now = func() time.Time { return now.Add(2 * time.Hour) }
+		_, err := store.Session(ctx, session.ID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		assert.ErrorIs(t, store.WriteChunk(ctx, session.ID, 1, strings.NewReader("def")), ErrSessionNotFound)
+		assert.Equal(t, int64(0), store.bytes)
+	})
+
+	t.Run("MaxSessions", func(t *testing.T) {
+		store := NewMemoryChunkStore(ChunkStoreOptions{TTL: time.Hour, MaxSessions: 1})
+		first := newSession()
+		require.NoError(t, store.SaveSession(ctx, first))
+		require.NoError(t, store.SaveSession(ctx, first), "更新已有会话不受上限限制")
+		assert.ErrorIs(t, store.SaveSession(ctx, newSession()), ErrChunkStoreFull)
+
+		store.now = func() time.Time { return now.Add(2 * time.Hour) }
+		assert.NoError(t, store.SaveSession(ctx, newSession()), "过期会话被清理后可以创建")
+	})
+
+	t.Run("MaxBytes", func(t *testing.T) {
+		store := NewMemoryChunkStore(ChunkStoreOptions{MaxBytes: 4})
+		session := newSession()
+		require.NoError(t, store.SaveSession(ctx, session))
+		require.NoError(t, store.WriteChunk(ctx, session.ID, 0, strings.NewReader("abc")))
+		require.NoError(t, store.WriteChunk(ctx, session.ID, 0, strings.NewReader("abc")), "覆盖分片不重复计算")
+		assert.ErrorIs(t, store.WriteChunk(ctx, session.ID, 1, strings.NewReader("def")), ErrChunkStoreFull)
+
+		require.NoError(t, store.Delete(ctx, session.ID))
+		assert.Equal(t, int64(0), store.bytes)
+	})
+}
+
+// TestFileChunkStoreExpiry 测试本地目录存储的过期清理
+func TestFileChunkStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewFileChunkStore(dir, ChunkStoreOptions{TTL: time.Hour})
+
+	expired := &ChunkSession{ID: newSessionID(), Name: "a.txt", Size: 3, ChunkSize: 3, Uploaded: []int{}, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	active := &ChunkSession{ID: newSessionID(), Name: "b.txt", Size: 3, ChunkSize: 3, Uploaded: []int{}, CreatedAt: time.Now()}
+	require.NoError(t, store.SaveSession(ctx, expired))
+	require.NoError(t, store.SaveSession(ctx, active))
+
+	_, err := store.Session(ctx, expired.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.NoDirExists(t, filepath.Join(dir, expired.ID))
+
+	require.NoError(t, store.SaveSession(ctx, expired))
+	require.NoError(t, store.Sweep(ctx))
+	assert.NoDirExists(t, filepath.Join(dir, expired.ID))
+	assert.DirExists(t, filepath.Join(dir, active.ID))
+}
+
+// TestFileChunkStoreLimits 测试本地目录存储的容量上限
+func TestFileChunkStoreLimits(t *testing.T) {
+	ctx := context.Background()
+	newSession := func(created time.Time) *ChunkSession {
+		return &ChunkSession{ID: newSessionID(), Name: "a.txt", Size: 6, ChunkSize: 3, Uploaded: []int{}, CreatedAt: created}
+	}
+
+	t.Run("MaxSessions", func(t *testing.T) {
+		store := NewFileChunkStore(t.TempDir(), ChunkStoreOptions{TTL: time.Hour, MaxSessions: 1})
+		expired := newSession(time.Now().Add(-2 * time.Hour))
+		require.NoError(t, store.SaveSession(ctx, expired))
+
+		first := newSession(time.Now())
+		require.NoError(t, store.SaveSession(ctx, first), "过期会话被清理后可以创建")
+		require.NoError(t, store.SaveSession(ctx, first), "更新已有会话不受上限限制")
+		assert.ErrorIs(t, store.SaveSession(ctx, newSession(time.Now())), ErrChunkStoreFull)
+	})
+
+	t.Run("MaxBytes", func(t *testing.T) {
+		dir := t.TempDir()
+		store := NewFileChunkStore(dir, ChunkStoreOptions{MaxBytes: 4})
+		session := newSession(time.Now())
+		require.NoError(t, store.SaveSession(ctx, session))
+		require.NoError(t, store.WriteChunk(ctx, session.ID, 0, strings.NewReader("abc")))
+		require.NoError(t, store.WriteChunk(ctx, session.ID, 0, strings.NewReader("abc")), "覆盖分片不重复计算")
+		assert.ErrorIs(t, store.WriteChunk(ctx, session.ID, 1, strings.NewReader("def")), ErrChunkStoreFull)
+		assert.NoFileExists(t, store.chunkPath(session.ID, 1)+".tmp")
+
+		// 重启后按目录中的文件重新统计
+		restarted := NewFileChunkStore(dir, ChunkStoreOptions{MaxBytes: 4})
+		assert.ErrorIs(t, restarted.WriteChunk(ctx, session.ID, 1, strings.NewReader("def")), ErrChunkStoreFull)
+
+		require.NoError(t, restarted.Delete(ctx, session.ID))
+		other := newSession(time.Now())
+		require.NoError(t, restarted.SaveSession(ctx, other))
+		assert.NoError(t, restarted.WriteChunk(ctx, other.ID, 0, strings.NewReader("def")), "删除会话后释放空间")
+	})
+}
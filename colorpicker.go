@@ -53,6 +53,12 @@ func (c *ColorPicker) Size(size string) *ColorPicker {
 	return c
 }
 
+// Normalize 实现Normalizer接口
+// 颜色值转换为字符串
+func (c *ColorPicker) Normalize(value interface{}) (interface{}, error) {
+	return normalizeString(value)
+}
+
 // GetField 实现Component接口
 func (c *ColorPicker) GetField() string {
 	return c.data.Field
@@ -37,6 +37,10 @@ type ComponentData struct {
 	// Validate 验证规则数组
 	Validate []ValidateRule
 
+	// Sanitizers 清理器数组
+	// 在服务端验证之前对提交的字符串执行，为nil时使用组件的默认清理器
+	Sanitizers []Sanitizer
+
 	// Control 条件显示规则数组
 	// 根据当前组件的值决定显示哪些其他组件
 	Control []ControlRule
@@ -94,6 +98,31 @@ func (b *Builder[T]) Required() T {
 	return b.inst
 }
 
+// RequiredIf 添加条件必填验证规则
+// field的值等于values中任意一个时当前字段必填
+//
+// 使用示例：
+//
+//	input.RequiredIf("invoice_type", "company")
+func (b *Builder[T]) RequiredIf(field string, values ...interface{}) T {
+	b.data.Validate = append(b.data.Validate, NewRequiredIf(field, values, "此项必填"))
+	return b.inst
+}
+
+// RequiredUnless 添加条件必填验证规则
+// 除非field的值等于values中任意一个，否则当前字段必填
+func (b *Builder[T]) RequiredUnless(field string, values ...interface{}) T {
+	b.data.Validate = append(b.data.Validate, NewRequiredUnless(field, values, "此项必填"))
+	return b.inst
+}
+
+// RequiredWith 添加条件必填验证规则
+// fields中任意一个字段有值时当前字段必填
+func (b *Builder[T]) RequiredWith(fields ...string) T {
+	b.data.Validate = append(b.data.Validate, NewRequiredWith(fields, "此项必填"))
+	return b.inst
+}
+
 // Value 设置组件默认值
 func (b *Builder[T]) Value(v interface{}) T {
 	b.data.Value = v
@@ -168,6 +197,20 @@ func (b *Builder[T]) Validate(rules ...ValidateRule) T {
 	return b.inst
 }
 
+// Sanitize 添加清理器
+// 清理器按添加顺序执行；不传参数时清除组件的默认清理器
+//
+// 使用示例：
+//
+//	input.Sanitize(Trim, StripTags, CollapseSpaces)
+func (b *Builder[T]) Sanitize(sanitizers ...Sanitizer) T {
+	if b.data.Sanitizers == nil {
+		b.data.Sanitizers = []Sanitizer{}
+	}
+	b.data.Sanitizers = append(b.data.Sanitizers, sanitizers...)
+	return b.inst
+}
+
 // Children 设置子组件
 func (b *Builder[T]) Children(children []Component) T {
 	b.data.Children = children
@@ -260,7 +303,7 @@ func (b *Builder[T]) Col(col interface{}) T {
 // 处理流程：
 // 1. 基础字段（type, field, title, value）
 // 2. Props属性
-// 3. Validate验证规则
+// 3. Validate验证规则与Sanitize清理器名称
 // 4. Control条件显示规则（递归处理）
 // 5. Children子组件（递归处理）
 // 6. Emit事件配置
@@ -297,6 +340,9 @@ func buildComponent(data *ComponentData) map[string]interface{} {
 		}
 		result["validate"] = validates
 	}
+	if len(data.Sanitizers) > 0 {
+		result["sanitize"] = sanitizerNames(data.Sanitizers)
+	}
 
 	// 4. Control条件显示规则（递归处理）
 	if len(data.Control) > 0 {
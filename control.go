@@ -0,0 +1,101 @@
+package formbuilder
+
+import "reflect"
+
+// control.go 实现Control条件显示规则的服务端求值
+// 根据提交的数据判断哪些ControlRule分支处于显示状态，
+// 隐藏分支中的字段既不参与验证，也不接受提交的值
+
+// Match 判断组件值是否触发该条件规则
+// 组件值为数组（如Checkbox）而规则值不是数组时，数组包含规则值即视为触发
+func (c ControlRule) Match(value interface{}) bool {
+	if valuesEqual(value, c.Value) {
+		return true
+	}
+	if c.Value == nil || isSliceValue(c.Value) {
+		return false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return false
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if valuesEqual(rv.Index(i).Interface(), c.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// eachActiveComponent 递归遍历当前显示的组件
+// 只进入与提交值匹配的control分支，children总是显示
+func (f *Form) eachActiveComponent(rules []Component, values map[string]interface{}, fn func(c Component, data *ComponentData)) {
+	for _, rule := range rules {
+		data := f.getComponentData(rule)
+		if data == nil {
+			continue
+		}
+		fn(rule, data)
+		if len(data.Control) > 0 {
+			value := values[data.Field]
+			for _, ctrl := range data.Control {
+				if ctrl.Match(value) {
+					f.eachActiveComponent(ctrl.Rule, values, fn)
+				}
+			}
+		}
+		f.eachActiveComponent(data.Children, values, fn)
+	}
+}
+
+// ActiveFields 返回在给定提交数据下处于显示状态的字段
+// 按组件声明顺序返回
+//
+// 使用示例：
+//
+//	// delivery_type=express 时返回 [delivery_type address receiver phone ...]
+//	fields := form.ActiveFields(values)
+func (f *Form) ActiveFields(values map[string]interface{}) []string {
+	var fields []string
+	f.eachActiveComponent(f.rules, values, func(c Component, data *ComponentData) {
+		if data.Field != "" {
+			fields = append(fields, data.Field)
+		}
+	})
+	return fields
+}
+
+// StripHidden 移除隐藏字段的值
+// 返回新的map，其中不包含处于未激活control分支中的字段，
+// 防止客户端向隐藏字段提交数据；未在表单中声明的字段原样保留
+func (f *Form) StripHidden(values map[string]interface{}) map[string]interface{} {
+	active := make(map[string]bool)
+	for _, field := range f.ActiveFields(values) {
+		active[field] = true
+	}
+
+	declared := make(map[string]bool)
+	f.eachComponent(f.rules, func(c Component, data *ComponentData) {
+		if data.Field != "" {
+			declared[data.Field] = true
+		}
+	})
+
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if declared[k] && !active[k] {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// isSliceValue 判断值是否为数组或切片
+func isSliceValue(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	kind := reflect.TypeOf(value).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
@@ -0,0 +1,137 @@
+package formbuilder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// control_test.go 测试Control条件显示规则的服务端求值
+
+// createDeliveryForm 创建与examples/control.go结构相同的测试表单
+func createDeliveryForm() *Form {
+	return NewElmForm("/api/order", []Component{
+		NewRadio("delivery_type", "配送方式", "express").
+			SetOptions([]Option{
+				{Value: "express", Label: "快递配送"},
+				{Value: "pickup", Label: "到店自提"},
+			}).
+			Control([]ControlRule{
+				{Value: "express", Rule: []Component{
+					NewInput("address", "收货地址").Required(),
+				}},
+				{Value: "pickup", Rule: []Component{
+					NewSelect("store", "自提门店").Required(),
+				}},
+			}),
+		NewSwitch("need_invoice", "是否需要发票").
+			Control([]ControlRule{
+				{Value: true, Rule: []Component{
+					NewRadio("invoice_type", "发票类型").Control([]ControlRule{
+						{Value: "company", Rule: []Component{
+							NewInput("tax_number", "税号").Required(),
+						}},
+					}),
+				}},
+			}),
+	}, nil)
+}
+
+// TestControlRuleMatch 测试条件匹配
+func TestControlRuleMatch(t *testing.T) {
+	t.Run("Equal", func(t *testing.T) {
+		assert.True(t, ControlRule{Value: "1"}.Match("1"))
+		assert.False(t, ControlRule{Value: "1"}.Match("2"))
+	})
+
+	t.Run("NumericTypes", func(t *testing.T) {
+		assert.True(t, ControlRule{Value: 1}.Match(float64(1)))
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		assert.True(t, ControlRule{Value: true}.Match(true))
+		assert.False(t, ControlRule{Value: true}.Match(nil))
+	})
+
+	t.Run("SliceContains", func(t *testing.T) {
+		assert.True(t, ControlRule{Value: "a"}.Match([]interface{}{"b", "a"}))
+		assert.False(t, ControlRule{Value: "c"}.Match([]interface{}{"b", "a"}))
+	})
+}
+
+// TestFormActiveFields 测试显示字段求值
+func TestFormActiveFields(t *testing.T) {
+	form := createDeliveryForm()
+
+	t.Run("ExpressBranch", func(t *testing.T) {
+		fields := form.ActiveFields(map[string]interface{}{"delivery_type": "express"})
+		assert.Equal(t, []string{"delivery_type", "address", "need_invoice"}, fields)
+	})
+
+	t.Run("NestedBranch", func(t *testing.T) {
+		fields := form.ActiveFields(map[string]interface{}{
+			"delivery_type": "pickup",
+			"need_invoice":  true,
+			"invoice_type":  "company",
+		})
+		assert.Equal(t, []string{"delivery_type", "store", "need_invoice", "invoice_type", "tax_number"}, fields)
+	})
+
+	t.Run("HiddenParentHidesNested", func(t *testing.T) {
+		fields := form.ActiveFields(map[string]interface{}{
+			"need_invoice": false,
+			"invoice_type": "company",
+		})
+		assert.NotContains(t, fields, "invoice_type")
+		assert.NotContains(t, fields, "tax_number")
+	})
+}
+
+// TestFormStripHidden 测试隐藏字段值的移除
+func TestFormStripHidden(t *testing.T) {
+	form := createDeliveryForm()
+
+	values := map[string]interface{}{
+		"delivery_type": "express",
+		"address":       "北京",
+		"store":         "store1",
+		"tax_number":    "123",
+		"extra":         "x",
+	}
+	result := form.StripHidden(values)
+
+	assert.Equal(t, map[string]interface{}{
+		"delivery_type": "express",
+		"address":       "北京",
+		"extra":         "x",
+	}, result)
+	assert.Contains(t, values, "store", "原始数据不应被修改")
+}
+
+// TestValidateDataControlAware 测试验证只作用于显示字段
+func TestValidateDataControlAware(t *testing.T) {
+	form := createDeliveryForm()
+
+	t.Run("HiddenRequiredIgnored", func(t *testing.T) {
+		err := form.ValidateData(map[string]interface{}{
+			"delivery_type": "pickup",
+			"store":         "store1",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ActiveRequiredEnforced", func(t *testing.T) {
+		err := form.ValidateData(map[string]interface{}{
+			"delivery_type": "express",
+			"need_invoice":  true,
+			"invoice_type":  "company",
+		})
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Len(t, errs.Get("address"), 1)
+		assert.Len(t, errs.Get("tax_number"), 1)
+		assert.Empty(t, errs.Get("store"))
+	})
+}
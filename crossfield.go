@@ -0,0 +1,426 @@
+package formbuilder
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// crossfield.go 实现跨字段验证规则（字段比较、条件必填）
+// 规则同时输出可在form-create中执行的前端validator函数，
+// 并在服务端验证时读取完整的提交数据进行比较
+
+// 比较运算符
+const (
+	CompareEQ  = "=="
+	CompareNE  = "!="
+	CompareGT  = ">"
+	CompareGTE = ">="
+	CompareLT  = "<"
+	CompareLTE = "<="
+)
+
+// EqualToFieldRule 字段相等验证规则
+// 验证当前字段的值与另一个字段相同，如确认密码
+//
+// 使用示例：
+//
+//	Password("password_confirm", "确认密码").
+//	    Validate(NewEqualTo("password", "两次输入的密码不一致"))
+type EqualToFieldRule struct {
+	Field   string // 比较的字段名
+	Message string // 验证失败提示信息
+	Trigger string // 触发方式：blur, change
+}
+
+// ToMap 实现ValidateRule接口
+func (r EqualToFieldRule) ToMap() map[string]interface{} {
+	expr := "JSON.stringify(value) === JSON.stringify(getValue(" + jsLiteral(r.Field) + "))"
+	rule := crossFieldRuleMap(expr, withDefault(r.Message, "两次输入不一致"), r.Trigger)
+	return withRuleData(rule, "equalTo", map[string]interface{}{"field": r.Field})
+}
+
+// Check 实现Checker接口
+func (r EqualToFieldRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	if !valuesEqual(value, vc.Values[r.Field]) {
+		return newRuleError("equalTo", r.Message, "两次输入不一致").
+			withParams(map[string]interface{}{"field": r.Field})
+	}
+	return nil
+}
+
+// CompareFieldRule 字段比较验证规则
+// 将当前字段的值与另一个字段按运算符比较
+// 两个值都是数字时按数值比较，否则按字符串比较；另一个字段为空时跳过
+//
+// 使用示例：
+//
+//	Number("max_price", "最高价").
+//	    Validate(NewCompare("min_price", CompareGTE, "最高价不能低于最低价"))
+type CompareFieldRule struct {
+	Field    string // 比较的字段名
+	Operator string // 比较运算符：CompareEQ, CompareGT 等
+	Message  string // 验证失败提示信息
+	Trigger  string // 触发方式：blur, change
+}
+
+// ToMap 实现ValidateRule接口
+func (r CompareFieldRule) ToMap() map[string]interface{} {
+	expr := "(function(a, b) {" +
+		" if (b === undefined || b === null || b === '') { return true; }" +
+		" if (a !== '' && b !== '' && !isNaN(a) && !isNaN(b)) { a = Number(a); b = Number(b); }" +
+		" else { a = String(a); b = String(b); }" +
+		" return a " + jsOperator(r.Operator) + " b;" +
+		" })(value, getValue(" + jsLiteral(r.Field) + "))"
+	rule := crossFieldRuleMap(expr, withDefault(r.Message, "比较验证失败"), r.Trigger)
+	return withRuleData(rule, "compare", map[string]interface{}{"field": r.Field, "operator": r.Operator})
+}
+
+// Check 实现Checker接口
+func (r CompareFieldRule) Check(value interface{}, vc *ValidateContext) error {
+	other := vc.Values[r.Field]
+	if isEmptyValue(value) || isEmptyValue(other) {
+		return nil
+	}
+	if !compareResult(compareValues(value, other), r.Operator) {
+		return newRuleError("compare", r.Message, "比较验证失败").
+			withParams(map[string]interface{}{"field": r.Field, "operator": r.Operator})
+	}
+	return nil
+}
+
+// DateOrderRule 日期先后验证规则
+// 将当前字段的日期与另一个字段的日期按运算符比较，如结束日期必须晚于开始日期
+// 另一个字段为空或无法解析时跳过，当前字段无法解析为日期（包括数组）时验证失败，前后端一致
+//
+// 使用示例：
+//
+//	NewDatePicker("end_date", "结束日期").
+//	    Validate(NewDateAfter("start_date", "结束日期必须晚于开始日期"))
+type DateOrderRule struct {
+	Field    string // 比较的字段名
+	Operator string // 比较运算符：CompareGT表示晚于，CompareLT表示早于
+	Message  string // 验证失败提示信息
+	Trigger  string // 触发方式：blur, change
+}
+
+// ToMap 实现ValidateRule接口
+func (r DateOrderRule) ToMap() map[string]interface{} {
+	expr := "(function(a, b) {" +
+		" var toTime = function(v) {" +
+		" if (Array.isArray(v)) { return NaN; }" +
+		" if (v instanceof Date) { return v.getTime(); }" +
+		" if (typeof v === 'number') { return v; }" +
+		" var m = /^(\\d{1,2}):(\\d{2})(?::(\\d{2}))?$/.exec(v);" +
+		" if (m) { return ((+m[1]) * 3600 + (+m[2]) * 60 + (+(m[3] || 0))) * 1000; }" +
+		" var t = Date.parse(v); return isNaN(t) ? Date.parse(String(v).replace(/-/g, '/')) : t; };" +
+		" if (b === undefined || b === null || b === '') { return true; }" +
+		" a = toTime(a); b = toTime(b);" +
+		" if (isNaN(b)) { return true; }" +
+		" if (isNaN(a)) { return false; }" +
+		" return a " + jsOperator(r.Operator) + " b;" +
+		" })(value, getValue(" + jsLiteral(r.Field) + "))"
+	rule := crossFieldRuleMap(expr, withDefault(r.Message, "日期先后顺序不正确"), r.Trigger)
+	return withRuleData(rule, "dateOrder", map[string]interface{}{"field": r.Field, "operator": r.Operator})
+}
+
+// Check 实现Checker接口
+func (r DateOrderRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) || isEmptyValue(vc.Values[r.Field]) {
+		return nil
+	}
+	current, ok := parseDateValue(value)
+	if !ok {
+		return newRuleError("dateOrder", r.Message, "请输入正确的日期").
+			withParams(map[string]interface{}{"field": r.Field, "operator": r.Operator})
+	}
+	other, ok := parseDateValue(vc.Values[r.Field])
+	if !ok {
+		return nil
+	}
+	if !compareResult(current.Compare(other), r.Operator) {
+		return newRuleError("dateOrder", r.Message, "日期先后顺序不正确").
+			withParams(map[string]interface{}{"field": r.Field, "operator": r.Operator})
+	}
+	return nil
+}
+
+// RequiredIfRule 条件必填验证规则
+// 另一个字段的值等于Values中任意一个时，当前字段必填
+//
+// 使用示例：
+//
+//	NewInput("tax_id", "税号").
+//	    Validate(NewRequiredIf("invoice_type", []interface{}{"company"}, "请输入税号"))
+type RequiredIfRule struct {
+	Field   string        // 条件字段名
+	Values  []interface{} // 触发必填的条件值
+	Message string        // 验证失败提示信息
+	Trigger string        // 触发方式：blur, change
+}
+
+// ToMap 实现ValidateRule接口
+func (r RequiredIfRule) ToMap() map[string]interface{} {
+	rule := conditionalRequiredMap(jsContains(r.Values, "getValue("+jsLiteral(r.Field)+")"), r.Message, r.Trigger)
+	return withRuleData(rule, "requiredIf", map[string]interface{}{"field": r.Field, "values": r.Values})
+}
+
+// Check 实现Checker接口
+func (r RequiredIfRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) && containsValue(r.Values, vc.Values[r.Field]) {
+		return newRuleError("requiredIf", r.Message, "此项必填").
+			withParams(map[string]interface{}{"field": r.Field, "values": r.Values})
+	}
+	return nil
+}
+
+// RequiredUnlessRule 条件必填验证规则
+// 除非另一个字段的值等于Values中任意一个，否则当前字段必填
+//
+// 使用示例：
+//
+//	NewInput("address", "收货地址").
+//	    Validate(NewRequiredUnless("delivery_type", []interface{}{"pickup"}, "请输入收货地址"))
+type RequiredUnlessRule struct {
+	Field   string        // 条件字段名
+	Values  []interface{} // 免于必填的条件值
+	Message string        // 验证失败提示信息
+	Trigger string        // 触发方式：blur, change
+}
+
+// ToMap 实现ValidateRule接口
+func (r RequiredUnlessRule) ToMap() map[string]interface{} {
+	rule := conditionalRequiredMap("!"+jsContains(r.Values, "getValue("+jsLiteral(r.Field)+")"), r.Message, r.Trigger)
+	return withRuleData(rule, "requiredUnless", map[string]interface{}{"field": r.Field, "values": r.Values})
+}
+
+// Check 实现Checker接口
+func (r RequiredUnlessRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) && !containsValue(r.Values, vc.Values[r.Field]) {
+		return newRuleError("requiredUnless", r.Message, "此项必填").
+			withParams(map[string]interface{}{"field": r.Field, "values": r.Values})
+	}
+	return nil
+}
+
+// RequiredWithRule 条件必填验证规则
+// Fields中任意一个字段有值时，当前字段必填
+//
+// 使用示例：
+//
+//	NewInput("area_code", "区号").
+//	    Validate(NewRequiredWith([]string{"landline"}, "请输入区号"))
+type RequiredWithRule struct {
+	Fields  []string // 关联字段名
+	Message string   // 验证失败提示信息
+	Trigger string   // 触发方式：blur, change
+}
+
+// ToMap 实现ValidateRule接口
+func (r RequiredWithRule) ToMap() map[string]interface{} {
+	cond := jsLiteral(r.Fields) + ".some(function(f) { return !isEmpty(getValue(f)); })"
+	rule := conditionalRequiredMap(cond, r.Message, r.Trigger)
+	return withRuleData(rule, "requiredWith", map[string]interface{}{"fields": r.Fields})
+}
+
+// Check 实现Checker接口
+func (r RequiredWithRule) Check(value interface{}, vc *ValidateContext) error {
+	if !isEmptyValue(value) {
+		return nil
+	}
+	for _, field := range r.Fields {
+		if !isEmptyValue(vc.Values[field]) {
+			return newRuleError("requiredWith", r.Message, "此项必填").
+				withParams(map[string]interface{}{"fields": r.Fields})
+		}
+	}
+	return nil
+}
+
+// 便捷构造函数
+
+// NewEqualTo 创建字段相等验证规则
+func NewEqualTo(field, message string) EqualToFieldRule {
+	return EqualToFieldRule{Field: field, Message: message}
+}
+
+// NewCompare 创建字段比较验证规则
+func NewCompare(field, operator, message string) CompareFieldRule {
+	return CompareFieldRule{Field: field, Operator: operator, Message: message}
+}
+
+// NewDateAfter 创建日期晚于验证规则
+func NewDateAfter(field, message string) DateOrderRule {
+	return DateOrderRule{Field: field, Operator: CompareGT, Message: message}
+}
+
+// NewDateBefore 创建日期早于验证规则
+func NewDateBefore(field, message string) DateOrderRule {
+	return DateOrderRule{Field: field, Operator: CompareLT, Message: message}
+}
+
+// NewRequiredIf 创建条件必填验证规则
+func NewRequiredIf(field string, values []interface{}, message string) RequiredIfRule {
+	return RequiredIfRule{Field: field, Values: values, Message: message}
+}
+
+// NewRequiredUnless 创建条件必填验证规则
+func NewRequiredUnless(field string, values []interface{}, message string) RequiredUnlessRule {
+	return RequiredUnlessRule{Field: field, Values: values, Message: message}
+}
+
+// NewRequiredWith 创建条件必填验证规则
+func NewRequiredWith(fields []string, message string) RequiredWithRule {
+	return RequiredWithRule{Fields: fields, Message: message}
+}
+
+// ruleDataKey 验证规则map中记录规则名称和参数的键
+// 生成validator函数的内置规则在此键下以数据保存规则名称（name）和参数，
+// ParseRules据此还原规则，不解析validator的源码
+const ruleDataKey = "__rule"
+
+// withRuleData 在规则map中记录规则名称和参数
+func withRuleData(rule map[string]interface{}, name string, params map[string]interface{}) map[string]interface{} {
+	data := map[string]interface{}{"name": name}
+	for k, v := range params {
+		data[k] = v
+	}
+	rule[ruleDataKey] = data
+	return rule
+}
+
+// crossFieldRuleMap 生成跨字段规则的map
+// valid为JavaScript布尔表达式，可使用value（当前值）和getValue(field)（其他字段的值）
+func crossFieldRuleMap(valid, message, trigger string) map[string]interface{} {
+	rule := map[string]interface{}{
+		"validator": jsValidator(
+			"if (isEmpty(value)) { return callback(); }" +
+				" if (" + valid + ") { callback(); } else { callback(new Error(" + jsLiteral(message) + ")); }",
+		),
+		"message": message,
+	}
+	if trigger != "" {
+		rule["trigger"] = trigger
+	}
+	return rule
+}
+
+// conditionalRequiredMap 生成条件必填规则的map
+// required为JavaScript布尔表达式，成立且当前值为空时验证失败
+func conditionalRequiredMap(required, message, trigger string) map[string]interface{} {
+	message = withDefault(message, "此项必填")
+	rule := map[string]interface{}{
+		"validator": jsValidator(
+			"if ((" + required + ") && isEmpty(value)) { callback(new Error(" + jsLiteral(message) + ")); } else { callback(); }",
+		),
+		"message": message,
+	}
+	if trigger != "" {
+		rule["trigger"] = trigger
+	}
+	return rule
+}
+
+// jsValidator 生成form-create的validator函数
+// 函数体中可通过getValue(field)读取其他字段的值，通过isEmpty(v)判断值是否为空，
+// 表单API取自validator的this.api或FormScript注册的window.$fApi
+func jsValidator(body string) JSFunc {
+	return JSFunc("function(rule, value, callback) {" +
+		" var api = (this && this.api) || window.$fApi;" +
+		" var getValue = function(field) { return api ? api.getValue(field) : undefined; };" +
+		" var isEmpty = function(v) { return v === undefined || v === null || v === '' || (Array.isArray(v) && v.length === 0); };" +
+		" " + body + " }")
+}
+
+// jsContains 生成判断expr的值是否在values中的JavaScript表达式
+func jsContains(values []interface{}, expr string) string {
+	return "(function(v) { return " + jsLiteral(values) + ".some(function(c) {" +
+		" return JSON.stringify(c) === JSON.stringify(v); }); })(" + expr + ")"
+}
+
+// jsLiteral 将Go值编码为JavaScript字面量
+func jsLiteral(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// jsOperator 将比较运算符转换为JavaScript运算符
+func jsOperator(op string) string {
+	switch op {
+	case CompareEQ:
+		return "==="
+	case CompareNE:
+		return "!=="
+	case CompareGT, CompareGTE, CompareLT, CompareLTE:
+		return op
+	}
+	// 未知运算符总是验证失败，与服务端行为一致
+	return "&& false &&"
+}
+
+// compareValues 比较两个值
+// 两者都可转换为数字时按数值比较，否则按字符串比较
+func compareValues(a, b interface{}) int {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	sa, _ := stringValue(a)
+	sb, _ := stringValue(b)
+	if sa == "" {
+		sa = jsLiteral(a)
+	}
+	if sb == "" {
+		sb = jsLiteral(b)
+	}
+	return strings.Compare(sa, sb)
+}
+
+// compareResult 根据比较结果和运算符判断是否成立
+func compareResult(cmp int, op string) bool {
+	switch op {
+	case CompareEQ:
+		return cmp == 0
+	case CompareNE:
+		return cmp != 0
+	case CompareGT:
+		return cmp > 0
+	case CompareGTE:
+		return cmp >= 0
+	case CompareLT:
+		return cmp < 0
+	case CompareLTE:
+		return cmp <= 0
+	}
+	return false
+}
+
+// containsValue 判断value是否等于values中的任意一个
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if valuesEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// withDefault 返回非空的字符串
+func withDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,226 @@
+package formbuilder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crossfield_test.go 测试跨字段验证规则
+
+// TestEqualToFieldRule 测试字段相等规则
+func TestEqualToFieldRule(t *testing.T) {
+	values := map[string]interface{}{"password": "secret"}
+	vc := &ValidateContext{Field: "confirm", Values: values}
+
+	t.Run("Equal", func(t *testing.T) {
+		assert.NoError(t, NewEqualTo("password", "").Check("secret", vc))
+	})
+
+	t.Run("NotEqual", func(t *testing.T) {
+		err := NewEqualTo("password", "两次输入的密码不一致").Check("other", vc)
+		var fe *FieldError
+		require.True(t, errors.As(err, &fe))
+		assert.Equal(t, "equalTo", fe.Rule)
+		assert.Equal(t, "两次输入的密码不一致", fe.Message)
+	})
+
+	t.Run("EmptySkipped", func(t *testing.T) {
+		assert.NoError(t, NewEqualTo("password", "").Check("", vc))
+	})
+
+	t.Run("ToMap", func(t *testing.T) {
+		m := EqualToFieldRule{Field: "password", Message: "不一致", Trigger: "blur"}.ToMap()
+		assert.Equal(t, "不一致", m["message"])
+		assert.Equal(t, "blur", m["trigger"])
+		validator, ok := m["validator"].(JSFunc)
+		require.True(t, ok)
+		assert.Contains(t, validator, "function(rule, value, callback)")
+		assert.Contains(t, validator, `getValue("password")`)
+	})
+}
+
+// TestCompareFieldRule 测试字段比较规则
+func TestCompareFieldRule(t *testing.T) {
+	values := map[string]interface{}{"min": float64(10), "code": "b"}
+
+	cases := []struct {
+		name  string
+		rule  CompareFieldRule
+		value interface{}
+		valid bool
+	}{
+		{"GTE", NewCompare("min", CompareGTE, ""), float64(10), true},
+		{"GTEFail", NewCompare("min", CompareGTE, ""), float64(9), false},
+		{"GTNumericString", NewCompare("min", CompareGT, ""), "11", true},
+		{"LT", NewCompare("min", CompareLT, ""), 3, true},
+		{"NE", NewCompare("min", CompareNE, ""), 10, false},
+		{"StringCompare", NewCompare("code", CompareGT, ""), "c", true},
+		{"OtherEmpty", NewCompare("missing", CompareGT, ""), 1, true},
+		{"UnknownOperator", NewCompare("min", "~", ""), 1, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.Check(tc.value, &ValidateContext{Values: values})
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("ToMapOperator", func(t *testing.T) {
+		m := NewCompare("min", CompareEQ, "").ToMap()
+		assert.Contains(t, m["validator"], "return a === b;")
+		assert.Equal(t, "比较验证失败", m["message"])
+	})
+}
+
+// TestDateOrderRule 测试日期先后规则
+func TestDateOrderRule(t *testing.T) {
+	values := map[string]interface{}{
+		"start_date": "2024-01-10",
+		"start_time": "09:00:00",
+	}
+	vc := &ValidateContext{Values: values}
+
+	t.Run("After", func(t *testing.T) {
+		assert.NoError(t, NewDateAfter("start_date", "").Check("2024-01-11", vc))
+		assert.Error(t, NewDateAfter("start_date", "").Check("2024-01-10", vc))
+	})
+
+	t.Run("Before", func(t *testing.T) {
+		assert.NoError(t, NewDateBefore("start_date", "").Check("2024-01-09 23:59:59", vc))
+		assert.Error(t, NewDateBefore("start_date", "").Check("2024-02-01", vc))
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		assert.NoError(t, NewDateAfter("start_time", "").Check("18:00:00", vc))
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		err := NewDateAfter("start_date", "").Check("tomorrow", vc)
+		var fe *FieldError
+		require.True(t, errors.As(err, &fe))
+		assert.Equal(t, "dateOrder", fe.Rule)
+	})
+
+	t.Run("OtherMissing", func(t *testing.T) {
+		assert.NoError(t, NewDateAfter("missing", "").Check("2024-01-01", vc))
+	})
+
+	t.Run("Array", func(t *testing.T) {
+		assert.Error(t, NewDateAfter("start_date", "").Check([]interface{}{"2024-01-11"}, vc))
+		assert.NoError(t, NewDateAfter("range", "").Check("2024-01-11",
+			&ValidateContext{Values: map[string]interface{}{"range": []interface{}{"2024-01-01"}}}))
+
+		validator := string(NewDateAfter("start_date", "").ToMap()["validator"].(JSFunc))
+		assert.Contains(t, validator, "if (Array.isArray(v)) { return NaN; }")
+		assert.Contains(t, validator, "if (isNaN(b)) { return true; } if (isNaN(a)) { return false; }")
+	})
+}
+
+// TestCrossFieldValidateData 测试跨字段规则在表单验证中的执行
+func TestCrossFieldValidateData(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		Password("password", "密码").Required(),
+		Password("password_confirm", "确认密码").
+			Validate(NewEqualTo("password", "两次输入的密码不一致")),
+		NewDatePicker("start_date", "开始日期"),
+		NewDatePicker("end_date", "结束日期").
+			Validate(NewDateAfter("start_date", "结束日期必须晚于开始日期")),
+	}, nil)
+
+	err := form.ValidateData(map[string]interface{}{
+		"password":         "secret",
+		"password_confirm": "secret2",
+		"start_date":       "2024-05-01",
+		"end_date":         "2024-04-01",
+	})
+
+	var errs FieldErrors
+	require.True(t, errors.As(err, &errs))
+	assert.Equal(t, map[string][]string{
+		"password_confirm": {"两次输入的密码不一致"},
+		"end_date":         {"结束日期必须晚于开始日期"},
+	}, errs.ByField())
+}
+
+// TestConditionalRequiredRules 测试条件必填规则
+func TestConditionalRequiredRules(t *testing.T) {
+	values := map[string]interface{}{
+		"invoice_type": "company",
+		"landline":     "12345678",
+		"count":        float64(2),
+	}
+	vc := &ValidateContext{Values: values}
+
+	cases := []struct {
+		name  string
+		rule  Checker
+		value interface{}
+		valid bool
+	}{
+		{"RequiredIfTriggered", NewRequiredIf("invoice_type", []interface{}{"company"}, ""), "", false},
+		{"RequiredIfFilled", NewRequiredIf("invoice_type", []interface{}{"company"}, ""), "91110000", true},
+		{"RequiredIfNotTriggered", NewRequiredIf("invoice_type", []interface{}{"personal"}, ""), nil, true},
+		{"RequiredIfNumeric", NewRequiredIf("count", []interface{}{2}, ""), nil, false},
+		{"RequiredUnlessTriggered", NewRequiredUnless("invoice_type", []interface{}{"personal"}, ""), nil, false},
+		{"RequiredUnlessExempt", NewRequiredUnless("invoice_type", []interface{}{"company"}, ""), nil, true},
+		{"RequiredWithTriggered", NewRequiredWith([]string{"mobile", "landline"}, ""), "", false},
+		{"RequiredWithNotTriggered", NewRequiredWith([]string{"mobile"}, ""), "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.Check(tc.value, vc)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	t.Run("ToMapRunsOnEmptyValue", func(t *testing.T) {
+		m := NewRequiredIf("invoice_type", []interface{}{"company"}, "").ToMap()
+		assert.Equal(t, "此项必填", m["message"])
+		validator := m["validator"].(JSFunc)
+		assert.Contains(t, validator, `["company"].some`)
+		assert.NotContains(t, validator, "if (isEmpty(value)) { return callback(); }")
+	})
+}
+
+// TestBuilderConditionalRequired 测试Builder的条件必填方法
+func TestBuilderConditionalRequired(t *testing.T) {
+	input := NewInput("tax_id", "税号").
+		RequiredIf("invoice_type", "company").
+		RequiredUnless("country", "US").
+		RequiredWith("company_name").
+		Validate(NewLength(0, 20, "最多20个字符"))
+
+	data := input.GetData()
+	require.Len(t, data.Validate, 4)
+	assert.Equal(t, RequiredIfRule{Field: "invoice_type", Values: []interface{}{"company"}, Message: "此项必填"}, data.Validate[0])
+	assert.IsType(t, RequiredUnlessRule{}, data.Validate[1])
+	assert.IsType(t, RequiredWithRule{}, data.Validate[2])
+
+	form := NewElmForm("/submit", []Component{
+		NewRadio("invoice_type", "发票类型"),
+		NewInput("country", "国家"),
+		NewInput("company_name", "公司名称"),
+		input,
+	}, nil)
+
+	err := form.ValidateData(map[string]interface{}{"invoice_type": "company", "country": "US"})
+	var errs FieldErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "requiredIf", errs[0].Rule)
+
+	assert.NoError(t, form.ValidateData(map[string]interface{}{"invoice_type": "personal", "country": "US"}))
+}
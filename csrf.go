@@ -0,0 +1,304 @@
+package formbuilder
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// csrf.go 实现表单的跨站请求伪造防护
+// 渲染表单时通过Form.ForRequest为当前请求签发令牌，令牌以隐藏字段随表单提交，FormScript的提交函数同时放入请求头；
+// ParseRequest或CSRFMiddleware在处理提交时校验令牌。
+// 令牌的生成和校验通过CSRFProtector接口完成，内置签名的双重提交Cookie实现CookieCSRF
+
+// CSRFField CSRF令牌字段名
+const CSRFField = "_fb_csrf"
+
+// CSRFHeader CSRF令牌请求头
+const CSRFHeader = "X-CSRF-Token"
+
+// csrfTokenSize 令牌密钥的字节数
+const csrfTokenSize = 32
+
+// MinCSRFKeySize CookieCSRF签名密钥的最小字节数
+const MinCSRFKeySize = 32
+
+// errInvalidCSRF CSRF令牌缺失或无效
+var errInvalidCSRF = errors.New("页面已过期，请刷新后重试")
+
+// errCSRFKey CookieCSRF的签名密钥过短
+var errCSRFKey = errors.New("csrf key must be at least " + strconv.Itoa(MinCSRFKeySize) + " bytes")
+
+// CSRFProtector CSRF令牌的生成和校验接口
+type CSRFProtector interface {
+	// Token 为当前请求签发令牌，需要时通过w写入Cookie等状态
+	Token(w http.ResponseWriter, r *http.Request) (string, error)
+
+	// Verify 校验请求提交的令牌，无效时返回错误
+	Verify(r *http.Request, token string) error
+}
+
+// SetCSRF 开启CSRF防护
+// 渲染时通过ForRequest为当前请求签发令牌；ParseRequest会校验提交的令牌，
+// 校验失败时返回CSRFField的FieldErrors（rule为"csrf"），WriteErrors以403输出
+//
+// 使用示例：
+//
+//	var csrf = formbuilder.NewCookieCSRF([]byte(os.Getenv("CSRF_KEY")))
+//	var userForm = newUserForm().SetCSRF(csrf)
+//
+//	view, err := userForm.ForRequest(w, r)
+//	if err != nil {
+//	    // 处理错误
+//	}
+//	html, _ := view.View()
+func (f *Form) SetCSRF(p CSRFProtector) *Form {
+	f.csrf = p
+	return f
+}
+
+// ForRequest 返回用于渲染当前请求的表单
+// 开启CSRF防护时为当前请求签发令牌，返回的表单渲染时注入CSRFField隐藏字段和CSRFHeader请求头。
+// 令牌只保存在返回的副本中，原表单不会被修改，可以在多个goroutine中共用；
+// 副本与原表单共用组件和数据，只用于渲染，不应再修改
+func (f *Form) ForRequest(w http.ResponseWriter, r *http.Request) (*Form, error) {
+	view := *f
+	if f.csrf != nil {
+		token, err := f.csrf.Token(w, r)
+		if err != nil {
+			return nil, err
+		}
+		view.csrfToken = token
+	}
+	return &view, nil
+}
+
+// verifyCSRF 校验请求中的令牌
+// 令牌取自CSRFHeader请求头，没有时取自提交数据中的CSRFField
+func (f *Form) verifyCSRF(r *http.Request, values map[string]interface{}) error {
+	if f.csrf == nil {
+		return nil
+	}
+	token := r.Header.Get(CSRFHeader)
+	if token == "" {
+		token, _ = values[CSRFField].(string)
+	}
+	if token == "" || f.csrf.Verify(r, token) != nil {
+		return FieldErrors{{Field: CSRFField, Rule: "csrf", Message: errInvalidCSRF.Error()}}
+	}
+	return nil
+}
+
+// CSRFMiddleware 返回校验CSRF令牌的中间件
+// GET、HEAD、OPTIONS和TRACE请求直接放行；其他请求的令牌取自CSRFHeader请求头，
+// 没有时取自urlencoded、multipart或JSON请求体中的CSRFField，校验失败时返回403
+//
+// 使用示例：
+//
+//	mux.Handle("/user/save", formbuilder.CSRFMiddleware(csrf)(saveHandler))
+func CSRFMiddleware(p CSRFProtector) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+				next.ServeHTTP(w, r)
+				return
+			}
+			token := r.Header.Get(CSRFHeader)
+			if token == "" {
+				token = requestCSRFToken(r)
+			}
+			if token == "" || p.Verify(r, token) != nil {
+				WriteErrors(w, FieldErrors{{Field: CSRFField, Rule: "csrf", Message: errInvalidCSRF.Error()}})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestCSRFToken 从请求体读取令牌
+// JSON请求体读取后会还原，后续的ParseRequest仍可以读取
+func requestCSRFToken(r *http.Request) string {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		body, err := io.ReadAll(io.LimitReader(r.Body, DefaultMaxMemory))
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		if err != nil {
+			return ""
+		}
+		var values struct {
+			Token string `json:"_fb_csrf"`
+		}
+		_ = json.Unmarshal(body, &values)
+		return values.Token
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(DefaultMaxMemory); err != nil {
+			return ""
+		}
+		if values := r.MultipartForm.Value[CSRFField]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return r.PostFormValue(CSRFField)
+}
+
+// CookieCSRF 签名的双重提交Cookie
+// Cookie中保存随机密钥及其HMAC签名，令牌为密钥经一次性掩码处理后的值，每次渲染都不相同；
+// 校验时要求Cookie签名有效且令牌还原后与Cookie中的密钥一致。
+//
+// 签名只能保证Cookie由服务端签发：攻击者可以先访问站点取得一对有效的Cookie和令牌，
+// 再通过可控的子域名写入受害者的浏览器（Cookie Tossing）。设置SessionID后签名包含会话标识，
+// 其他会话签发的Cookie不再有效；未设置时需要保证所有子域名可信，或使用 "__Host-" 前缀的CookieName
+type CookieCSRF struct {
+	// Key 签名密钥，至少MinCSRFKeySize字节，过短时Token返回错误、Verify拒绝所有令牌
+	Key []byte
+
+	// SessionID 返回当前请求的会话标识（如登录会话ID），签名时与密钥绑定；
+	// 为nil或返回空字符串时不绑定会话。会话变化（如登录）后Token会签发新的Cookie
+	SessionID func(*http.Request) string
+
+	// CookieName Cookie名称，默认为 "_fb_csrf"
+	CookieName string
+
+	// Path Cookie路径，默认为 "/"
+	Path string
+
+	// Domain Cookie域名
+	Domain string
+
+	// Secure 是否只在HTTPS下发送
+	Secure bool
+
+	// SameSite Cookie的SameSite属性，默认为Lax
+	SameSite http.SameSite
+
+	// MaxAge Cookie有效期（秒），为0时为会话Cookie
+	MaxAge int
+}
+
+// NewCookieCSRF 创建签名的双重提交Cookie防护
+// key至少为MinCSRFKeySize字节，为空或过短（如未设置环境变量）时panic
+func NewCookieCSRF(key []byte) *CookieCSRF {
+	if len(key) < MinCSRFKeySize {
+		panic(errCSRFKey)
+	}
+	return &CookieCSRF{Key: key}
+}
+
+// Token 实现CSRFProtector接口
+// Cookie不存在或签名无效时生成新的密钥并写入Cookie
+func (c *CookieCSRF) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	if len(c.Key) < MinCSRFKeySize {
+		return "", errCSRFKey
+	}
+	secret, ok := c.cookieSecret(r)
+	if !ok {
+		secret = make([]byte, csrfTokenSize)
+		if _, err := rand.Read(secret); err != nil {
+			return "", err
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     c.cookieName(),
+			Value:    base64.RawURLEncoding.EncodeToString(secret) + "." + base64.RawURLEncoding.EncodeToString(c.sign(r, secret)),
+			Path:     withDefault(c.Path, "/"),
+			Domain:   c.Domain,
+			MaxAge:   c.MaxAge,
+			Secure:   c.Secure,
+			HttpOnly: true,
+			SameSite: c.sameSite(),
+		})
+	}
+
+	mask := make([]byte, csrfTokenSize)
+	if _, err := rand.Read(mask); err != nil {
+		return "", err
+	}
+	token := make([]byte, 0, csrfTokenSize*2)
+	token = append(token, mask...)
+	for i := range secret {
+		token = append(token, secret[i]^mask[i])
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// Verify 实现CSRFProtector接口
+func (c *CookieCSRF) Verify(r *http.Request, token string) error {
+	if len(c.Key) < MinCSRFKeySize {
+		return errCSRFKey
+	}
+	secret, ok := c.cookieSecret(r)
+	if !ok {
+		return errInvalidCSRF
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != csrfTokenSize*2 {
+		return errInvalidCSRF
+	}
+	unmasked := make([]byte, csrfTokenSize)
+	for i := range unmasked {
+		unmasked[i] = raw[i] ^ raw[csrfTokenSize+i]
+	}
+	if subtle.ConstantTimeCompare(unmasked, secret) != 1 {
+		return errInvalidCSRF
+	}
+	return nil
+}
+
+// cookieSecret 读取并校验Cookie中的密钥
+func (c *CookieCSRF) cookieSecret(r *http.Request) ([]byte, bool) {
+	cookie, err := r.Cookie(c.cookieName())
+	if err != nil {
+		return nil, false
+	}
+	encoded, mac, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return nil, false
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(secret) != csrfTokenSize {
+		return nil, false
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(sum, c.sign(r, secret)) {
+		return nil, false
+	}
+	return secret, true
+}
+
+// sign 计算密钥的签名，设置了SessionID时包含当前请求的会话标识
+func (c *CookieCSRF) sign(r *http.Request, secret []byte) []byte {
+	data := []byte("csrf:")
+	if c.SessionID != nil {
+		sid := c.SessionID(r)
+		data = append(data, strconv.Itoa(len(sid))...)
+		data = append(data, ':')
+		data = append(data, sid...)
+		data = append(data, ':')
+	}
+	return signHMAC(c.Key, append(data, secret...))
+}
+
+// cookieName 返回Cookie名称
+func (c *CookieCSRF) cookieName() string {
+	return withDefault(c.CookieName, CSRFField)
+}
+
+// sameSite 返回Cookie的SameSite属性
+func (c *CookieCSRF) sameSite() http.SameSite {
+	if c.SameSite == 0 {
+		return http.SameSiteLaxMode
+	}
+	return c.SameSite
+}
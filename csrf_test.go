@@ -0,0 +1,281 @@
+package formbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// csrf_test.go 测试CSRF防护
+
+// testCSRFKey 测试用的签名密钥
+var testCSRFKey = []byte("csrf-key-0123456789abcdefghijklmn")
+
+// issueCSRF 签发令牌，返回令牌和写入的Cookie
+func issueCSRF(t *testing.T, p CSRFProtector, cookies ...*http.Cookie) (string, []*http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	token, err := p.Token(rec, req)
+	require.NoError(t, err)
+	return token, rec.Result().Cookies()
+}
+
+// csrfRequest 创建携带Cookie的提交请求
+func csrfRequest(body io.Reader, contentType string, cookies []*http.Cookie) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/submit", body)
+	req.Header.Set("Content-Type", contentType)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	return req
+}
+
+// TestCookieCSRF 测试签名的双重提交Cookie
+func TestCookieCSRF(t *testing.T) {
+	p := NewCookieCSRF(testCSRFKey)
+
+	t.Run("IssueAndVerify", func(t *testing.T) {
+		token, cookies := issueCSRF(t, p)
+		require.Len(t, cookies, 1)
+		cookie := cookies[0]
+		assert.Equal(t, CSRFField, cookie.Name)
+		assert.True(t, cookie.HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+		assert.Equal(t, "/", cookie.Path)
+
+		req := csrfRequest(nil, "", cookies)
+		assert.NoError(t, p.Verify(req, token))
+	})
+
+	t.Run("PerRequestToken", func(t *testing.T) {
+		first, cookies := issueCSRF(t, p)
+		second, again := issueCSRF(t, p, cookies...)
+		assert.NotEqual(t, first, second, "每次签发的令牌不同")
+		assert.Empty(t, again, "Cookie有效时不重新写入")
+
+		req := csrfRequest(nil, "", cookies)
+		assert.NoError(t, p.Verify(req, first))
+		assert.NoError(t, p.Verify(req, second))
+	})
+
+	t.Run("Rejects", func(t *testing.T) {
+		token, cookies := issueCSRF(t, p)
+		other, otherCookies := issueCSRF(t, p)
+
+		assert.Error(t, p.Verify(csrfRequest(nil, "", nil), token), "缺少Cookie")
+		assert.Error(t, p.Verify(csrfRequest(nil, "", otherCookies), token), "Cookie与令牌不匹配")
+		assert.Error(t, p.Verify(csrfRequest(nil, "", cookies), other))
+		assert.Error(t, p.Verify(csrfRequest(nil, "", cookies), "not-a-token"))
+		assert.Error(t, p.Verify(csrfRequest(nil, "", cookies), token[:len(token)-4]))
+
+		forged := *cookies[0]
+		secret, _, _ := strings.Cut(forged.Value, ".")
+		forged.Value = secret + ".AAAA"
+		assert.Error(t, p.Verify(csrfRequest(nil, "", []*http.Cookie{&forged}), token), "Cookie签名无效")
+
+		_, foreign := issueCSRF(t, NewCookieCSRF([]byte("other-key-0123456789abcdefghijkl")))
+		reissued, replaced := issueCSRF(t, p, foreign...)
+		require.Len(t, replaced, 1, "其他密钥签名的Cookie需要重新签发")
+		assert.NoError(t, p.Verify(csrfRequest(nil, "", replaced), reissued))
+	})
+
+	t.Run("SessionBinding", func(t *testing.T) {
+		p := &CookieCSRF{Key: testCSRFKey, SessionID: func(r *http.Request) string {
+			if c, err := r.Cookie("sid"); err == nil {
+				return c.Value
+			}
+			return ""
+		}}
+		attacker := &http.Cookie{Name: "sid", Value: "attacker"}
+		victim := &http.Cookie{Name: "sid", Value: "victim"}
+
+		token, cookies := issueCSRF(t, p, attacker)
+		assert.NoError(t, p.Verify(csrfRequest(nil, "", append(cookies, attacker)), token))
+		assert.Error(t, p.Verify(csrfRequest(nil, "", append(cookies, victim)), token), "其他会话签发的Cookie无效")
+
+		_, replaced := issueCSRF(t, p, append(cookies, victim)...)
+		assert.Len(t, replaced, 1, "会话变化后重新签发")
+	})
+
+	t.Run("ShortKey", func(t *testing.T) {
+		assert.Panics(t, func() { NewCookieCSRF(nil) })
+		assert.Panics(t, func() { NewCookieCSRF([]byte("short")) })
+
+		p := &CookieCSRF{Key: []byte("short")}
+		_, err := p.Token(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/form", nil))
+		assert.Error(t, err)
+		assert.Error(t, p.Verify(csrfRequest(nil, "", nil), "token"))
+	})
+
+	t.Run("CookieOptions", func(t *testing.T) {
+		p := &CookieCSRF{Key: testCSRFKey, CookieName: "xsrf", Path: "/admin", Secure: true, SameSite: http.SameSiteStrictMode, MaxAge: 3600}
+		_, cookies := issueCSRF(t, p)
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "xsrf", cookies[0].Name)
+		assert.Equal(t, "/admin", cookies[0].Path)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+		assert.Equal(t, 3600, cookies[0].MaxAge)
+	})
+}
+
+// TestFormCSRF 测试表单的CSRF令牌注入和校验
+func TestFormCSRF(t *testing.T) {
+	p := NewCookieCSRF(testCSRFKey)
+	newForm := func() *Form {
+		return NewElmForm("/submit", []Component{NewInput("name", "名称")}, nil).SetCSRF(p)
+	}
+	shared := newForm()
+
+	render := func(t *testing.T) (*Form, string, []*http.Cookie) {
+		rec := httptest.NewRecorder()
+		view, err := shared.ForRequest(rec, httptest.NewRequest(http.MethodGet, "/form", nil))
+		require.NoError(t, err)
+		rules := view.FormRule()
+		last := rules[len(rules)-1]
+		require.Equal(t, CSRFField, last["field"])
+		assert.Equal(t, "hidden", last["type"])
+		return view, last["value"].(string), rec.Result().Cookies()
+	}
+
+	t.Run("Render", func(t *testing.T) {
+		view, token, _ := render(t)
+		script := view.FormScript()
+		assert.Contains(t, script, `init.headers["X-CSRF-Token"] = "`+token+`";`)
+
+		plain := NewElmForm("/submit", []Component{NewInput("name", "名称")}, nil)
+		view, err := plain.ForRequest(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		require.NoError(t, err)
+		assert.Len(t, view.FormRule(), 1)
+		assert.NotContains(t, view.FormScript(), CSRFHeader)
+	})
+
+	t.Run("SharedForm", func(t *testing.T) {
+		// 令牌只保存在副本中，共用的表单可以并发渲染，不会输出其他请求的令牌
+		tokens := make(chan string, 8)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(tokens); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, token, _ := render(t)
+				tokens <- token
+			}()
+		}
+		wg.Wait()
+		close(tokens)
+		seen := make(map[string]bool)
+		for token := range tokens {
+			assert.False(t, seen[token])
+			seen[token] = true
+		}
+		assert.Len(t, shared.FormRule(), 1, "原表单不包含令牌")
+		assert.NotContains(t, shared.FormScript(), CSRFHeader)
+	})
+
+	t.Run("ParseJSONHeader", func(t *testing.T) {
+		_, token, cookies := render(t)
+		req := csrfRequest(strings.NewReader(`{"name":"a"}`), "application/json", cookies)
+		req.Header.Set(CSRFHeader, token)
+		values, err := ParseRequest(newForm(), req, ParseOptions{Strict: true})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"name": "a"}, values)
+	})
+
+	t.Run("ParseFormField", func(t *testing.T) {
+		_, token, cookies := render(t)
+		body := url.Values{CSRFField: {token}, "name": {"a"}}.Encode()
+		req := csrfRequest(strings.NewReader(body), "application/x-www-form-urlencoded", cookies)
+		values, err := ParseRequest(newForm(), req, ParseOptions{Strict: true})
+		require.NoError(t, err)
+		assert.NotContains(t, values, CSRFField, "令牌字段不出现在返回的数据中")
+	})
+
+	t.Run("ParseRejects", func(t *testing.T) {
+		_, token, cookies := render(t)
+		for name, req := range map[string]*http.Request{
+			"NoToken":  csrfRequest(strings.NewReader(`{"name":"a"}`), "application/json", cookies),
+			"NoCookie": csrfRequest(strings.NewReader(`{"name":"a","_fb_csrf":"`+token+`"}`), "application/json", nil),
+		} {
+			values, err := ParseRequest(newForm(), req)
+			assert.Nil(t, values, name)
+			assert.ErrorIs(t, err, ErrCSRF, name)
+			assert.ErrorIs(t, err, &FieldError{Field: CSRFField}, name)
+
+			resp := NewErrorResponse(err)
+			assert.Equal(t, http.StatusForbidden, resp.Code, name)
+			assert.Equal(t, errInvalidCSRF.Error(), resp.Message, name)
+		}
+	})
+}
+
+// TestCSRFMiddleware 测试CSRF中间件
+func TestCSRFMiddleware(t *testing.T) {
+	p := NewCookieCSRF(testCSRFKey)
+	token, cookies := issueCSRF(t, p)
+
+	var received map[string]interface{}
+	handler := CSRFMiddleware(p)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		form := NewElmForm("/submit", []Component{NewInput("name", "名称")}, nil)
+		values, err := ParseRequest(form, r)
+		require.NoError(t, err)
+		received = values
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		received = nil
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("SafeMethod", func(t *testing.T) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/submit?name=a", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		req := csrfRequest(strings.NewReader(`{"name":"a"}`), "application/json", cookies)
+		req.Header.Set(CSRFHeader, token)
+		assert.Equal(t, http.StatusNoContent, serve(req).Code)
+	})
+
+	t.Run("JSONBody", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"name": "a", CSRFField: token})
+		rec := serve(csrfRequest(bytes.NewReader(body), "application/json", cookies))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "a", received["name"], "读取令牌后请求体仍可解析")
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField(CSRFField, token)
+		_ = mw.WriteField("name", "a")
+		_ = mw.Close()
+		rec := serve(csrfRequest(&buf, mw.FormDataContentType(), cookies))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "a", received["name"])
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		body := url.Values{"name": {"a"}}.Encode()
+		rec := serve(csrfRequest(strings.NewReader(body), "application/x-www-form-urlencoded", cookies))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Nil(t, received)
+		assert.Contains(t, rec.Body.String(), `"rule":"csrf"`)
+	})
+}
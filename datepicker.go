@@ -1,5 +1,7 @@
 package formbuilder
 
+import "strings"
+
 // datepicker.go 实现DatePicker日期选择器组件
 
 // DatePicker 日期选择器组件
@@ -90,6 +92,20 @@ func (d *DatePicker) Size(size string) *DatePicker {
 	return d
 }
 
+// Normalize 实现Normalizer接口
+// 按value-format解析为time.Time；范围类型（daterange等）转换为[2]time.Time，dates转换为[]time.Time
+func (d *DatePicker) Normalize(value interface{}) (interface{}, error) {
+	format := propString(d.data.Props, "value-format")
+	dtype := propString(d.data.Props, "type")
+	switch {
+	case strings.HasSuffix(dtype, "range"):
+		return normalizeTimeRange(value, format)
+	case dtype == "dates":
+		return normalizeTimes(value, format)
+	}
+	return normalizeTime(value, format)
+}
+
 // GetField 实现Component接口
 func (d *DatePicker) GetField() string {
 	return d.data.Field
@@ -0,0 +1,1101 @@
+package formbuilder
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// definition.go 实现YAML/JSON表单定义文件的加载
+// 定义文件描述表单的提交地址、配置和字段，字段通过Elm、Iview、Iview4工厂创建，
+// 与在Go代码中调用相同工厂方法得到的表单完全一致。
+// 任意对象都可以通过$ref（或include）引用本文件或其他文件中的片段，
+// 错误信息包含文件名和行列号
+//
+// 定义文件示例：
+//
+//	ui: elm
+//	action: /user/save
+//	config:
+//	  submitBtn: 保存
+//	  resetBtn: false
+//	fields:
+//	  - type: input
+//	    field: name
+//	    title: 姓名
+//	    required: true
+//	    validate:
+//	      - {rule: length, min: 2, max: 20, message: 长度为2-20个字}
+//	  - type: radio
+//	    field: gender
+//	    title: 性别
+//	    value: 1
+//	    options:
+//	      - {value: 1, label: 男}
+//	      - {value: 0, label: 女}
+//	  - $ref: common/contact.yaml#/fields
+
+// DefinitionError 表单定义文件的错误
+type DefinitionError struct {
+	File   string // 文件名
+	Line   int    // 行号，从1开始，未知时为0
+	Column int    // 列号，从1开始，未知时为0
+	Err    error  // 错误原因
+}
+
+// Error 实现error接口
+// 格式为 文件:行:列: 错误原因，YAML语法错误没有列号
+func (e *DefinitionError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+	if e.Column == 0 {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+}
+
+// Unwrap 返回错误原因
+func (e *DefinitionError) Unwrap() error {
+	return e.Err
+}
+
+// definitionFactory 定义文件使用的组件工厂，由ElmFactory和IviewFactory实现
+type definitionFactory interface {
+	Input(field, title string, args ...interface{}) *Input
+	Password(field, title string, value ...interface{}) *Input
+	Textarea(field, title string, value ...interface{}) *Input
+	Select(field, title string, value ...interface{}) *Select
+	Radio(field, title string, value ...interface{}) *Radio
+	Checkbox(field, title string, value ...interface{}) *Checkbox
+	Number(field, title string, value ...interface{}) *InputNumber
+	DatePicker(field, title string, value ...interface{}) *DatePicker
+	TimePicker(field, title string, value ...interface{}) *TimePicker
+	Slider(field, title string, value ...interface{}) *Slider
+	Switch(field, title string, value ...interface{}) *Switch
+	Upload(field, title string, value ...interface{}) *Upload
+	Cascader(field, title string, value ...interface{}) *Cascader
+	Tree(field, title string, value ...interface{}) *Tree
+	Rate(field, title string, value ...interface{}) *Rate
+	ColorPicker(field, title string, value ...interface{}) *ColorPicker
+	Hidden(field string, value ...interface{}) *Hidden
+	Frame(field, title, src string, value ...interface{}) *Frame
+	FrameImage(field, title, src string, value ...interface{}) *Frame
+	FrameImages(field, title, src string, value ...interface{}) *Frame
+	FrameFile(field, title, src string, value ...interface{}) *Frame
+	FrameFiles(field, title, src string, value ...interface{}) *Frame
+	FrameInput(field, title, src string, value ...interface{}) *Frame
+	FrameInputs(field, title, src string, value ...interface{}) *Frame
+	UploadFile(field, title, action string, value ...interface{}) *Upload
+	UploadFiles(field, title, action string, value ...interface{}) *Upload
+	UploadImage(field, title, action string, value ...interface{}) *Upload
+	UploadImages(field, title, action string, value ...interface{}) *Upload
+	CreateForm(action string, args ...interface{}) *Form
+	Config() *Config
+}
+
+// definitionFactories 定义文件中ui对应的工厂
+var definitionFactories = map[string]definitionFactory{
+	"elm":    Elm,
+	"iview":  Iview,
+	"iview4": Iview4,
+}
+
+// fieldSpec 字段定义中传给工厂方法的参数
+type fieldSpec struct {
+	field string
+	title string
+	value []interface{} // 没有value时为空，使用工厂方法的默认值
+	src   string        // Frame系列组件的页面地址
+	url   string        // Upload系列组件的上传地址
+}
+
+// definitionTypes 字段type对应的工厂方法，名称与工厂方法相同（首字母小写）
+var definitionTypes = map[string]func(f definitionFactory, s fieldSpec) Component{
+	"input": func(f definitionFactory, s fieldSpec) Component {
+		return f.Input(s.field, s.title, s.value...)
+	},
+	"password": func(f definitionFactory, s fieldSpec) Component {
+		return f.Password(s.field, s.title, s.value...)
+	},
+	"textarea": func(f definitionFactory, s fieldSpec) Component {
+		return f.Textarea(s.field, s.title, s.value...)
+	},
+	"select": func(f definitionFactory, s fieldSpec) Component {
+		return f.Select(s.field, s.title, s.value...)
+	},
+	"radio": func(f definitionFactory, s fieldSpec) Component {
+		return f.Radio(s.field, s.title, s.value...)
+	},
+	"checkbox": func(f definitionFactory, s fieldSpec) Component {
+		return f.Checkbox(s.field, s.title, s.value...)
+	},
+	"number": func(f definitionFactory, s fieldSpec) Component {
+		return f.Number(s.field, s.title, s.value...)
+	},
+	"datePicker": func(f definitionFactory, s fieldSpec) Component {
+		return f.DatePicker(s.field, s.title, s.value...)
+	},
+	"timePicker": func(f definitionFactory, s fieldSpec) Component {
+		return f.TimePicker(s.field, s.title, s.value...)
+	},
+	"slider": func(f definitionFactory, s fieldSpec) Component {
+		return f.Slider(s.field, s.title, s.value...)
+	},
+	"switch": func(f definitionFactory, s fieldSpec) Component {
+		return f.Switch(s.field, s.title, s.value...)
+	},
+	"upload": func(f definitionFactory, s fieldSpec) Component {
+		return f.Upload(s.field, s.title, s.value...)
+	},
+	"cascader": func(f definitionFactory, s fieldSpec) Component {
+		return f.Cascader(s.field, s.title, s.value...)
+	},
+	"tree": func(f definitionFactory, s fieldSpec) Component {
+		return f.Tree(s.field, s.title, s.value...)
+	},
+	"rate": func(f definitionFactory, s fieldSpec) Component {
+		return f.Rate(s.field, s.title, s.value...)
+	},
+	"colorPicker": func(f definitionFactory, s fieldSpec) Component {
+		return f.ColorPicker(s.field, s.title, s.value...)
+	},
+	"hidden": func(f definitionFactory, s fieldSpec) Component {
+		return f.Hidden(s.field, s.value...)
+	},
+	"frame": func(f definitionFactory, s fieldSpec) Component {
+		return f.Frame(s.field, s.title, s.src, s.value...)
+	},
+	"frameImage": func(f definitionFactory, s fieldSpec) Component {
+		return f.FrameImage(s.field, s.title, s.src, s.value...)
+	},
+	"frameImages": func(f definitionFactory, s fieldSpec) Component {
+		return f.FrameImages(s.field, s.title, s.src, s.value...)
+	},
+	"frameFile": func(f definitionFactory, s fieldSpec) Component {
+		return f.FrameFile(s.field, s.title, s.src, s.value...)
+	},
+	"frameFiles": func(f definitionFactory, s fieldSpec) Component {
+		return f.FrameFiles(s.field, s.title, s.src, s.value...)
+	},
+	"frameInput": func(f definitionFactory, s fieldSpec) Component {
+		return f.FrameInput(s.field, s.title, s.src, s.value...)
+	},
+	"frameInputs": func(f definitionFactory, s fieldSpec) Component {
+		return f.FrameInputs(s.field, s.title, s.src, s.value...)
+	},
+	"uploadFile": func(f definitionFactory, s fieldSpec) Component {
+		return f.UploadFile(s.field, s.title, s.url, s.value...)
+	},
+	"uploadFiles": func(f definitionFactory, s fieldSpec) Component {
+		return f.UploadFiles(s.field, s.title, s.url, s.value...)
+	},
+	"uploadImage": func(f definitionFactory, s fieldSpec) Component {
+		return f.UploadImage(s.field, s.title, s.url, s.value...)
+	},
+	"uploadImages": func(f definitionFactory, s fieldSpec) Component {
+		return f.UploadImages(s.field, s.title, s.url, s.value...)
+	},
+}
+
+// 定义文件中允许的键
+var (
+	formKeys     = keySet("ui", "action", "method", "title", "config", "fields", "definitions")
+	configKeys   = keySet("submitBtn", "resetBtn", "formStyle", "row", "info", "global")
+	fieldKeys    = keySet("type", "field", "title", "value", "src", "action", "props", "required", "validate", "sanitize", "options", "control", "emit", "col", "rule")
+	controlKeys  = keySet("value", "fields")
+	validateKeys = keySet("rule", "message", "trigger", "pattern", "min", "max", "values", "field", "fields", "operator", "id", "url")
+)
+
+// LoadForm 从文件系统加载表单定义文件
+// name为fsys中的文件路径，$ref中的相对路径相对于引用它的文件；
+// .yaml、.yml和.json文件使用相同的解析器，JSON文件同样可以使用$ref
+//
+// 使用示例：
+//
+//	//go:embed forms
+//	var forms embed.FS
+//
+//	form, err := formbuilder.LoadForm(forms, "forms/user.yaml")
+//	if err != nil {
+//	    log.Fatal(err) // forms/user.yaml:12:7: 未知的组件类型textbox
+//	}
+func LoadForm(fsys fs.FS, name string) (*Form, error) {
+	l := newDefinitionLoader(
+		func(name string) ([]byte, error) { return fs.ReadFile(fsys, name) },
+		func(base, ref string) (string, error) {
+			file := path.Join(path.Dir(base), ref)
+			if path.IsAbs(ref) || !fs.ValidPath(file) {
+				return "", errRefOutside
+			}
+			return file, nil
+		},
+	)
+	return l.loadForm(path.Clean(name))
+}
+
+// LoadFormFile 从本地文件加载表单定义文件
+// $ref中的相对路径相对于引用它的文件；引用的文件必须位于name所在的目录内，
+// 绝对路径、超出该目录的".."以及指向目录外的符号链接都会返回错误
+func LoadFormFile(name string) (*Form, error) {
+	name = filepath.Clean(name)
+	dir := filepath.Dir(name)
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, &DefinitionError{File: name, Err: err}
+	}
+	defer root.Close()
+
+	l := newDefinitionLoader(
+		func(file string) ([]byte, error) {
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return nil, err
+			}
+			return root.ReadFile(rel)
+		},
+		func(base, ref string) (string, error) {
+			if filepath.IsAbs(ref) || path.IsAbs(ref) {
+				return "", errRefOutside
+			}
+			file := filepath.Join(filepath.Dir(base), filepath.FromSlash(ref))
+			if rel, err := filepath.Rel(dir, file); err != nil || !filepath.IsLocal(rel) {
+				return "", errRefOutside
+			}
+			return file, nil
+		},
+	)
+	return l.loadForm(name)
+}
+
+// errRefOutside 引用的文件超出允许的目录
+var errRefOutside = errors.New("不能使用绝对路径或引用根目录以外的文件")
+
+// definitionLoader 表单定义文件的加载器
+type definitionLoader struct {
+	read  func(name string) ([]byte, error)
+	join  func(base, ref string) (string, error) // 解析相对于base的引用路径，超出允许的目录时返回错误
+	docs  map[string]*yaml.Node                  // 已解析的文件
+	files map[*yaml.Node]string                  // 节点所在的文件
+	stack []string                               // 正在展开的引用，用于检测循环引用
+}
+
+// newDefinitionLoader 创建加载器
+func newDefinitionLoader(read func(string) ([]byte, error), join func(base, ref string) (string, error)) *definitionLoader {
+	return &definitionLoader{
+		read:  read,
+		join:  join,
+		docs:  make(map[string]*yaml.Node),
+		files: make(map[*yaml.Node]string),
+	}
+}
+
+// loadForm 加载并构建表单
+func (l *definitionLoader) loadForm(name string) (*Form, error) {
+	doc, err := l.document(name)
+	if err != nil {
+		return nil, err
+	}
+	root, err := l.expand(doc)
+	if err != nil {
+		return nil, err
+	}
+	return l.buildForm(root)
+}
+
+// document 读取并解析文件，返回文档的根节点
+func (l *definitionLoader) document(name string) (*yaml.Node, error) {
+	if doc, ok := l.docs[name]; ok {
+		return doc, nil
+	}
+	data, err := l.read(name)
+	if err != nil {
+		return nil, &DefinitionError{File: name, Err: err}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(name, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, &DefinitionError{File: name, Err: errors.New("文件内容为空")}
+	}
+	root := doc.Content[0]
+	l.track(root, name)
+	l.docs[name] = root
+	return root, nil
+}
+
+// track 记录节点所在的文件
+func (l *definitionLoader) track(n *yaml.Node, file string) {
+	l.files[n] = file
+	for _, c := range n.Content {
+		l.track(c, file)
+	}
+}
+
+// errorf 返回指向节点位置的错误
+func (l *definitionLoader) errorf(n *yaml.Node, format string, args ...interface{}) error {
+	return &DefinitionError{File: l.files[n], Line: n.Line, Column: n.Column, Err: fmt.Errorf(format, args...)}
+}
+
+// yamlError 将YAML解析错误转换为DefinitionError
+// yaml.v3的错误格式为 "yaml: line N: 原因"
+func yamlError(file string, err error) error {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	if rest, ok := strings.CutPrefix(msg, "line "); ok {
+		if num, reason, ok := strings.Cut(rest, ": "); ok {
+			if line, err := strconv.Atoi(num); err == nil {
+				return &DefinitionError{File: file, Line: line, Err: errors.New(reason)}
+			}
+		}
+	}
+	return &DefinitionError{File: file, Err: errors.New(msg)}
+}
+
+// expand 展开节点中的$ref和include，返回新的节点树
+// 引用对象时，与$ref同级的键覆盖被引用对象中的同名键；
+// 数组中引用数组时，被引用数组的元素插入到当前位置
+func (l *definitionLoader) expand(n *yaml.Node) (*yaml.Node, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return l.expand(n.Alias)
+	case yaml.MappingNode:
+		if ref := refValue(n); ref != nil {
+			return l.expandRef(n, ref)
+		}
+		out := l.copyNode(n)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			value, err := l.expand(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			out.Content = append(out.Content, n.Content[i], value)
+		}
+		return out, nil
+	case yaml.SequenceNode:
+		out := l.copyNode(n)
+		for _, item := range n.Content {
+			value, err := l.expand(item)
+			if err != nil {
+				return nil, err
+			}
+			if value.Kind == yaml.SequenceNode && item.Kind == yaml.MappingNode && refValue(item) != nil {
+				out.Content = append(out.Content, value.Content...)
+				continue
+			}
+			out.Content = append(out.Content, value)
+		}
+		return out, nil
+	}
+	return n, nil
+}
+
+// expandRef 展开引用
+func (l *definitionLoader) expandRef(n *yaml.Node, ref *yaml.Node) (*yaml.Node, error) {
+	if ref.Kind != yaml.ScalarNode || ref.Value == "" {
+		return nil, l.errorf(ref, "引用必须是非空字符串")
+	}
+	file, pointer, _ := strings.Cut(ref.Value, "#")
+	if file == "" {
+		file = l.files[n]
+	} else {
+		joined, err := l.join(l.files[n], file)
+		if err != nil {
+			return nil, l.errorf(ref, "无法引用%s: %v", ref.Value, err)
+		}
+		file = joined
+	}
+
+	key := file + "#" + pointer
+	for _, active := range l.stack {
+		if active == key {
+			return nil, l.errorf(ref, "循环引用%s", ref.Value)
+		}
+	}
+	l.stack = append(l.stack, key)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	doc, err := l.document(file)
+	if err != nil {
+		var de *DefinitionError
+		if errors.As(err, &de) && de.Line == 0 {
+			return nil, l.errorf(ref, "无法读取引用的文件%s: %v", file, de.Err)
+		}
+		return nil, err
+	}
+	target, err := l.resolvePointer(doc, pointer, ref)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := l.expand(target)
+	if err != nil {
+		return nil, err
+	}
+
+	// 合并与$ref同级的键
+	var siblings []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i].Value; k != "$ref" && k != "include" {
+			siblings = append(siblings, n.Content[i], n.Content[i+1])
+		}
+	}
+	if len(siblings) == 0 {
+		return expanded, nil
+	}
+	if expanded.Kind != yaml.MappingNode {
+		return nil, l.errorf(ref, "只有引用对象时才能覆盖其中的键")
+	}
+	out := l.copyNode(n)
+	overridden := make(map[string]bool)
+	for i := 0; i < len(siblings); i += 2 {
+		value, err := l.expand(siblings[i+1])
+		if err != nil {
+			return nil, err
+		}
+		out.Content = append(out.Content, siblings[i], value)
+		overridden[siblings[i].Value] = true
+	}
+	for i := 0; i+1 < len(expanded.Content); i += 2 {
+		if !overridden[expanded.Content[i].Value] {
+			out.Content = append(out.Content, expanded.Content[i], expanded.Content[i+1])
+		}
+	}
+	return out, nil
+}
+
+// resolvePointer 按JSON Pointer（如 "/definitions/phone"）查找节点，pointer为空时返回根节点
+func (l *definitionLoader) resolvePointer(doc *yaml.Node, pointer string, ref *yaml.Node) (*yaml.Node, error) {
+	n := doc
+	if pointer == "" || pointer == "/" {
+		return n, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, l.errorf(ref, "引用路径必须以/开头: %s", pointer)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		for n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+		var next *yaml.Node
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == token {
+					next = n.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(n.Content) {
+				next = n.Content[i]
+			}
+		}
+		if next == nil {
+			return nil, l.errorf(ref, "引用的节点不存在: %s", ref.Value)
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// copyNode 复制容器节点，不包含子节点
+func (l *definitionLoader) copyNode(n *yaml.Node) *yaml.Node {
+	out := *n
+	out.Content = nil
+	l.files[&out] = l.files[n]
+	return &out
+}
+
+// refValue 返回对象中$ref或include的值，不是引用时返回nil
+func refValue(n *yaml.Node) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i].Value; k == "$ref" || k == "include" {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// keySet 创建键集合
+func keySet(keys ...string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return set
+}
+
+// mappingEntries 将对象节点转换为键到值节点的映射，检查未知的键和重复的键
+func (l *definitionLoader) mappingEntries(n *yaml.Node, allowed map[string]bool, what string) (map[string]*yaml.Node, error) {
+	if n.Kind != yaml.MappingNode {
+		return nil, l.errorf(n, "%s必须是对象", what)
+	}
+	entries := make(map[string]*yaml.Node, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if !allowed[key.Value] {
+			return nil, l.errorf(key, "%s不支持%s", what, key.Value)
+		}
+		if _, ok := entries[key.Value]; ok {
+			return nil, l.errorf(key, "%s重复", key.Value)
+		}
+		entries[key.Value] = n.Content[i+1]
+	}
+	return entries, nil
+}
+
+// decodeValue 解码节点为Go值
+func (l *definitionLoader) decodeValue(n *yaml.Node, v interface{}) error {
+	if err := n.Decode(v); err != nil {
+		var te *yaml.TypeError
+		if errors.As(err, &te) && len(te.Errors) > 0 {
+			return l.errorf(n, "%s", strings.TrimPrefix(te.Errors[0], "line "+strconv.Itoa(n.Line)+": "))
+		}
+		return l.errorf(n, "%v", err)
+	}
+	return nil
+}
+
+// decodeString 解码字符串
+func (l *definitionLoader) decodeString(n *yaml.Node, what string) (string, error) {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+		return "", l.errorf(n, "%s必须是字符串", what)
+	}
+	return n.Value, nil
+}
+
+// decodeMap 解码对象
+func (l *definitionLoader) decodeMap(n *yaml.Node, what string) (map[string]interface{}, error) {
+	if n.Kind != yaml.MappingNode {
+		return nil, l.errorf(n, "%s必须是对象", what)
+	}
+	var m map[string]interface{}
+	if err := l.decodeValue(n, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// buildForm 构建表单
+func (l *definitionLoader) buildForm(root *yaml.Node) (*Form, error) {
+	entries, err := l.mappingEntries(root, formKeys, "表单定义")
+	if err != nil {
+		return nil, err
+	}
+
+	factory := definitionFactory(Elm)
+	if n, ok := entries["ui"]; ok {
+		ui, err := l.decodeString(n, "ui")
+		if err != nil {
+			return nil, err
+		}
+		if factory, ok = definitionFactories[ui]; !ok {
+			return nil, l.errorf(n, "未知的ui%s，可选值为elm、iview、iview4", ui)
+		}
+	}
+
+	var action, method, title string
+	for key, dst := range map[string]*string{"action": &action, "method": &method, "title": &title} {
+		if n, ok := entries[key]; ok {
+			if *dst, err = l.decodeString(n, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	config := factory.Config()
+	if n, ok := entries["config"]; ok {
+		if err := l.applyConfig(config, n); err != nil {
+			return nil, err
+		}
+	}
+
+	var rules []Component
+	if n, ok := entries["fields"]; ok {
+		if rules, err = l.buildFields(factory, n, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+	}
+
+	form := factory.CreateForm(action, rules, config)
+	if method != "" {
+		form.SetMethod(method)
+	}
+	if title != "" {
+		form.SetTitle(title)
+	}
+	return form, nil
+}
+
+// applyConfig 将config写入Config
+// submitBtn、resetBtn可以是布尔值（是否显示）、字符串（按钮文本）或按钮属性对象
+func (l *definitionLoader) applyConfig(c *Config, n *yaml.Node) error {
+	entries, err := l.mappingEntries(n, configKeys, "config")
+	if err != nil {
+		return err
+	}
+	buttons := []struct {
+		key   string
+		show  func(bool, ...string) *Config
+		props func(map[string]interface{}) *Config
+	}{
+		{"submitBtn", c.SubmitBtn, c.SetSubmitBtnProps},
+		{"resetBtn", c.ResetBtn, c.SetResetBtnProps},
+	}
+	for _, btn := range buttons {
+		v, ok := entries[btn.key]
+		if !ok {
+			continue
+		}
+		switch {
+		case v.Kind == yaml.ScalarNode && v.Tag == "!!bool":
+			btn.show(v.Value == "true")
+		case v.Kind == yaml.ScalarNode && v.Tag == "!!str":
+			btn.show(true, v.Value)
+		case v.Kind == yaml.MappingNode:
+			props, err := l.decodeMap(v, btn.key)
+			if err != nil {
+				return err
+			}
+			btn.props(props)
+		default:
+			return l.errorf(v, "%s必须是布尔值、字符串或对象", btn.key)
+		}
+	}
+
+	for key, set := range map[string]func(map[string]interface{}) *Config{
+		"formStyle": c.FormStyle,
+		"row":       c.Row,
+		"info":      func(m map[string]interface{}) *Config { c.info = m; return c },
+		"global":    c.SetGlobal,
+	} {
+		if v, ok := entries[key]; ok {
+			m, err := l.decodeMap(v, key)
+			if err != nil {
+				return err
+			}
+			set(m)
+		}
+	}
+	return nil
+}
+
+// buildFields 构建字段数组，seen用于检查字段名重复（包括control分支中的字段）
+func (l *definitionLoader) buildFields(factory definitionFactory, n *yaml.Node, seen map[string]bool) ([]Component, error) {
+	if n.Kind != yaml.SequenceNode {
+		return nil, l.errorf(n, "fields必须是数组")
+	}
+	components := make([]Component, 0, len(n.Content))
+	for _, item := range n.Content {
+		c, err := l.buildField(factory, item, seen)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// buildField 构建单个字段
+// 先调用type对应的工厂方法，再按props、required、validate、sanitize、options、control、emit、col、rule的顺序设置
+func (l *definitionLoader) buildField(factory definitionFactory, n *yaml.Node, seen map[string]bool) (Component, error) {
+	entries, err := l.mappingEntries(n, fieldKeys, "字段")
+	if err != nil {
+		return nil, err
+	}
+	typeNode, ok := entries["type"]
+	if !ok {
+		return nil, l.errorf(n, "缺少字段类型type")
+	}
+	ruleType, err := l.decodeString(typeNode, "type")
+	if err != nil {
+		return nil, err
+	}
+	create, ok := definitionTypes[ruleType]
+	if !ok {
+		return nil, l.errorf(typeNode, "未知的组件类型%s", ruleType)
+	}
+
+	var spec fieldSpec
+	fieldNode, ok := entries["field"]
+	if !ok {
+		return nil, l.errorf(n, "缺少字段名field")
+	}
+	if spec.field, err = l.decodeString(fieldNode, "field"); err != nil {
+		return nil, err
+	}
+	if seen[spec.field] {
+		return nil, l.errorf(fieldNode, "字段%s重复", spec.field)
+	}
+	seen[spec.field] = true
+	if v, ok := entries["title"]; ok {
+		if spec.title, err = l.decodeString(v, "title"); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := entries["value"]; ok {
+		var value interface{}
+		if err := l.decodeValue(v, &value); err != nil {
+			return nil, err
+		}
+		spec.value = []interface{}{value}
+	}
+	if v, ok := entries["src"]; ok {
+		if spec.src, err = l.decodeString(v, "src"); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := entries["action"]; ok {
+		if spec.url, err = l.decodeString(v, "action"); err != nil {
+			return nil, err
+		}
+	}
+	if strings.HasPrefix(ruleType, "frame") && spec.src == "" {
+		return nil, l.errorf(n, "%s组件缺少页面地址src", ruleType)
+	}
+
+	c := create(factory, spec)
+	data := c.(interface{ GetData() *ComponentData }).GetData()
+
+	if v, ok := entries["props"]; ok {
+		props, err := l.decodeMap(v, "props")
+		if err != nil {
+			return nil, err
+		}
+		for k, val := range props {
+			data.Props[k] = val
+		}
+	}
+	if v, ok := entries["required"]; ok {
+		switch {
+		case v.Kind == yaml.ScalarNode && v.Tag == "!!bool":
+			if v.Value == "true" {
+				data.Validate = append(data.Validate, NewRequired())
+			}
+		case v.Kind == yaml.ScalarNode && v.Tag == "!!str":
+			data.Validate = append(data.Validate, NewRequired(v.Value))
+		default:
+			return nil, l.errorf(v, "required必须是布尔值或提示信息")
+		}
+	}
+	if v, ok := entries["validate"]; ok {
+		if v.Kind != yaml.SequenceNode {
+			return nil, l.errorf(v, "validate必须是数组")
+		}
+		for _, item := range v.Content {
+			rule, err := l.buildValidate(item, c.GetType())
+			if err != nil {
+				return nil, err
+			}
+			data.Validate = append(data.Validate, rule)
+		}
+	}
+	if v, ok := entries["sanitize"]; ok {
+		sanitizers, err := l.buildSanitizers(v)
+		if err != nil {
+			return nil, err
+		}
+		data.Sanitizers = sanitizers
+	}
+	if v, ok := entries["options"]; ok {
+		if err := l.applyOptions(c, v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := entries["control"]; ok {
+		controls, err := l.buildControls(factory, v, seen)
+		if err != nil {
+			return nil, err
+		}
+		data.Control = controls
+	}
+	if v, ok := entries["emit"]; ok {
+		if data.Emit, err = l.decodeMap(v, "emit"); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := entries["col"]; ok {
+		var col interface{}
+		if err := l.decodeValue(v, &col); err != nil {
+			return nil, err
+		}
+		if data.AppendRule == nil {
+			data.AppendRule = make(map[string]interface{})
+		}
+		if span, ok := col.(int); ok {
+			col = map[string]interface{}{"span": span}
+		}
+		data.AppendRule["col"] = col
+	}
+	if v, ok := entries["rule"]; ok {
+		extra, err := l.decodeMap(v, "rule")
+		if err != nil {
+			return nil, err
+		}
+		if data.AppendRule == nil {
+			data.AppendRule = make(map[string]interface{})
+		}
+		for k, val := range extra {
+			data.AppendRule[k] = val
+		}
+	}
+	return c, nil
+}
+
+// buildSanitizers 按名称构建内置清理器，空数组表示清除组件的默认清理器
+func (l *definitionLoader) buildSanitizers(n *yaml.Node) ([]Sanitizer, error) {
+	if n.Kind != yaml.SequenceNode {
+		return nil, l.errorf(n, "sanitize必须是数组")
+	}
+	sanitizers := []Sanitizer{}
+	for _, item := range n.Content {
+		name, err := l.decodeString(item, "清理器")
+		if err != nil {
+			return nil, err
+		}
+		s, ok := builtinSanitizers[name]
+		if !ok {
+			return nil, l.errorf(item, "未知的清理器%s", name)
+		}
+		sanitizers = append(sanitizers, s)
+	}
+	return sanitizers, nil
+}
+
+// applyOptions 设置Select、Radio、Checkbox、Cascader的选项
+// 选项为包含value、label的对象，也可以是标量（value和label相同）
+func (l *definitionLoader) applyOptions(c Component, n *yaml.Node) error {
+	if n.Kind != yaml.SequenceNode {
+		return l.errorf(n, "options必须是数组")
+	}
+	options := make([]Option, 0, len(n.Content))
+	for _, item := range n.Content {
+		var v interface{}
+		if err := l.decodeValue(item, &v); err != nil {
+			return err
+		}
+		switch opt := v.(type) {
+		case map[string]interface{}:
+			if _, ok := opt["value"]; !ok {
+				return l.errorf(item, "选项缺少value")
+			}
+			options = append(options, optionFromMap(opt))
+		case []interface{}, nil:
+			return l.errorf(item, "选项必须是对象或标量")
+		default:
+			options = append(options, Option{Value: opt, Label: fmt.Sprint(opt)})
+		}
+	}
+
+	switch c := c.(type) {
+	case *Select:
+		c.SetOptions(options)
+	case *Radio:
+		c.SetOptions(options)
+	case *Checkbox:
+		c.SetOptions(options)
+	case *Cascader:
+		c.SetOptions(options)
+	default:
+		return l.errorf(n, "组件类型%s不支持options", c.GetType())
+	}
+	return nil
+}
+
+// buildControls 构建control分支
+func (l *definitionLoader) buildControls(factory definitionFactory, n *yaml.Node, seen map[string]bool) ([]ControlRule, error) {
+	if n.Kind != yaml.SequenceNode {
+		return nil, l.errorf(n, "control必须是数组")
+	}
+	controls := make([]ControlRule, 0, len(n.Content))
+	for _, item := range n.Content {
+		entries, err := l.mappingEntries(item, controlKeys, "control")
+		if err != nil {
+			return nil, err
+		}
+		var ctrl ControlRule
+		if v, ok := entries["value"]; ok {
+			if err := l.decodeValue(v, &ctrl.Value); err != nil {
+				return nil, err
+			}
+		}
+		if v, ok := entries["fields"]; ok {
+			if ctrl.Rule, err = l.buildFields(factory, v, seen); err != nil {
+				return nil, err
+			}
+		}
+		controls = append(controls, ctrl)
+	}
+	return controls, nil
+}
+
+// validateSpec 验证规则定义
+type validateSpec struct {
+	Rule     string        `yaml:"rule"`
+	Message  string        `yaml:"message"`
+	Trigger  string        `yaml:"trigger"`
+	Pattern  string        `yaml:"pattern"`
+	Min      *float64      `yaml:"min"`
+	Max      *float64      `yaml:"max"`
+	Values   []interface{} `yaml:"values"`
+	Field    string        `yaml:"field"`
+	Fields   []string      `yaml:"fields"`
+	Operator string        `yaml:"operator"`
+	ID       string        `yaml:"id"`
+	URL      string        `yaml:"url"`
+}
+
+// messages 返回传给默认提示信息构造函数的参数
+func (s validateSpec) messages() []string {
+	if s.Message == "" {
+		return nil
+	}
+	return []string{s.Message}
+}
+
+// buildValidate 构建验证规则
+// 包含rule时按名称创建内置规则，名称与构造函数对应（如 length 对应 NewLength），
+// 数字类组件（ruleType为inputNumber、slider、rate）的length、min、max按数值范围创建RangeRule；
+// 不包含rule时按form-create的格式还原，与ParseRules相同
+func (l *definitionLoader) buildValidate(n *yaml.Node, ruleType string) (ValidateRule, error) {
+	if n.Kind != yaml.MappingNode {
+		return nil, l.errorf(n, "验证规则必须是对象")
+	}
+	if !hasKey(n, "rule") {
+		m, err := l.decodeMap(n, "验证规则")
+		if err != nil {
+			return nil, err
+		}
+		var normalized map[string]interface{}
+		if err := remarshalJSON(m, &normalized); err != nil {
+			return nil, l.errorf(n, "%v", err)
+		}
+		rule, err := parseValidateRule(normalized, ParseRulesOptions{})
+		if err != nil {
+			return nil, l.errorf(n, "%v", err)
+		}
+		return rule, nil
+	}
+
+	if _, err := l.mappingEntries(n, validateKeys, "验证规则"); err != nil {
+		return nil, err
+	}
+	var s validateSpec
+	if err := l.decodeValue(n, &s); err != nil {
+		return nil, err
+	}
+	var minValue, maxValue float64
+	if s.Min != nil {
+		minValue = *s.Min
+	}
+	if s.Max != nil {
+		maxValue = *s.Max
+	}
+
+	var rule ValidateRule
+	switch s.Rule {
+	case "required":
+		r := NewRequired(s.messages()...)
+		r.Trigger = s.Trigger
+		rule = r
+	case "pattern":
+		rule = PatternRule{Pattern: s.Pattern, Message: s.Message, Trigger: s.Trigger}
+	case "length", "min", "max":
+		if !numberRuleTypes[ruleType] {
+			rule = LengthRule{Min: int(minValue), Max: int(maxValue), Message: s.Message, Trigger: s.Trigger}
+			break
+		}
+		fallthrough
+	case "range":
+		rule = RangeRule{
+			Min: minValue, Max: maxValue, Message: s.Message, Trigger: s.Trigger,
+			MinSet: s.Min != nil && minValue == 0, MaxSet: s.Max != nil && maxValue == 0,
+		}
+	case "email":
+		r := NewEmail(s.messages()...)
+		r.Trigger = s.Trigger
+		rule = r
+	case "url":
+		r := NewURL(s.messages()...)
+		r.Trigger = s.Trigger
+		rule = r
+	case "date":
+		rule = DateRule{Message: s.Message, Trigger: s.Trigger}
+	case "enum":
+		rule = EnumRule{Enum: s.Values, Message: s.Message, Trigger: s.Trigger}
+	case "whitespace":
+		rule = WhitespaceRule{Whitespace: true, Message: s.Message, Trigger: s.Trigger}
+	case "equalTo":
+		rule = EqualToFieldRule{Field: s.Field, Message: s.Message, Trigger: s.Trigger}
+	case "compare":
+		rule = CompareFieldRule{Field: s.Field, Operator: s.Operator, Message: s.Message, Trigger: s.Trigger}
+	case "dateAfter":
+		rule = DateOrderRule{Field: s.Field, Operator: CompareGT, Message: s.Message, Trigger: s.Trigger}
+	case "dateBefore":
+		rule = DateOrderRule{Field: s.Field, Operator: CompareLT, Message: s.Message, Trigger: s.Trigger}
+	case "requiredIf":
+		rule = RequiredIfRule{Field: s.Field, Values: s.Values, Message: s.Message, Trigger: s.Trigger}
+	case "requiredUnless":
+		rule = RequiredUnlessRule{Field: s.Field, Values: s.Values, Message: s.Message, Trigger: s.Trigger}
+	case "requiredWith":
+		rule = RequiredWithRule{Fields: s.Fields, Message: s.Message, Trigger: s.Trigger}
+	case "remote":
+		rule = RemoteRule{ID: s.ID, URL: s.URL, Message: s.Message, Trigger: s.Trigger}
+	default:
+		names := make([]string, 0, len(validateRuleNames))
+		for name := range validateRuleNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, l.errorf(n, "未知的验证规则%s，可选值为%s", s.Rule, strings.Join(names, "、"))
+	}
+
+	for _, key := range validateRuleNames[s.Rule] {
+		if !hasKey(n, key) {
+			return nil, l.errorf(n, "验证规则%s缺少%s", s.Rule, key)
+		}
+	}
+	return rule, nil
+}
+
+// validateRuleNames 内置验证规则的名称及必须的参数
+var validateRuleNames = map[string][]string{
+	"required":       nil,
+	"pattern":        {"pattern"},
+	"length":         {"min", "max"},
+	"min":            {"min"},
+	"max":            {"max"},
+	"range":          {"min", "max"},
+	"email":          nil,
+	"url":            nil,
+	"date":           nil,
+	"enum":           {"values"},
+	"whitespace":     nil,
+	"equalTo":        {"field"},
+	"compare":        {"field", "operator"},
+	"dateAfter":      {"field"},
+	"dateBefore":     {"field"},
+	"requiredIf":     {"field", "values"},
+	"requiredUnless": {"field", "values"},
+	"requiredWith":   {"fields"},
+	"remote":         {"id"},
+}
+
+// hasKey 判断对象节点是否包含键
+func hasKey(n *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
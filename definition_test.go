@@ -0,0 +1,268 @@
+package formbuilder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// definition_test.go 测试YAML/JSON表单定义文件的加载
+
+// definitionFS 测试用的定义文件
+var definitionFS = fstest.MapFS{
+	"forms/user.yaml": {Data: []byte(`ui: elm
+action: /user/save
+method: PUT
+title: 用户
+config:
+  submitBtn: 保存
+  resetBtn: false
+  formStyle: {labelWidth: 100px}
+fields:
+  - type: input
+    field: name
+    title: 姓名
+    props: {placeholder: 请输入姓名}
+    required: true
+    validate:
+      - {rule: length, min: 2, max: 20, message: 长度为2-20个字}
+    sanitize: [trim]
+  - type: password
+    field: password
+    title: 密码
+    validate:
+      - {rule: min, min: 6, trigger: blur}
+  - type: password
+    field: confirm
+    title: 确认密码
+    validate:
+      - {rule: equalTo, field: password, message: 两次密码不一致}
+  - type: radio
+    field: gender
+    title: 性别
+    value: 1
+    options:
+      - {value: 1, label: 男}
+      - {value: 0, label: 女}
+    control:
+      - value: 1
+        fields:
+          - {type: input, field: beard, title: 胡子, validate: [{rule: requiredIf, field: gender, values: [1]}]}
+  - type: number
+    field: age
+    title: 年龄
+    col: 12
+    validate:
+      - {rule: range, min: 1, max: 120}
+  - $ref: ../common/contact.yaml#/fields
+  - $ref: '#/definitions/city'
+    title: 所在城市
+definitions:
+  city:
+    type: select
+    field: city
+    title: 城市
+    options: [北京, 上海]
+`)},
+	"common/contact.yaml": {Data: []byte(`fields:
+  - type: input
+    field: email
+    title: 邮箱
+    validate:
+      - {rule: email}
+  - include: phone.json
+`)},
+	"common/phone.json": {Data: []byte(`{
+  "type": "input",
+  "field": "phone",
+  "title": "手机号",
+  "validate": [{"pattern": "^1\\d{10}$", "message": "手机号格式错误"}]
+}`)},
+}
+
+// codeUserForm 与forms/user.yaml相同的代码构建的表单
+func codeUserForm() *Form {
+	config := Elm.Config().SubmitBtn(true, "保存").ResetBtn(false).
+		FormStyle(map[string]interface{}{"labelWidth": "100px"})
+	return Elm.CreateForm("/user/save", []Component{
+		Elm.Input("name", "姓名").Props("placeholder", "请输入姓名").Required().
+			Validate(NewLength(2, 20, "长度为2-20个字")).Sanitize(Trim),
+		Elm.Password("password", "密码").Validate(LengthRule{Min: 6, Trigger: "blur"}),
+		Elm.Password("confirm", "确认密码").Validate(NewEqualTo("password", "两次密码不一致")),
+		Elm.Radio("gender", "性别", 1).SetOptions([]Option{
+			Elm.Option(1, "男"), Elm.Option(0, "女"),
+		}).Control([]ControlRule{{Value: 1, Rule: []Component{
+			Elm.Input("beard", "胡子").Validate(NewRequiredIf("gender", []interface{}{1}, "")),
+		}}}),
+		Elm.Number("age", "年龄").Col(12).Validate(NewRange(1, 120, "")),
+		Elm.Input("email", "邮箱").Validate(NewEmail()),
+		Elm.Input("phone", "手机号").Validate(NewPattern(`^1\d{10}$`, "手机号格式错误")),
+		Elm.Select("city", "所在城市").SetOptions([]Option{
+			Elm.Option("北京", "北京"), Elm.Option("上海", "上海"),
+		}),
+	}, config).SetMethod("PUT").SetTitle("用户")
+}
+
+// TestLoadForm 测试定义文件构建的表单与代码构建的表单一致
+func TestLoadForm(t *testing.T) {
+	form, err := LoadForm(definitionFS, "forms/user.yaml")
+	require.NoError(t, err)
+	expected := codeUserForm()
+
+	t.Run("Rules", func(t *testing.T) {
+		got, err := form.ParseFormRule()
+		require.NoError(t, err)
+		want, err := expected.ParseFormRule()
+		require.NoError(t, err)
+		assert.JSONEq(t, want, got)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		assert.Equal(t, expected.FormConfig(), form.FormConfig())
+		assert.Equal(t, "PUT", form.GetMethod())
+		assert.Equal(t, "用户", form.getTitle())
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		for _, values := range []map[string]interface{}{
+			{},
+			{"name": " 张 ", "password": "123", "confirm": "456", "gender": 1, "age": 200, "email": "bad", "phone": "123"},
+			{"name": "张三", "password": "123456", "confirm": "123456", "gender": 1, "beard": "有", "age": 20,
+				"email": "a@example.com", "phone": "13800000000", "city": "北京"},
+		} {
+			assert.Equal(t, expected.ValidateData(values), form.ValidateData(values))
+		}
+	})
+
+	t.Run("Iview", func(t *testing.T) {
+		fsys := fstest.MapFS{"form.yml": {Data: []byte(`ui: iview4
+action: /save
+fields:
+  - {type: datePicker, field: day, title: 日期}
+  - {type: uploadImage, field: avatar, title: 头像, action: /upload}
+`)}}
+		form, err := LoadForm(fsys, "form.yml")
+		require.NoError(t, err)
+		expected := Iview4.CreateForm("/save", []Component{
+			Iview4.DatePicker("day", "日期"),
+			Iview4.UploadImage("avatar", "头像", "/upload"),
+		})
+		got, _ := form.ParseFormRule()
+		want, _ := expected.ParseFormRule()
+		assert.JSONEq(t, want, got)
+		assert.Equal(t, expected.GetUI(), form.GetUI())
+	})
+
+	t.Run("NumberBounds", func(t *testing.T) {
+		fsys := fstest.MapFS{"form.yml": {Data: []byte(`fields:
+  - type: number
+    field: qty
+    validate:
+      - {rule: min, min: 0, message: 不能为负数}
+      - {rule: max, max: 99}
+  - type: input
+    field: code
+    validate:
+      - {rule: max, max: 6}
+`)}}
+		form, err := LoadForm(fsys, "form.yml")
+		require.NoError(t, err)
+		rules := form.GetRules()
+		assert.Equal(t, []ValidateRule{
+			RangeRule{MinSet: true, Message: "不能为负数"},
+			RangeRule{Max: 99},
+		}, rules[0].(*InputNumber).data.Validate, "数字类组件的min、max按数值范围验证")
+		assert.Equal(t, []ValidateRule{LengthRule{Max: 6}}, rules[1].(*Input).data.Validate)
+
+		assert.ErrorIs(t, form.ValidateData(map[string]interface{}{"qty": -1}), &FieldError{Field: "qty", Rule: "range"})
+		assert.ErrorIs(t, form.ValidateData(map[string]interface{}{"qty": 100}), &FieldError{Field: "qty", Rule: "range"})
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"qty": 0, "code": "123456"}))
+	})
+}
+
+// TestLoadFormErrors 测试错误信息中的文件和行号
+func TestLoadFormErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		err   string
+	}{
+		{"UnknownType", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - type: textbox\n    field: a\n")}},
+			"f.yaml:2:11: 未知的组件类型textbox"},
+		{"UnknownKey", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - type: input\n    field: a\n    placeholder: x\n")}},
+			"f.yaml:4:5: 字段不支持placeholder"},
+		{"MissingField", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - type: input\n")}},
+			"f.yaml:2:5: 缺少字段名field"},
+		{"DuplicateField", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - {type: input, field: a}\n  - {type: input, field: a}\n")}},
+			"f.yaml:3:26: 字段a重复"},
+		{"ValidateParam", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - type: input\n    field: a\n    validate:\n      - {rule: length, min: 1}\n")}},
+			"f.yaml:5:9: 验证规则length缺少max"},
+		{"Options", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - type: input\n    field: a\n    options: [1]\n")}},
+			"f.yaml:4:14: 组件类型input不支持options"},
+		{"Syntax", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - type: input\n    field: a\n    sanitize: [trim\n")}},
+			"f.yaml:3: did not find expected ',' or ']'"},
+		{"MissingFile", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - $ref: missing.yaml\n")}},
+			"f.yaml:2:11: 无法读取引用的文件missing.yaml: open missing.yaml: file does not exist"},
+		{"AbsoluteRef", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - $ref: /etc/passwd\n")}},
+			"f.yaml:2:11: 无法引用/etc/passwd: 不能使用绝对路径或引用根目录以外的文件"},
+		{"ParentRef", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - $ref: ../secret.yaml\n")}},
+			"f.yaml:2:11: 无法引用../secret.yaml: 不能使用绝对路径或引用根目录以外的文件"},
+		{"MissingPointer", fstest.MapFS{"f.yaml": {Data: []byte("fields:\n  - $ref: '#/definitions/a'\n")}},
+			"f.yaml:2:11: 引用的节点不存在: #/definitions/a"},
+		{"ErrorInFragment", fstest.MapFS{
+			"f.yaml":       {Data: []byte("fields:\n  - $ref: parts/a.yaml\n")},
+			"parts/a.yaml": {Data: []byte("type: input\nfield: a\nprops: 1\n")},
+		}, "parts/a.yaml:3:8: props必须是对象"},
+		{"Cycle", fstest.MapFS{
+			"f.yaml": {Data: []byte("fields:\n  - $ref: g.yaml\n")},
+			"g.yaml": {Data: []byte("$ref: f.yaml#/fields/0\n")},
+		}, "f.yaml:2:11: 循环引用g.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadForm(tt.files, "f.yaml")
+			var de *DefinitionError
+			require.ErrorAs(t, err, &de)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+// TestLoadFormFile 测试从本地文件加载
+func TestLoadFormFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shared"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "name.yaml"), []byte("type: input\nfield: name\ntitle: 名称\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "form.json"), []byte(`{"action": "/save", "fields": [{"$ref": "shared/name.yaml", "required": "请输入名称"}]}`), 0o644))
+
+	form, err := LoadFormFile(filepath.Join(dir, "form.json"))
+	require.NoError(t, err)
+	require.Len(t, form.GetRules(), 1)
+	assert.Equal(t, "name", form.GetRules()[0].GetField())
+	assert.ErrorIs(t, form.ValidateData(map[string]interface{}{}), &FieldError{Field: "name", Rule: "required"})
+
+	_, err = LoadFormFile(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	t.Run("Outside", func(t *testing.T) {
+		outside := filepath.Join(t.TempDir(), "secret.yaml")
+		require.NoError(t, os.WriteFile(outside, []byte("type: input\nfield: secret\n"), 0o644))
+		formDir := filepath.Join(dir, "forms")
+		require.NoError(t, os.MkdirAll(formDir, 0o755))
+		link := filepath.Join(formDir, "link.yaml")
+		require.NoError(t, os.Symlink(outside, link))
+
+		for _, ref := range []string{outside, "../shared/name.yaml", "link.yaml"} {
+			file := filepath.Join(formDir, "form.yaml")
+			require.NoError(t, os.WriteFile(file, []byte("fields:\n  - $ref: "+ref+"\n"), 0o644))
+			_, err := LoadFormFile(file)
+			var de *DefinitionError
+			require.ErrorAs(t, err, &de, ref)
+			assert.Equal(t, 2, de.Line, ref)
+		}
+	})
+}
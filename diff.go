@@ -0,0 +1,226 @@
+package formbuilder
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// diff.go 实现初始数据与提交数据的变更比较
+// 编辑表单通过FormData/SetValue预填数据，提交后按组件类型比较两份数据，
+// 得到用户实际修改的字段，用于审计日志和PATCH式的部分更新
+
+// ChangeKind 字段变更类型
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"   // 初始值为空，提交了非空值
+	ChangeChanged ChangeKind = "changed" // 初始值和提交值都非空且不相等
+	ChangeRemoved ChangeKind = "removed" // 初始值非空，提交了空值
+)
+
+// FieldChange 单个字段的变更
+type FieldChange struct {
+	// Field 字段名
+	Field string `json:"field"`
+
+	// Kind 变更类型
+	Kind ChangeKind `json:"kind"`
+
+	// Old 规范化后的初始值
+	Old interface{} `json:"old"`
+
+	// New 规范化后的提交值
+	New interface{} `json:"new"`
+}
+
+// ChangeSet 字段变更列表，按组件声明顺序排列
+type ChangeSet []FieldChange
+
+// Fields 返回发生变更的字段名
+func (c ChangeSet) Fields() []string {
+	fields := make([]string, len(c))
+	for i, change := range c {
+		fields[i] = change.Field
+	}
+	return fields
+}
+
+// Values 返回变更字段的新值，可直接用于部分更新
+// 被清空的字段值为nil
+func (c ChangeSet) Values() map[string]interface{} {
+	values := make(map[string]interface{}, len(c))
+	for _, change := range c {
+		values[change.Field] = change.New
+	}
+	return values
+}
+
+// Get 返回指定字段的变更
+func (c ChangeSet) Get(field string) (FieldChange, bool) {
+	for _, change := range c {
+		if change.Field == field {
+			return change, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+// Diff 比较初始数据与提交数据，返回用户修改的字段
+// 初始值取FormData/SetValue设置的值，未设置时取组件的默认值；
+// 两份数据都先按组件类型规范化再比较，因此InputNumber的"1"与1相等，
+// Checkbox、多选Select、Tree等集合类型的值不比较顺序。
+// 只比较当前显示的字段，未提交的字段视为未修改（适用于部分提交），
+// 提交值无法规范化时返回FieldErrors
+//
+// 使用示例：
+//
+//	form.FormData(product)
+//	// 提交处理
+//	changes, err := form.Diff(values)
+//	for _, c := range changes {
+//	    log.Printf("%s %s: %v -> %v", c.Kind, c.Field, c.Old, c.New)
+//	}
+//	db.Model(&product).Updates(changes.Values())
+func (f *Form) Diff(submitted map[string]interface{}) (ChangeSet, error) {
+	var changes ChangeSet
+	var errs FieldErrors
+	seen := make(map[string]bool)
+
+	f.eachActiveComponent(f.rules, submitted, func(c Component, data *ComponentData) {
+		if data.Field == "" || seen[data.Field] {
+			return
+		}
+		seen[data.Field] = true
+
+		value, ok := submitted[data.Field]
+		if !ok {
+			return
+		}
+		newValue, err := normalizeFieldValue(c, value)
+		if err != nil {
+			errs = append(errs, &FieldError{Field: data.Field, Rule: "type", Message: err.Error()})
+			return
+		}
+
+		oldValue := f.initialValue(data)
+		if normalized, err := normalizeFieldValue(c, oldValue); err == nil {
+			oldValue = normalized
+		}
+
+		var kind ChangeKind
+		switch oldEmpty, newEmpty := isEmptyValue(oldValue), isEmptyValue(newValue); {
+		case oldEmpty && newEmpty:
+			return
+		case oldEmpty:
+			kind = ChangeAdded
+		case newEmpty:
+			kind = ChangeRemoved
+		case diffEqual(oldValue, newValue, isUnordered(c, data)):
+			return
+		default:
+			kind = ChangeChanged
+		}
+		changes = append(changes, FieldChange{Field: data.Field, Kind: kind, Old: oldValue, New: newValue})
+	})
+
+	if len(errs) > 0 {
+		return changes, errs
+	}
+	return changes, nil
+}
+
+// normalizeFieldValue 使用组件的Normalizer转换值，未实现时原样返回
+func normalizeFieldValue(c Component, value interface{}) (interface{}, error) {
+	if normalizer, ok := c.(Normalizer); ok {
+		return normalizer.Normalize(value)
+	}
+	return value, nil
+}
+
+// isUnordered 判断字段值是否为不区分顺序的集合
+func isUnordered(c Component, data *ComponentData) bool {
+	switch c.(type) {
+	case *Checkbox, *Tree:
+		return true
+	case *Select:
+		return propBool(data.Props, "multiple")
+	case *Cascader:
+		props, _ := data.Props["props"].(map[string]interface{})
+		return propBool(props, "multiple")
+	case *DatePicker:
+		return propString(data.Props, "type") == "dates"
+	}
+	return false
+}
+
+// diffEqual 比较两个规范化后的值
+// 集合类型排序后比较，时间按时刻比较，其余值按宽松规则比较（数字与数字字符串相等）
+func diffEqual(a, b interface{}, unordered bool) bool {
+	switch x := a.(type) {
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	case [2]time.Time:
+		y, ok := b.([2]time.Time)
+		return ok && x[0].Equal(y[0]) && x[1].Equal(y[1])
+	case []time.Time:
+		y, ok := b.([]time.Time)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		if unordered {
+			x, y = sortedTimes(x), sortedTimes(y)
+		}
+		for i := range x {
+			if !x[i].Equal(y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if unordered {
+		if x, ok := sortedKeys(a); ok {
+			y, ok := sortedKeys(b)
+			return ok && reflect.DeepEqual(x, y)
+		}
+	}
+	return reflect.DeepEqual(a, b) || signedValueEqual(a, b)
+}
+
+// sortedKeys 将集合中的元素转换为排序后的字符串
+// 元素为路径（Cascader多选）时按路径拼接
+func sortedKeys(value interface{}) ([]string, bool) {
+	var keys []string
+	switch v := value.(type) {
+	case []string:
+		keys = append(keys, v...)
+	case [][]string:
+		for _, path := range v {
+			keys = append(keys, strings.Join(path, "\x00"))
+		}
+	default:
+		items, ok := sliceItems(value)
+		if !ok {
+			return nil, false
+		}
+		for _, item := range items {
+			s, ok := stringValue(item)
+			if !ok {
+				return nil, false
+			}
+			keys = append(keys, s)
+		}
+	}
+	sort.Strings(keys)
+	return keys, true
+}
+
+// sortedTimes 返回按时间排序的副本
+func sortedTimes(list []time.Time) []time.Time {
+	sorted := append([]time.Time(nil), list...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	return sorted
+}
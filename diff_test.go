@@ -0,0 +1,116 @@
+package formbuilder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diff_test.go 测试初始数据与提交数据的变更比较
+
+// createDiffForm 创建用于变更比较测试的编辑表单
+func createDiffForm() *Form {
+	options := []Option{
+		{Value: "red", Label: "红"},
+		{Value: "green", Label: "绿"},
+		{Value: "blue", Label: "蓝"},
+	}
+	return NewElmForm("/product/1", []Component{
+		NewInput("name", "名称"),
+		NewInput("remark", "备注"),
+		NewInputNumber("stock", "库存"),
+		NewCheckbox("colors", "颜色").SetOptions(options),
+		NewSelect("tags", "标签").SetOptions(options).Multiple(true),
+		NewDatePicker("on_sale", "上架日期"),
+		NewSwitch("enabled", "启用"),
+	}, nil).FormData(map[string]interface{}{
+		"name":    "T恤",
+		"stock":   10,
+		"colors":  []interface{}{"red", "blue"},
+		"tags":    []string{"green"},
+		"on_sale": "2024-01-02",
+		"enabled": true,
+	})
+}
+
+// TestFormDiff 测试表单变更比较
+func TestFormDiff(t *testing.T) {
+	t.Run("NoChanges", func(t *testing.T) {
+		changes, err := createDiffForm().Diff(map[string]interface{}{
+			"name":    "T恤",
+			"stock":   "10",
+			"colors":  []interface{}{"blue", "red"},
+			"tags":    []interface{}{"green"},
+			"on_sale": "2024-01-02 00:00:00",
+			"enabled": true,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("ChangeKinds", func(t *testing.T) {
+		changes, err := createDiffForm().Diff(map[string]interface{}{
+			"name":   "卫衣",
+			"remark": "新品",
+			"stock":  float64(12),
+			"colors": []interface{}{},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name", "remark", "stock", "colors"}, changes.Fields())
+
+		name, ok := changes.Get("name")
+		require.True(t, ok)
+		assert.Equal(t, FieldChange{Field: "name", Kind: ChangeChanged, Old: "T恤", New: "卫衣"}, name)
+
+		remark, _ := changes.Get("remark")
+		assert.Equal(t, ChangeAdded, remark.Kind)
+
+		stock, _ := changes.Get("stock")
+		assert.Equal(t, ChangeChanged, stock.Kind)
+		assert.Equal(t, float64(10), stock.Old)
+		assert.Equal(t, float64(12), stock.New)
+
+		colors, _ := changes.Get("colors")
+		assert.Equal(t, ChangeRemoved, colors.Kind)
+		assert.Equal(t, []interface{}{"red", "blue"}, colors.Old)
+	})
+
+	t.Run("UnsubmittedFieldsIgnored", func(t *testing.T) {
+		changes, err := createDiffForm().Diff(map[string]interface{}{"enabled": false})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"enabled": false}, changes.Values())
+	})
+
+	t.Run("DefaultValueAsInitial", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{NewInput("status", "状态", "draft")}, nil)
+		changes, err := form.Diff(map[string]interface{}{"status": "draft"})
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("MultipleSetOrder", func(t *testing.T) {
+		changes, err := createDiffForm().Diff(map[string]interface{}{
+			"tags": []interface{}{"red", "green"},
+		})
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, []interface{}{"red", "green"}, changes[0].New)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		_, err := createDiffForm().Diff(map[string]interface{}{"stock": "many"})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, &FieldError{Field: "stock", Rule: "type"}))
+	})
+}
+
+// TestDiffEqual 测试规范化值的比较
+func TestDiffEqual(t *testing.T) {
+	assert.True(t, diffEqual([]string{"a", "b"}, []string{"b", "a"}, true))
+	assert.False(t, diffEqual([]string{"a", "b"}, []string{"b", "a"}, false))
+	assert.True(t, diffEqual([][]string{{"1", "2"}, {"3"}}, [][]string{{"3"}, {"1", "2"}}, true))
+	assert.False(t, diffEqual([][]string{{"1", "2"}}, [][]string{{"2", "1"}}, true))
+	assert.True(t, diffEqual("1", float64(1), false))
+}
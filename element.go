@@ -0,0 +1,45 @@
+package formbuilder
+
+// element.go 实现Element通用组件
+// 用于form-create支持、但本库没有对应结构体的组件，
+// 如原生HTML标签、el-button等UI组件、布局组件和自定义Vue组件。
+// ParseRules遇到未注册的组件类型时也会还原为Element
+
+// Element 通用组件
+// 组件类型、属性和子组件完全由调用方决定
+//
+// 使用示例：
+//
+//	NewElement("el-button").
+//	    Props("type", "primary").
+//	    AppendRule("children", []string{"查询"})
+type Element struct {
+	Builder[*Element]
+}
+
+// NewElement 创建通用组件
+// ruleType为form-create的组件类型，如 "span"、"el-divider"、"my-editor"
+func NewElement(ruleType string) *Element {
+	element := &Element{}
+	element.data = &ComponentData{
+		RuleType: ruleType,
+		Props:    make(map[string]interface{}),
+	}
+	element.inst = element
+	return element
+}
+
+// GetField 实现Component接口
+func (e *Element) GetField() string {
+	return e.data.Field
+}
+
+// GetType 实现Component接口
+func (e *Element) GetType() string {
+	return e.data.RuleType
+}
+
+// Build 实现Component接口
+func (e *Element) Build() map[string]interface{} {
+	return buildComponent(e.data)
+}
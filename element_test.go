@@ -0,0 +1,27 @@
+package formbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// element_test.go 测试Element通用组件
+
+// TestElement 测试通用组件的规则生成
+func TestElement(t *testing.T) {
+	button := NewElement("el-button").Props("type", "primary").AppendRule("children", []string{"查询"})
+	assert.Equal(t, "el-button", button.GetType())
+	assert.Equal(t, "", button.GetField())
+	assert.Equal(t, map[string]interface{}{
+		"type":     "el-button",
+		"props":    map[string]interface{}{"type": "primary"},
+		"children": []string{"查询"},
+	}, button.Build())
+
+	editor := NewElement("my-editor").Field("content").Title("内容").Required()
+	rule := editor.Build()
+	assert.Equal(t, "content", rule["field"])
+	assert.Equal(t, "内容", rule["title"])
+	assert.Len(t, rule["validate"], 1)
+}
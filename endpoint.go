@@ -0,0 +1,98 @@
+package formbuilder
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// endpoint.go 实现表单服务端接口的挂载
+// 远程验证等功能需要对应的HTTP接口，Mount会根据表单中的组件和验证规则自动注册
+
+// endpointProvider 需要服务端接口的组件或验证规则
+type endpointProvider interface {
+	// endpoints 返回接口地址到处理器的映射
+	endpoints() map[string]http.Handler
+}
+
+// Endpoints 返回表单需要的全部服务端接口
+// key为接口地址，value为对应的处理器；
+// 地址相同的远程验证规则合并为一个接口，只执行表单中声明的验证函数
+func (f *Form) Endpoints() map[string]http.Handler {
+	result := make(map[string]http.Handler)
+	remotes := make(map[string][]RemoteRule)
+	collect := func(v interface{}) {
+		if p, ok := v.(endpointProvider); ok {
+			for path, h := range p.endpoints() {
+				result[path] = h
+			}
+		}
+	}
+
+	f.eachComponent(f.rules, func(c Component, data *ComponentData) {
+		collect(c)
+		for _, validate := range data.Validate {
+			for _, rule := range builtinRules(validate) {
+				if remote, ok := rule.(RemoteRule); ok {
+					remotes[remote.url()] = append(remotes[remote.url()], remote)
+					continue
+				}
+				collect(rule)
+			}
+		}
+	})
+	for path, rules := range remotes {
+		result[path] = RemoteHandler(rules...)
+	}
+	return result
+}
+
+// Mount 将表单需要的服务端接口注册到mux
+// 同一个表单重复挂载时跳过已注册的地址；地址已被其他处理器（包括其他表单）注册时返回错误，
+// 不注册任何接口。默认接口地址只由字段名或规则ID决定（如DefaultCaptchaURL+"/"+field），
+// 多个表单挂载到同一个mux且字段名相同时，需要为其中一个设置不同的地址
+//
+// 使用示例：
+//
+//	mux := http.NewServeMux()
+//	if err := form.Mount(mux); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *Form) Mount(mux *http.ServeMux) error {
+	endpoints := f.Endpoints()
+	paths := make([]string, 0, len(endpoints))
+	for path := range endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var pending []string
+	for _, path := range paths {
+		h, ok := mountedHandler(mux, path)
+		if !ok {
+			pending = append(pending, path)
+			continue
+		}
+		if owned, _ := h.(*formEndpoint); owned == nil || owned.form != f {
+			return fmt.Errorf("挂载接口失败: %s已注册其他处理器", path)
+		}
+	}
+	for _, path := range pending {
+		mux.Handle(path, &formEndpoint{form: f, Handler: endpoints[path]})
+	}
+	return nil
+}
+
+// formEndpoint 由Mount注册的接口，记录所属的表单
+type formEndpoint struct {
+	form *Form
+	http.Handler
+}
+
+// mountedHandler 返回mux中以path注册的处理器
+func mountedHandler(mux *http.ServeMux, path string) (http.Handler, bool) {
+	req := &http.Request{Method: http.MethodPost, URL: &url.URL{Path: path}}
+	h, pattern := mux.Handler(req)
+	return h, pattern == path
+}
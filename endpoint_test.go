@@ -0,0 +1,89 @@
+package formbuilder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endpoint_test.go 测试服务端接口的挂载
+
+// TestFormEndpoints 测试接口收集
+func TestFormEndpoints(t *testing.T) {
+	t.Run("NoEndpoints", func(t *testing.T) {
+		assert.Empty(t, createTestForm().Endpoints())
+	})
+
+	t.Run("RemoteRules", func(t *testing.T) {
+		sku := NewRemote("test_sku", usernameTaken, "")
+		sku.URL = "/api/check"
+		code := NewRemote("test_code", usernameTaken, "")
+		code.URL = "/api/check"
+		form := NewElmForm("/submit", []Component{
+			NewInput("username", "用户名").Validate(NewRemote("test_username", usernameTaken, "")),
+			NewRadio("type", "类型").Control([]ControlRule{
+				{Value: "1", Rule: []Component{
+					NewInput("sku", "SKU").Validate(sku),
+					NewInput("code", "编码").Validate(code),
+				}},
+			}),
+		}, nil)
+
+		endpoints := form.Endpoints()
+		assert.Len(t, endpoints, 2)
+		assert.Contains(t, endpoints, DefaultRemoteURL+"/test_username")
+		require.Contains(t, endpoints, "/api/check")
+
+		post := func(path, id string) int {
+			rec := httptest.NewRecorder()
+			endpoints[path].ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path,
+				strings.NewReader(`{"id":"`+id+`","value":"x"}`)))
+			return rec.Code
+		}
+		assert.Equal(t, http.StatusOK, post("/api/check", "test_sku"), "相同地址的规则合并为一个接口")
+		assert.Equal(t, http.StatusOK, post("/api/check", "test_code"))
+		assert.Equal(t, http.StatusNotFound, post("/api/check", "test_username"), "只执行该地址上声明的验证函数")
+	})
+}
+
+// TestFormMount 测试接口挂载
+func TestFormMount(t *testing.T) {
+	newForm := func() *Form {
+		return NewElmForm("/submit", []Component{
+			NewInput("username", "用户名").Validate(NewRemote("test_username", usernameTaken, "")),
+		}, nil)
+	}
+
+	mux := http.NewServeMux()
+	form := newForm()
+	require.NoError(t, form.Mount(mux))
+	assert.NoError(t, form.Mount(mux), "同一个表单重复挂载时跳过")
+	assert.Error(t, newForm().Mount(mux), "地址已被其他表单注册")
+
+	t.Run("SameFieldName", func(t *testing.T) {
+		// 两个表单的city字段使用不同的搜索函数，默认地址相同时不能挂载到同一个mux
+		search := func(ctx context.Context, query string, page int) ([]Option, bool, error) { return nil, false, nil }
+		mux := http.NewServeMux()
+		require.NoError(t, NewElmForm("/a", []Component{NewSelect("city", "城市").RemoteSource(search)}, nil).Mount(mux))
+
+		other := NewElmForm("/b", []Component{
+			NewInput("name", "名称").Validate(NewRemote("test_username", usernameTaken, "")),
+			NewSelect("city", "城市").RemoteSource(search),
+		}, nil)
+		assert.ErrorContains(t, other.Mount(mux), DefaultOptionSearchURL+"/city")
+		_, mounted := mountedHandler(mux, DefaultRemoteURL+"/test_username")
+		assert.False(t, mounted, "冲突时不注册任何接口")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, DefaultRemoteURL+"/test_username",
+		strings.NewReader(`{"id":"test_username","value":"admin"}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "用户名已被占用")
+}
@@ -0,0 +1,212 @@
+package formbuilder
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// errors.go 定义服务端验证的错误类型
+// 错误按字段组织，便于回传到前端对应的表单项
+
+// FieldError 单个字段的验证错误
+type FieldError struct {
+	// Field 字段名，嵌套字段使用点号分隔的路径，如 "items.0.name"
+	Field string `json:"field"`
+
+	// Rule 触发错误的规则类型，如 "required", "pattern" 等
+	Rule string `json:"rule"`
+
+	// Message 错误提示信息
+	Message string `json:"message"`
+
+	// Params 规则参数，如length规则的min、max，便于调用方按参数重新生成提示信息
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// 按规则类型匹配的哨兵错误，配合errors.Is使用
+//
+// 使用示例：
+//
+//	if errors.Is(err, formbuilder.ErrRequired) {
+//	    // 存在必填错误
+//	}
+var (
+	ErrRequired  = &FieldError{Rule: "required"}
+	ErrPattern   = &FieldError{Rule: "pattern"}
+	ErrLength    = &FieldError{Rule: "length"}
+	ErrRange     = &FieldError{Rule: "range"}
+	ErrEmail     = &FieldError{Rule: "email"}
+	ErrURL       = &FieldError{Rule: "url"}
+	ErrDate      = &FieldError{Rule: "date"}
+	ErrEnum      = &FieldError{Rule: "enum"}
+	ErrOption    = &FieldError{Rule: "option"}
+	ErrType      = &FieldError{Rule: "type"}
+	ErrRemote    = &FieldError{Rule: "remote"}
+	ErrSignature = &FieldError{Rule: "signature"}
+	ErrCSRF      = &FieldError{Rule: "csrf"}
+	ErrCaptcha   = &FieldError{Rule: "captcha"}
+	ErrSpam      = &FieldError{Rule: "spam"}
+	ErrUnknown   = &FieldError{Rule: "unknown"}
+)
+
+// Error 实现error接口
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Is 支持errors.Is按字段和规则类型匹配
+// target为*FieldError时，其Field和Rule中非空的部分必须一致，
+// 因此ErrRequired匹配任意字段的必填错误，&FieldError{Field: "name"}匹配name字段的任意错误
+func (e *FieldError) Is(target error) bool {
+	t, ok := target.(*FieldError)
+	if !ok || (t.Field == "" && t.Rule == "") {
+		return false
+	}
+	return (t.Field == "" || t.Field == e.Field) && (t.Rule == "" || t.Rule == e.Rule)
+}
+
+// withParams 设置规则参数
+func (e *FieldError) withParams(params map[string]interface{}) *FieldError {
+	e.Params = params
+	return e
+}
+
+// FieldErrors 字段错误列表
+// 作为error返回时至少包含一个错误
+type FieldErrors []*FieldError
+
+// Error 实现error接口
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap 返回全部字段错误，使errors.Is和errors.As可以匹配其中的单个错误
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// Get 返回指定字段的全部错误
+func (e FieldErrors) Get(field string) []*FieldError {
+	var result []*FieldError
+	for _, fe := range e {
+		if fe.Field == field {
+			result = append(result, fe)
+		}
+	}
+	return result
+}
+
+// ByField 按字段分组返回错误信息
+//
+// 返回示例：
+//
+//	map[string][]string{
+//	    "username": {"此项必填"},
+//	    "email":    {"请输入正确的邮箱地址"},
+//	}
+func (e FieldErrors) ByField() map[string][]string {
+	result := make(map[string][]string)
+	for _, fe := range e {
+		result[fe.Field] = append(result[fe.Field], fe.Message)
+	}
+	return result
+}
+
+// newRuleError 创建规则验证错误
+// message为空时使用fallback作为提示信息
+func newRuleError(rule, message, fallback string) *FieldError {
+	if message == "" {
+		message = fallback
+	}
+	return &FieldError{Rule: rule, Message: message}
+}
+
+// ErrorResponse 提交失败时返回给前端的响应
+// FormScript生成的提交函数读取Errors，将错误显示在对应的表单项下，
+// 无法对应到表单项的错误和Message以提示框显示
+//
+// 响应示例：
+//
+//	{
+//	    "code": 422,
+//	    "message": "表单验证失败",
+//	    "errors": {"username": ["此项必填"]},
+//	    "details": [{"field": "username", "rule": "required", "message": "此项必填"}]
+//	}
+type ErrorResponse struct {
+	// Code HTTP状态码
+	Code int `json:"code"`
+
+	// Message 错误说明
+	Message string `json:"message"`
+
+	// Errors 按字段分组的错误信息
+	Errors map[string][]string `json:"errors,omitempty"`
+
+	// Details 完整的字段错误，包括规则类型和参数
+	Details FieldErrors `json:"details,omitempty"`
+}
+
+// NewErrorResponse 根据错误创建响应
+// err中包含FieldErrors或*FieldError时Code为422，其中CSRF令牌错误为403；
+// 否则为400，Message为err的信息；err为nil时返回零值
+func NewErrorResponse(err error) ErrorResponse {
+	if err == nil {
+		return ErrorResponse{}
+	}
+	var errs FieldErrors
+	var fe *FieldError
+	switch {
+	case errors.As(err, &errs):
+	case errors.As(err, &fe):
+		errs = FieldErrors{fe}
+	default:
+		return ErrorResponse{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+	if errors.Is(err, ErrCSRF) {
+		return ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: errInvalidCSRF.Error(),
+			Errors:  errs.ByField(),
+			Details: errs,
+		}
+	}
+	return ErrorResponse{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "表单验证失败",
+		Errors:  errs.ByField(),
+		Details: errs,
+	}
+}
+
+// WriteErrors 以JSON输出错误响应，HTTP状态码与响应的Code一致，err为nil时不输出
+//
+// 使用示例：
+//
+//	values, err := formbuilder.ParseRequest(form, r)
+//	if err == nil {
+//	    err = form.ValidateData(values)
+//	}
+//	if err != nil {
+//	    formbuilder.WriteErrors(w, err)
+//	    return
+//	}
+func WriteErrors(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+	resp := NewErrorResponse(err)
+	writeJSON(w, resp.Code, resp)
+}
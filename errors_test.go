@@ -0,0 +1,115 @@
+package formbuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errors_test.go 测试字段错误类型和错误响应
+
+// TestFieldErrorIs 测试errors.Is和errors.As
+func TestFieldErrorIs(t *testing.T) {
+	err := error(FieldErrors{
+		{Field: "name", Rule: "required", Message: "此项必填"},
+		{Field: "age", Rule: "range", Message: "数值超出范围", Params: map[string]interface{}{"min": 1.0, "max": 120.0}},
+	})
+
+	t.Run("ByRule", func(t *testing.T) {
+		assert.True(t, errors.Is(err, ErrRequired))
+		assert.True(t, errors.Is(err, ErrRange))
+		assert.False(t, errors.Is(err, ErrEmail))
+	})
+
+	t.Run("ByField", func(t *testing.T) {
+		assert.True(t, errors.Is(err, &FieldError{Field: "age"}))
+		assert.True(t, errors.Is(err, &FieldError{Field: "name", Rule: "required"}))
+		assert.False(t, errors.Is(err, &FieldError{Field: "name", Rule: "range"}))
+		assert.False(t, errors.Is(err, &FieldError{}))
+	})
+
+	t.Run("Wrapped", func(t *testing.T) {
+		wrapped := fmt.Errorf("save product: %w", err)
+		assert.True(t, errors.Is(wrapped, ErrRequired))
+
+		var errs FieldErrors
+		require.True(t, errors.As(wrapped, &errs))
+		assert.Len(t, errs, 2)
+
+		var fe *FieldError
+		require.True(t, errors.As(wrapped, &fe))
+		assert.Equal(t, "name", fe.Field)
+	})
+}
+
+// TestRuleErrorParams 测试规则错误携带的参数
+func TestRuleErrorParams(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		NewInput("name", "名称").Validate(NewLength(2, 10, "")),
+		NewInput("code", "编码").Validate(NewPattern(`^\d+$`, "")),
+		NewInput("confirm", "确认").Validate(NewEqualTo("name", "")),
+	}, nil)
+
+	err := form.ValidateData(map[string]interface{}{"name": "a", "code": "x", "confirm": "b"})
+	var errs FieldErrors
+	require.True(t, errors.As(err, &errs))
+
+	assert.Equal(t, map[string]interface{}{"min": 2, "max": 10}, errs.Get("name")[0].Params)
+	assert.Equal(t, map[string]interface{}{"pattern": `^\d+$`}, errs.Get("code")[0].Params)
+	assert.Equal(t, map[string]interface{}{"field": "name"}, errs.Get("confirm")[0].Params)
+}
+
+// TestErrorResponse 测试错误响应
+func TestErrorResponse(t *testing.T) {
+	t.Run("FieldErrors", func(t *testing.T) {
+		resp := NewErrorResponse(fmt.Errorf("wrapped: %w", FieldErrors{
+			{Field: "name", Rule: "required", Message: "此项必填"},
+			{Field: "name", Rule: "length", Message: "长度不符合要求"},
+		}))
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Equal(t, map[string][]string{"name": {"此项必填", "长度不符合要求"}}, resp.Errors)
+		assert.Len(t, resp.Details, 2)
+	})
+
+	t.Run("SingleFieldError", func(t *testing.T) {
+		resp := NewErrorResponse(&FieldError{Field: "name", Rule: "remote", Message: "已被占用"})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Equal(t, map[string][]string{"name": {"已被占用"}}, resp.Errors)
+	})
+
+	t.Run("OtherError", func(t *testing.T) {
+		resp := NewErrorResponse(errors.New("invalid json body"))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "invalid json body", resp.Message)
+		assert.Nil(t, resp.Errors)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		assert.Equal(t, ErrorResponse{}, NewErrorResponse(nil))
+
+		rec := httptest.NewRecorder()
+		WriteErrors(rec, nil)
+		assert.Zero(t, rec.Body.Len())
+	})
+
+	t.Run("WriteErrors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteErrors(rec, FieldErrors{{Field: "age", Rule: "range", Message: "数值超出范围", Params: map[string]interface{}{"min": 1}}})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, float64(422), body["code"])
+		assert.Equal(t, map[string]interface{}{"age": []interface{}{"数值超出范围"}}, body["errors"])
+		details := body["details"].([]interface{})
+		assert.Equal(t, map[string]interface{}{"min": float64(1)}, details[0].(map[string]interface{})["params"])
+	})
+}
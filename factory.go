@@ -112,6 +112,11 @@ func (ElmFactory) Hidden(field string, value ...interface{}) *Hidden {
 	return NewHidden(field, value...)
 }
 
+// Captcha 创建验证码
+func (ElmFactory) Captcha(field, title string, verifier CaptchaVerifier, imageURL ...string) *Captcha {
+	return NewCaptcha(field, title, verifier, imageURL...)
+}
+
 // Frame 创建框架组件（通用）
 func (ElmFactory) Frame(field, title, src string, value ...interface{}) *Frame {
 	return NewFrame(field, title, src, value...)
@@ -408,6 +413,11 @@ func (f IviewFactory) Hidden(field string, value ...interface{}) *Hidden {
 	return NewHidden(field, value...)
 }
 
+// Captcha 创建验证码
+func (f IviewFactory) Captcha(field, title string, verifier CaptchaVerifier, imageURL ...string) *Captcha {
+	return NewCaptcha(field, title, verifier, imageURL...)
+}
+
 // Frame 创建框架组件（通用）
 func (f IviewFactory) Frame(field, title, src string, value ...interface{}) *Frame {
 	return NewIviewFrame(field, title, src, value...)
@@ -0,0 +1,303 @@
+package formbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fc_designer.go 实现FcDesigner（form-create可视化设计器）导出JSON的导入
+// 设计器的规则先清理为标准的form-create规则：去掉_fc_drag_tag、_fc_id等设计器内部字段，
+// 将$required和effect.required转换为必填验证，将验证规则中的mode转换为内置规则的格式，
+// 再交给ParseRules还原为组件。fcRow、col、elCard、elTabs等布局组件还原为Element，
+// 其中的字段仍参与服务端验证、数据绑定和FormData回填
+
+// ImportDesigner 导入FcDesigner导出的规则和表单配置
+// rule为设计器getJson()导出的规则数组，也可以是同时包含rule和option的对象；
+// option为设计器getOptionsJson()导出的配置，为空时使用默认配置。
+// 配置中的form映射为FormStyle，submitBtn、resetBtn支持布尔值和对象两种写法，
+// formName、language、globalEvent等只在设计器中使用的配置会被忽略
+//
+// 使用示例：
+//
+//	rules, config, err := formbuilder.ImportDesigner(ruleJSON, optionJSON)
+//	if err != nil {
+//	    return err
+//	}
+//	form := formbuilder.NewElmForm("/apply/save", rules, config)
+func ImportDesigner(rule, option []byte) ([]Component, *Config, error) {
+	components, config, err := importDesigner(rule, option)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config == nil {
+		config = NewElmConfig()
+	}
+	return components, config, nil
+}
+
+// importDesigner 导入设计器JSON，没有配置时返回的Config为nil
+func importDesigner(rule, option []byte) ([]Component, *Config, error) {
+	var raw interface{}
+	if err := decodeJSON(rule, &raw); err != nil {
+		return nil, nil, fmt.Errorf("解析设计器规则失败: %w", err)
+	}
+
+	var rawOption interface{}
+	if wrapper, ok := raw.(map[string]interface{}); ok {
+		raw = wrapper["rule"]
+		rawOption = wrapper["option"]
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("解析设计器规则失败: 规则必须是数组")
+	}
+
+	if len(option) > 0 {
+		if err := decodeJSON(option, &rawOption); err != nil {
+			return nil, nil, fmt.Errorf("解析设计器配置失败: %w", err)
+		}
+	}
+	var config *Config
+	if rawOption != nil {
+		opts, ok := rawOption.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("解析设计器配置失败: 配置必须是对象")
+		}
+		config = NewElmConfig()
+		applyDesignerOption(config, opts)
+	}
+
+	components, err := parseRuleList(cleanDesignerRules(list), "rules", ParseRulesOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return components, config, nil
+}
+
+// LoadDesigner 从FcDesigner导出的JSON加载表单的组件和配置，替换原有组件
+// option为空且rule中不包含option时保留表单原有的配置；与LoadFormRule相同，忽略规则中的系统字段
+//
+// 使用示例：
+//
+//	form := formbuilder.NewElmForm("/apply/save", nil, nil)
+//	if err := form.LoadDesigner(ruleJSON, optionJSON); err != nil {
+//	    return err
+//	}
+//	form.FormData(saved)
+func (f *Form) LoadDesigner(rule, option []byte) error {
+	rules, config, err := importDesigner(rule, option)
+	if err != nil {
+		return err
+	}
+	if err := f.loadRules(rules); err != nil {
+		return err
+	}
+	if config != nil {
+		f.config = config
+	}
+	return nil
+}
+
+// applyDesignerOption 将设计器配置写入Config
+func applyDesignerOption(c *Config, opts map[string]interface{}) {
+	if form, ok := opts["form"].(map[string]interface{}); ok && len(form) > 0 {
+		c.FormStyle(form)
+	}
+	switch btn := opts["submitBtn"].(type) {
+	case bool:
+		c.SubmitBtn(btn)
+	case map[string]interface{}:
+		c.SetSubmitBtnProps(btn)
+	}
+	switch btn := opts["resetBtn"].(type) {
+	case bool:
+		c.ResetBtn(btn)
+	case map[string]interface{}:
+		c.SetResetBtnProps(btn)
+	}
+	if row, ok := opts["row"].(map[string]interface{}); ok && len(row) > 0 {
+		c.Row(row)
+	}
+	if info, ok := opts["info"].(map[string]interface{}); ok && len(info) > 0 {
+		c.info = info
+	}
+	if global, ok := opts["global"].(map[string]interface{}); ok {
+		c.SetGlobal(global)
+	}
+}
+
+// cleanDesignerRules 清理规则数组，非对象元素原样保留
+func cleanDesignerRules(list []interface{}) []interface{} {
+	cleaned := make([]interface{}, len(list))
+	for i, item := range list {
+		if rule, ok := item.(map[string]interface{}); ok {
+			cleaned[i] = cleanDesignerRule(rule)
+		} else {
+			cleaned[i] = item
+		}
+	}
+	return cleaned
+}
+
+// cleanDesignerRule 将设计器规则清理为标准的form-create规则
+func cleanDesignerRule(rule map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(rule))
+	for k, v := range rule {
+		if !strings.HasPrefix(k, "_fc") {
+			out[k] = v
+		}
+	}
+	ruleType, _ := out["type"].(string)
+	if ruleType != "" {
+		ruleType = canonicalRuleType(ruleType)
+		out["type"] = ruleType
+	}
+
+	required := out["$required"]
+	delete(out, "$required")
+	if effect, ok := out["effect"].(map[string]interface{}); ok {
+		rest := make(map[string]interface{}, len(effect))
+		for k, v := range effect {
+			switch {
+			case k == "required":
+				if required == nil {
+					required = v
+				}
+			case !isEmptyValue(v):
+				rest[k] = v
+			}
+		}
+		out["effect"] = rest
+	}
+
+	// 设计器会写出默认的显示状态和空的属性
+	if out["display"] == true {
+		delete(out, "display")
+	}
+	if out["hidden"] == false {
+		delete(out, "hidden")
+	}
+	for _, k := range []string{"title", "info", "props", "emit", "effect", "validate", "children", "control"} {
+		if v, ok := out[k]; ok && isEmptyValue(v) {
+			delete(out, k)
+		}
+	}
+
+	var validate []interface{}
+	if list, ok := out["validate"].([]interface{}); ok {
+		for _, item := range list {
+			if m, ok := item.(map[string]interface{}); ok {
+				item = cleanDesignerValidate(m, ruleType)
+			}
+			validate = append(validate, item)
+		}
+	}
+	if msg, ok := designerRequired(required); ok && !hasRequiredRule(validate) {
+		validate = append([]interface{}{NewRequired(msg...).ToMap()}, validate...)
+	}
+	if len(validate) > 0 {
+		out["validate"] = validate
+	}
+
+	if children, ok := out["children"].([]interface{}); ok {
+		out["children"] = cleanDesignerRules(children)
+	}
+	if control, ok := out["control"].([]interface{}); ok {
+		cleaned := make([]interface{}, len(control))
+		for i, item := range control {
+			cleaned[i] = item
+			ctrl, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			copied := make(map[string]interface{}, len(ctrl))
+			for k, v := range ctrl {
+				copied[k] = v
+			}
+			if rules, ok := ctrl["rule"].([]interface{}); ok {
+				copied["rule"] = cleanDesignerRules(rules)
+			}
+			cleaned[i] = copied
+		}
+		out["control"] = cleaned
+	}
+	return out
+}
+
+// cleanDesignerValidate 将设计器的验证规则转换为内置规则的格式
+// 设计器用mode标记规则类型，len规则转换为相同的min和max，
+// 数字类组件的min、max规则补充type为number，按数值范围校验；长度规则中为0的min不限制，直接去掉
+func cleanDesignerValidate(m map[string]interface{}, ruleType string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	mode, _ := out["mode"].(string)
+	delete(out, "mode")
+
+	switch mode {
+	case "len":
+		if n, ok := out["len"]; ok {
+			out["min"], out["max"] = n, n
+			delete(out, "len")
+		}
+		fallthrough
+	case "min", "max":
+		if _, ok := out["type"]; !ok && numberRuleTypes[ruleType] {
+			out["type"] = "number"
+		}
+		// 最小长度为0时不限制，LengthRule不输出，去掉后才能还原为内置规则；数值的min为0时保留，见RangeRule.MinSet
+		if n, ok := toFloat(out["min"]); ok && n == 0 && out["type"] == nil {
+			delete(out, "min")
+		}
+	case "email", "url":
+		if _, ok := out["type"]; !ok {
+			out["type"] = mode
+		}
+	}
+	if msg, ok := out["message"]; ok && isEmptyValue(msg) {
+		delete(out, "message")
+	}
+	return out
+}
+
+// designerRequired 解析$required的值
+// true表示必填并使用默认提示，非空字符串作为提示信息
+func designerRequired(v interface{}) ([]string, bool) {
+	switch v := v.(type) {
+	case bool:
+		return nil, v
+	case string:
+		if v != "" {
+			return []string{v}, true
+		}
+	}
+	return nil, false
+}
+
+// hasRequiredRule 判断验证规则中是否已有必填规则
+func hasRequiredRule(validate []interface{}) bool {
+	for _, item := range validate {
+		if m, ok := item.(map[string]interface{}); ok && m["required"] == true {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalRuleType 将设计器中的组件类型统一为注册表中的写法
+// 忽略大小写和"-"，如 "input-number"、"InputNumber" 都对应 "inputNumber"；未注册的类型原样返回
+func canonicalRuleType(ruleType string) string {
+	key := strings.ToLower(strings.ReplaceAll(ruleType, "-", ""))
+	ruleTypeRegistry.RLock()
+	defer ruleTypeRegistry.RUnlock()
+	if _, ok := ruleTypeRegistry.factories[ruleType]; ok {
+		return ruleType
+	}
+	for name := range ruleTypeRegistry.factories {
+		if strings.ToLower(name) == key {
+			return name
+		}
+	}
+	return ruleType
+}
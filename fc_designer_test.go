@@ -0,0 +1,196 @@
+package formbuilder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fc_designer_test.go 测试FcDesigner导出JSON的导入
+
+// designerRule 设计器导出的规则，包含布局组件和设计器内部字段
+const designerRule = `[
+  {"type": "fcRow", "_fc_id": "id_F1", "_fc_drag_tag": "row", "display": true, "hidden": false, "children": [
+    {"type": "col", "_fc_drag_tag": "col", "props": {"span": 12}, "display": true, "hidden": false, "children": [
+      {"type": "input", "field": "name", "title": "姓名", "info": "", "$required": true, "props": {},
+       "effect": {"fetch": ""}, "_fc_id": "id_F2", "name": "ref_F2", "_fc_drag_tag": "input", "display": true, "hidden": false,
+       "validate": [{"trigger": "blur", "mode": "min", "message": "至少2个字", "min": 2}]}
+    ]},
+    {"type": "col", "props": {"span": 12}, "children": [
+      {"type": "input-number", "field": "age", "title": "年龄", "$required": "请填写年龄", "_fc_drag_tag": "inputNumber",
+       "validate": [{"trigger": "change", "mode": "max", "message": "不能超过120", "max": 120}]}
+    ]}
+  ]},
+  {"type": "elCard", "props": {"header": "联系方式"}, "_fc_drag_tag": "elCard", "children": [
+    {"type": "input", "field": "email", "title": "邮箱", "_fc_drag_tag": "input",
+     "validate": [{"trigger": "blur", "mode": "email", "message": "邮箱格式错误"}]}
+  ]},
+  {"type": "elTabs", "_fc_drag_tag": "elTabs", "children": [
+    {"type": "elTabPane", "props": {"label": "偏好"}, "_fc_drag_tag": "elTabPane", "children": [
+      {"type": "select", "field": "city", "title": "城市", "effect": {"required": "请选择城市"}, "_fc_drag_tag": "select",
+       "options": [{"label": "北京", "value": "bj"}, {"label": "上海", "value": "sh"}]},
+      {"type": "input", "field": "code", "title": "编码", "_fc_drag_tag": "input",
+       "validate": [{"trigger": "blur", "mode": "len", "message": "编码为6位", "len": 6}]}
+    ]}
+  ]}
+]`
+
+// designerOption 设计器导出的表单配置
+const designerOption = `{
+  "form": {"labelPosition": "right", "size": "default", "labelWidth": "125px"},
+  "submitBtn": {"show": true, "innerText": "提交申请"},
+  "resetBtn": false,
+  "formName": "申请表",
+  "language": {}
+}`
+
+// TestImportDesigner 测试导入设计器规则和配置
+func TestImportDesigner(t *testing.T) {
+	rules, config, err := ImportDesigner([]byte(designerRule), []byte(designerOption))
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	t.Run("Layout", func(t *testing.T) {
+		row := rules[0].(*Element)
+		assert.Equal(t, "fcRow", row.GetType())
+		require.Len(t, row.data.Children, 2)
+		col := row.data.Children[0].(*Element)
+		assert.Equal(t, map[string]interface{}{"span": json.Number("12")}, col.data.Props)
+		assert.Nil(t, col.data.AppendRule, "display、hidden和_fc开头的字段被清理")
+
+		built := rules[2].Build()
+		pane := built["children"].([]map[string]interface{})[0]
+		assert.Equal(t, "elTabPane", pane["type"])
+		assert.Equal(t, "city", pane["children"].([]map[string]interface{})[0]["field"])
+	})
+
+	t.Run("Components", func(t *testing.T) {
+		name := rules[0].(*Element).data.Children[0].(*Element).data.Children[0].(*Input)
+		assert.Equal(t, "姓名", name.data.Title)
+		assert.Equal(t, []ValidateRule{NewRequired(), LengthRule{Min: 2, Message: "至少2个字", Trigger: "blur"}}, name.data.Validate)
+		assert.Equal(t, map[string]interface{}{"name": "ref_F2"}, name.data.AppendRule, "空的effect被清理")
+
+		age := rules[0].(*Element).data.Children[1].(*Element).data.Children[0]
+		require.IsType(t, &InputNumber{}, age, "组件类型忽略大小写和-")
+		assert.Equal(t, []ValidateRule{
+			NewRequired("请填写年龄"),
+			RangeRule{Max: 120, Message: "不能超过120", Trigger: "change"},
+		}, age.(*InputNumber).data.Validate)
+
+		email := rules[1].(*Element).data.Children[0].(*Input)
+		assert.Equal(t, []ValidateRule{EmailRule{Message: "邮箱格式错误", Trigger: "blur"}}, email.data.Validate)
+
+		pane := rules[2].(*Element).data.Children[0].(*Element)
+		city := pane.data.Children[0].(*Select)
+		assert.Equal(t, []ValidateRule{NewRequired("请选择城市")}, city.data.Validate, "effect.required转换为必填规则")
+		assert.Len(t, city.options, 2)
+		code := pane.data.Children[1].(*Input)
+		assert.Equal(t, []ValidateRule{LengthRule{Min: 6, Max: 6, Message: "编码为6位", Trigger: "blur"}}, code.data.Validate)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		m := config.ToMap()
+		assert.Equal(t, "125px", m["formStyle"].(map[string]interface{})["labelWidth"])
+		assert.Equal(t, map[string]interface{}{"show": true, "innerText": "提交申请"}, m["submitBtn"])
+		assert.Equal(t, false, m["resetBtn"].(map[string]interface{})["show"])
+		assert.NotContains(t, m, "global", "设计器专用配置被忽略")
+	})
+
+	t.Run("Wrapper", func(t *testing.T) {
+		data := `{"rule": [{"type": "input", "field": "a", "_fc_drag_tag": "input"}], "option": {"submitBtn": false}}`
+		rules, config, err := ImportDesigner([]byte(data), nil)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, false, config.ToMap()["submitBtn"].(map[string]interface{})["show"])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, _, err := ImportDesigner([]byte(`{"type": "input"}`), nil)
+		assert.EqualError(t, err, "解析设计器规则失败: 规则必须是数组")
+		_, _, err = ImportDesigner([]byte(`[]`), []byte(`[]`))
+		assert.EqualError(t, err, "解析设计器配置失败: 配置必须是对象")
+		_, _, err = ImportDesigner([]byte(`[{"type": "fcRow", "children": [{"_fc_drag_tag": "input"}]}]`), nil)
+		assert.EqualError(t, err, "rules[0].children[0]: 缺少组件类型")
+	})
+}
+
+// TestFormLoadDesigner 测试表单加载设计器JSON后的验证、绑定和数据回填
+func TestFormLoadDesigner(t *testing.T) {
+	form := NewElmForm("/apply", nil, nil)
+	require.NoError(t, form.LoadDesigner([]byte(designerRule), []byte(designerOption)))
+	assert.Equal(t, "125px", form.FormConfig()["formStyle"].(map[string]interface{})["labelWidth"])
+
+	t.Run("Validate", func(t *testing.T) {
+		err := form.ValidateData(map[string]interface{}{"name": "张", "age": 130, "email": "bad", "code": "123"})
+		var errs FieldErrors
+		require.ErrorAs(t, err, &errs)
+		fields := make(map[string]string)
+		for _, fe := range errs {
+			fields[fe.Field] = fe.Message
+		}
+		assert.Equal(t, map[string]string{
+			"name":  "至少2个字",
+			"age":   "不能超过120",
+			"email": "邮箱格式错误",
+			"city":  "请选择城市",
+			"code":  "编码为6位",
+		}, fields)
+
+		assert.NoError(t, form.ValidateData(map[string]interface{}{
+			"name": "张三", "age": 30, "email": "a@example.com", "city": "bj", "code": "123456",
+		}))
+	})
+
+	t.Run("Bind", func(t *testing.T) {
+		var dst struct {
+			Name string `form:"name"`
+			Age  int    `form:"age"`
+			City string `form:"city"`
+		}
+		require.NoError(t, form.Bind(map[string]interface{}{"name": "张三", "age": "30", "city": "sh"}, &dst))
+		assert.Equal(t, "张三", dst.Name)
+		assert.Equal(t, 30, dst.Age)
+		assert.Equal(t, "sh", dst.City)
+	})
+
+	t.Run("FormData", func(t *testing.T) {
+		form.FormData(map[string]interface{}{"name": "李四", "city": "sh"})
+		rules := form.FormRule()
+		col := rules[0]["children"].([]map[string]interface{})[0]
+		assert.Equal(t, "李四", col["children"].([]map[string]interface{})[0]["value"], "布局组件中的字段同样回填")
+		pane := rules[2]["children"].([]map[string]interface{})[0]
+		assert.Equal(t, "sh", pane["children"].([]map[string]interface{})[0]["value"])
+	})
+
+	t.Run("KeepConfig", func(t *testing.T) {
+		config := NewElmConfig().SubmitBtn(true, "保存")
+		form := NewElmForm("/apply", nil, config)
+		require.NoError(t, form.LoadDesigner([]byte(`[{"type": "input", "field": "a"}]`), nil))
+		assert.Same(t, config, form.GetConfig(), "没有配置时保留表单原有配置")
+	})
+
+	t.Run("SystemFields", func(t *testing.T) {
+		form := NewElmForm("/apply", nil, nil).SetSignKey([]byte("k"))
+		rule := `[{"type": "input", "field": "a"}, {"type": "hidden", "field": "` + SignatureField + `", "value": "x"},
+			{"type": "hidden", "field": "code` + CaptchaIDSuffix + `", "value": "y"}]`
+		require.NoError(t, form.LoadDesigner([]byte(rule), nil))
+		require.Len(t, form.GetRules(), 1, "与LoadFormRule相同，忽略系统字段")
+		assert.Equal(t, "a", form.GetRules()[0].GetField())
+	})
+
+	t.Run("ZeroMin", func(t *testing.T) {
+		form := NewElmForm("/apply", nil, nil)
+		require.NoError(t, form.LoadDesigner([]byte(`[
+			{"type": "inputNumber", "field": "qty", "validate": [{"mode": "min", "min": 0, "message": "不能为负数"}]},
+			{"type": "input", "field": "note", "validate": [{"mode": "min", "min": 0, "message": "x"}]}]`), nil))
+		rules := form.GetRules()
+		assert.Equal(t, RangeRule{MinSet: true, Message: "不能为负数"}, rules[0].(*InputNumber).data.Validate[0])
+		assert.IsType(t, CustomRule{}, rules[1].(*Input).data.Validate[0], "长度为0的min去掉后规则不限制")
+
+		err := form.ValidateData(map[string]interface{}{"qty": -1})
+		assert.ErrorIs(t, err, &FieldError{Field: "qty", Rule: "range"})
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"qty": 0}))
+	})
+}
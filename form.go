@@ -3,6 +3,7 @@ package formbuilder
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // form.go 实现Form核心类
@@ -32,6 +33,11 @@ type Form struct {
 	ui           Bootstrap              // UI引导实例
 	dependScript []string               // 依赖脚本列表
 	title        string                 // 表单标题
+	signKey      []byte                 // 防篡改签名密钥
+	signMaxAge   time.Duration          // 签名有效期
+	csrf         CSRFProtector          // CSRF防护
+	csrfToken    string                 // 当前请求的CSRF令牌，只在ForRequest返回的副本中设置
+	honeypot     HoneypotOptions        // 蜜罐防护配置
 }
 
 // NewElmForm 创建Element UI表单
@@ -170,6 +176,14 @@ func (f *Form) FormData(data map[string]interface{}) *Form {
 	return f
 }
 
+// initialValue 返回字段的初始值，FormData中的值优先于组件自身的Value
+func (f *Form) initialValue(data *ComponentData) interface{} {
+	if v, ok := f.formData[data.Field]; ok {
+		return v
+	}
+	return data.Value
+}
+
 // SetValue 设置单个字段的值
 func (f *Form) SetValue(field string, value interface{}) *Form {
 	if f.formData == nil {
@@ -230,6 +244,22 @@ func (f *Form) getComponentData(c Component) *ComponentData {
 	return nil
 }
 
+// eachComponent 递归遍历组件树
+// 包括control与children中的全部组件，按声明顺序回调fn
+func (f *Form) eachComponent(rules []Component, fn func(c Component, data *ComponentData)) {
+	for _, rule := range rules {
+		data := f.getComponentData(rule)
+		if data == nil {
+			continue
+		}
+		fn(rule, data)
+		for _, ctrl := range data.Control {
+			f.eachComponent(ctrl.Rule, fn)
+		}
+		f.eachComponent(data.Children, fn)
+	}
+}
+
 // FormRule 获取表单规则数组（应用formData后）
 // 对应PHP的formRule()方法
 func (f *Form) FormRule() []map[string]interface{} {
@@ -241,9 +271,47 @@ func (f *Form) FormRule() []map[string]interface{} {
 	// 应用表单数据
 	f.applyFormData(rules)
 
+	// 追加系统字段（签名、CSRF令牌等）
+	return append(rules, f.systemRules()...)
+}
+
+// systemRules 返回表单自动注入的系统字段规则
+func (f *Form) systemRules() []map[string]interface{} {
+	var rules []map[string]interface{}
+	if len(f.signKey) > 0 {
+		rules = append(rules, NewHidden(SignatureField, f.signatureToken(time.Now())).Build())
+	}
+	if f.csrfToken != "" {
+		rules = append(rules, NewHidden(CSRFField, f.csrfToken).Build())
+	}
+	if len(f.honeypot.Key) > 0 {
+		rules = append(rules, f.honeypotRules()...)
+	}
+	f.eachComponent(f.rules, func(c Component, data *ComponentData) {
+		if captcha, ok := c.(*Captcha); ok {
+			rules = append(rules, NewHidden(captcha.IDField(), captcha.id).Build())
+		}
+	})
 	return rules
 }
 
+// systemFields 返回FormRule可能注入的系统字段名
+// 包括签名、CSRF令牌、渲染时间、开启时的蜜罐字段以及验证码的挑战ID字段。
+// 系统字段不在组件树中声明：ParseRequest原样保留签名和挑战ID（CSRF、蜜罐字段校验后移除），
+// LoadFormRule忽略这些字段的规则
+func (f *Form) systemFields() []string {
+	fields := []string{SignatureField, CSRFField, RenderTimeField}
+	if len(f.honeypot.Key) > 0 {
+		fields = append(fields, f.honeypotField())
+	}
+	f.eachComponent(f.rules, func(c Component, data *ComponentData) {
+		if captcha, ok := c.(*Captcha); ok {
+			fields = append(fields, captcha.IDField())
+		}
+	})
+	return fields
+}
+
 // ParseFormRule 返回JSON格式的表单规则
 // 对应PHP的parseFormRule()方法
 func (f *Form) ParseFormRule() (string, error) {
@@ -284,14 +352,11 @@ func (f *Form) ParseFormConfig() (string, error) {
 // 对应PHP的deepSetFormData()方法
 func (f *Form) applyFormData(rules []map[string]interface{}) {
 	for _, rule := range rules {
-		field, ok := rule["field"].(string)
-		if !ok || field == "" {
-			continue
-		}
-
-		// 设置值
-		if value, exists := f.formData[field]; exists {
-			rule["value"] = value
+		// 设置值，没有field的布局组件只处理其子组件
+		if field, ok := rule["field"].(string); ok && field != "" {
+			if value, exists := f.formData[field]; exists {
+				rule["value"] = value
+			}
 		}
 
 		// 递归处理control
@@ -513,3 +513,18 @@ func BenchmarkParseFormRule(b *testing.B) {
 		_, _ = form.ParseFormRule()
 	}
 }
+
+// TestFormSystemFields 测试系统字段名的统一定义
+func TestFormSystemFields(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		NewInput("name", "名称"),
+		NewCaptcha("code", "验证码", &stubVerifier{}),
+	}, nil)
+	assert.Equal(t, []string{SignatureField, CSRFField, RenderTimeField, "code" + CaptchaIDSuffix}, form.systemFields())
+
+	form.SetHoneypot(HoneypotOptions{Key: []byte("k"), Field: "website"})
+	assert.Contains(t, form.systemFields(), "website")
+	assert.True(t, form.isSystemRule(NewHidden(CSRFField, "")))
+	assert.True(t, form.isSystemRule(NewInput("website", "")))
+	assert.False(t, form.isSystemRule(NewInput("name", "")))
+}
@@ -7,6 +7,8 @@ package formbuilder
 // 用于在iframe弹出框中选择内容（图片、文件、输入等）
 type Frame struct {
 	Builder[*Frame]
+	source        FrameSource        // 选择页面的数据源，见Picker
+	pickerOptions FramePickerOptions // 选择页面选项
 }
 
 // Frame类型常量
@@ -121,6 +123,13 @@ func (f *Frame) Disabled(disabled bool) *Frame {
 	return f
 }
 
+// Normalize 实现Normalizer接口
+// maxLength为1时转换为string，否则转换为[]string
+func (f *Frame) Normalize(value interface{}) (interface{}, error) {
+	maxLength, _ := propInt(f.data.Props, "maxLength")
+	return normalizeStringOrStrings(value, maxLength == 1)
+}
+
 // GetField 实现Component接口
 func (f *Frame) GetField() string {
 	return f.data.Field
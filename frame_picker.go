@@ -0,0 +1,343 @@
+package formbuilder
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// frame_picker.go 实现Frame组件的选择页面
+// Frame组件在iframe中打开src地址，页面加载后form-create注入window.form_create_helper，
+// 页面通过 set(field, value)、get(field)、close(field) 读写字段值和关闭弹出框。
+// Picker根据Go数据源生成带搜索和分页的选择页面，并在服务端验证时检查提交的值来自数据源
+
+// DefaultFramePageSize 选择页面每页默认显示的条目数
+const DefaultFramePageSize = 20
+
+// FrameItem 选择页面中的条目
+type FrameItem struct {
+	ID    string `json:"id"`              // 条目ID，选中后作为字段值；图片类型的Frame应为图片地址
+	Label string `json:"label"`           // 显示名称
+	Thumb string `json:"thumb,omitempty"` // 缩略图地址
+}
+
+// FrameQuery 选择页面的查询条件
+type FrameQuery struct {
+	Keyword  string // 搜索关键字
+	Page     int    // 页码，从1开始
+	PageSize int    // 每页条目数
+}
+
+// FrameSource 选择页面的数据源
+type FrameSource interface {
+	// Search 按条件查询一页条目，同时返回符合条件的条目总数
+	Search(ctx context.Context, query FrameQuery) ([]FrameItem, int, error)
+
+	// Lookup 按ID查询条目，不存在的ID直接忽略
+	Lookup(ctx context.Context, ids []string) ([]FrameItem, error)
+}
+
+// FrameItems 固定的条目列表，实现FrameSource接口
+// 搜索时匹配ID或名称中包含关键字（不区分大小写）的条目
+type FrameItems []FrameItem
+
+// Search 实现FrameSource接口
+func (s FrameItems) Search(ctx context.Context, query FrameQuery) ([]FrameItem, int, error) {
+	keyword := strings.ToLower(strings.TrimSpace(query.Keyword))
+	var matched []FrameItem
+	for _, item := range s {
+		if keyword == "" || strings.Contains(strings.ToLower(item.Label), keyword) ||
+			strings.Contains(strings.ToLower(item.ID), keyword) {
+			matched = append(matched, item)
+		}
+	}
+
+	start := (query.Page - 1) * query.PageSize
+	if start < 0 || start >= len(matched) {
+		return nil, len(matched), nil
+	}
+	end := min(start+query.PageSize, len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+// Lookup 实现FrameSource接口
+func (s FrameItems) Lookup(ctx context.Context, ids []string) ([]FrameItem, error) {
+	var items []FrameItem
+	for _, item := range s {
+		for _, id := range ids {
+			if item.ID == id {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+	return items, nil
+}
+
+// FramePickerOptions 选择页面选项
+type FramePickerOptions struct {
+	// PageSize 每页条目数，为0时使用DefaultFramePageSize
+	PageSize int
+
+	// Title 页面标题，为空时使用组件标题
+	Title string
+}
+
+// Picker 设置选择页面的数据源，由Form.Mount在组件的src地址注册选择页面
+// MaxLength为1时单选，点击条目后直接关闭弹出框；否则多选，选择结果实时写入字段，
+// 并按MaxLength限制数量。服务端验证时检查提交的值都能在数据源中查到
+//
+// 使用示例：
+//
+//	Elm.FrameImages("photos", "相册", "/frame/photos").MaxLength(5).
+//	    Picker(formbuilder.FrameItems{
+//	        {ID: "/static/a.jpg", Label: "海边", Thumb: "/static/a_thumb.jpg"},
+//	        {ID: "/static/b.jpg", Label: "山景", Thumb: "/static/b_thumb.jpg"},
+//	    })
+func (f *Frame) Picker(source FrameSource, opts ...FramePickerOptions) *Frame {
+	f.source = source
+	if len(opts) > 0 {
+		f.pickerOptions = opts[0]
+	}
+	return f
+}
+
+// endpoints 实现endpointProvider接口
+// 只注册本站地址，src中的查询参数不影响注册的路径
+func (f *Frame) endpoints() map[string]http.Handler {
+	if f.source == nil {
+		return nil
+	}
+	u, err := url.Parse(propString(f.data.Props, "src"))
+	if err != nil || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return nil
+	}
+	return map[string]http.Handler{u.Path: FramePickerHandler(f, f.source, f.pickerOptions)}
+}
+
+// checkOptions 实现optionChecker接口
+// 未设置数据源时不检查
+func (f *Frame) checkOptions(ctx context.Context, value, defaults interface{}) error {
+	if f.source == nil {
+		return nil
+	}
+	ids, err := toStrings(value)
+	if err != nil {
+		return errInvalidOption
+	}
+	if maxLength, _ := propInt(f.data.Props, "maxLength"); maxLength > 0 && len(ids) > maxLength {
+		return fmt.Errorf("最多只能选择%d项", maxLength)
+	}
+
+	items, err := f.source.Lookup(ctx, ids)
+	if err != nil {
+		return errOptionLookup
+	}
+	found := make(map[string]bool, len(items))
+	for _, item := range items {
+		found[item.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return errInvalidOption
+		}
+	}
+	return nil
+}
+
+// FramePickerHandler 返回Frame组件的选择页面
+// 页面接收GET请求，q为搜索关键字，page为页码；src中的其他查询参数在搜索和翻页时保留
+func FramePickerHandler(f *Frame, source FrameSource, opts ...FramePickerOptions) http.Handler {
+	var opt FramePickerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.PageSize <= 0 {
+		opt.PageSize = DefaultFramePageSize
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		params := r.URL.Query()
+		page, _ := strconv.Atoi(params.Get("page"))
+		page = max(page, 1)
+		query := FrameQuery{Keyword: strings.TrimSpace(params.Get("q")), Page: page, PageSize: opt.PageSize}
+		items, total, err := source.Search(r.Context(), query)
+		if err != nil {
+			http.Error(w, "加载失败", http.StatusInternalServerError)
+			return
+		}
+
+		maxLength, _ := propInt(f.data.Props, "maxLength")
+		data := framePage{
+			Title:    withDefault(opt.Title, f.data.Title),
+			Keyword:  query.Keyword,
+			Items:    items,
+			Page:     page,
+			Pages:    max((total+opt.PageSize-1)/opt.PageSize, 1),
+			Multiple: maxLength != 1,
+			Config: map[string]interface{}{
+				"field":     f.data.Field,
+				"multiple":  maxLength != 1,
+				"maxLength": maxLength,
+			},
+		}
+		names := make([]string, 0, len(params))
+		for name := range params {
+			if name != "q" && name != "page" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, v := range params[name] {
+				data.Hidden = append(data.Hidden, [2]string{name, v})
+			}
+		}
+		if page > 1 {
+			data.PrevURL = framePageURL(params, page-1)
+		}
+		if page < data.Pages {
+			data.NextURL = framePageURL(params, page+1)
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := framePageTemplate.Execute(w, data); err != nil {
+			http.Error(w, "渲染失败", http.StatusInternalServerError)
+		}
+	})
+}
+
+// framePage 选择页面的模板数据
+type framePage struct {
+	Title    string
+	Keyword  string
+	Items    []FrameItem
+	Page     int
+	Pages    int
+	PrevURL  string
+	NextURL  string
+	Hidden   [][2]string
+	Multiple bool
+	Config   map[string]interface{}
+}
+
+// framePageURL 返回翻页地址
+func framePageURL(params url.Values, page int) string {
+	next := make(url.Values, len(params))
+	for k, v := range params {
+		next[k] = v
+	}
+	next.Set("page", strconv.Itoa(page))
+	return "?" + next.Encode()
+}
+
+// framePageTemplate 选择页面模板
+// 多选时每次勾选都通过set写入字段，翻页后根据get的结果恢复勾选状态
+var framePageTemplate = template.Must(template.New("frame").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <style>
+        body { margin: 0; padding: 16px; font-family: -apple-system, "Helvetica Neue", Arial, sans-serif; font-size: 14px; color: #303133; }
+        .fb-search { display: flex; gap: 8px; margin-bottom: 16px; }
+        .fb-search input[type=text] { flex: 1; padding: 6px 10px; border: 1px solid #dcdfe6; border-radius: 4px; }
+        .fb-items { display: grid; grid-template-columns: repeat(auto-fill, minmax(120px, 1fr)); gap: 12px; }
+        .fb-item { display: block; padding: 8px; border: 1px solid #ebeef5; border-radius: 4px; cursor: pointer; text-align: center; word-break: break-all; }
+        .fb-item:hover { border-color: #409eff; }
+        .fb-item img { display: block; width: 100%; height: 90px; object-fit: cover; margin-bottom: 6px; }
+        .fb-empty { padding: 40px 0; text-align: center; color: #909399; }
+        .fb-footer { display: flex; align-items: center; justify-content: space-between; margin-top: 16px; }
+        .fb-footer a { color: #409eff; text-decoration: none; margin-right: 12px; }
+        button { padding: 6px 16px; border: 1px solid #409eff; border-radius: 4px; background: #409eff; color: #fff; cursor: pointer; }
+    </style>
+</head>
+<body>
+    <form class="fb-search" method="get">
+        {{range .Hidden}}<input type="hidden" name="{{index . 0}}" value="{{index . 1}}">{{end}}
+        <input type="text" name="q" value="{{.Keyword}}" placeholder="搜索">
+        <button type="submit">搜索</button>
+    </form>
+    {{if .Items}}
+    <div class="fb-items">
+        {{range .Items}}
+        <label class="fb-item">
+            {{if .Thumb}}<img src="{{.Thumb}}" alt="">{{end}}
+            <input type="{{if $.Multiple}}checkbox{{else}}radio{{end}}" name="fb-frame-item" value="{{.ID}}">
+            {{.Label}}
+        </label>
+        {{end}}
+    </div>
+    {{else}}
+    <div class="fb-empty">暂无数据</div>
+    {{end}}
+    <div class="fb-footer">
+        <div>
+            {{if .PrevURL}}<a href="{{.PrevURL}}">上一页</a>{{end}}
+            <span>{{.Page}} / {{.Pages}}</span>
+            {{if .NextURL}}<a href="{{.NextURL}}">下一页</a>{{end}}
+        </div>
+        {{if .Multiple}}<button type="button" id="fb-frame-confirm">确定</button>{{end}}
+    </div>
+    <script>
+    (function() {
+        var config = {{.Config}};
+        var inputs = [].slice.call(document.querySelectorAll('input[name="fb-frame-item"]'));
+        var values = [];
+        var helper = function() { return window.form_create_helper; };
+        var sync = function() {
+            var h = helper();
+            if (!h) { return false; }
+            var value = h.get(config.field);
+            values = value === undefined || value === null || value === '' ? [] : [].concat(value).map(String);
+            inputs.forEach(function(input) { input.checked = values.indexOf(input.value) >= 0; });
+            return true;
+        };
+        var wait = function() { if (!sync()) { setTimeout(wait, 50); } };
+        wait();
+        inputs.forEach(function(input) {
+            input.addEventListener('change', function() {
+                var h = helper();
+                if (!h) { input.checked = false; return; }
+                if (!config.multiple) {
+                    h.set(config.field, input.value);
+                    h.close(config.field);
+                    return;
+                }
+                var i = values.indexOf(input.value);
+                if (input.checked && i < 0) {
+                    if (config.maxLength > 0 && values.length >= config.maxLength) {
+                        input.checked = false;
+                        alert('最多只能选择' + config.maxLength + '项');
+                        return;
+                    }
+                    values.push(input.value);
+                } else if (!input.checked && i >= 0) {
+                    values.splice(i, 1);
+                }
+                h.set(config.field, values.slice());
+            });
+        });
+        var confirm = document.getElementById('fb-frame-confirm');
+        if (confirm) {
+            confirm.addEventListener('click', function() {
+                var h = helper();
+                if (h) { h.close(config.field); }
+            });
+        }
+    })();
+    </script>
+</body>
+</html>`))
@@ -0,0 +1,185 @@
+package formbuilder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frame_picker_test.go 测试Frame组件的选择页面
+
+// testFrameItems 测试用的条目
+var testFrameItems = FrameItems{
+	{ID: "/static/sea.jpg", Label: "海边", Thumb: "/static/sea_thumb.jpg"},
+	{ID: "/static/hill.jpg", Label: "山景"},
+	{ID: "/static/city.jpg", Label: "城市夜景"},
+}
+
+// failingFrameSource 查询总是失败的数据源
+type failingFrameSource struct{}
+
+func (failingFrameSource) Search(ctx context.Context, query FrameQuery) ([]FrameItem, int, error) {
+	return nil, 0, errors.New("db down")
+}
+
+func (failingFrameSource) Lookup(ctx context.Context, ids []string) ([]FrameItem, error) {
+	return nil, errors.New("db down")
+}
+
+// TestFrameItems 测试固定条目数据源
+func TestFrameItems(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Paging", func(t *testing.T) {
+		items, total, err := testFrameItems.Search(ctx, FrameQuery{Page: 2, PageSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, []FrameItem{testFrameItems[2]}, items)
+
+		items, total, _ = testFrameItems.Search(ctx, FrameQuery{Page: 3, PageSize: 2})
+		assert.Equal(t, 3, total)
+		assert.Empty(t, items)
+	})
+
+	t.Run("Keyword", func(t *testing.T) {
+		items, total, _ := testFrameItems.Search(ctx, FrameQuery{Keyword: "夜景", Page: 1, PageSize: 10})
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "/static/city.jpg", items[0].ID)
+
+		_, total, _ = testFrameItems.Search(ctx, FrameQuery{Keyword: "HILL", Page: 1, PageSize: 10})
+		assert.Equal(t, 1, total, "关键字匹配ID且不区分大小写")
+	})
+
+	t.Run("Lookup", func(t *testing.T) {
+		items, err := testFrameItems.Lookup(ctx, []string{"/static/hill.jpg", "/static/none.jpg"})
+		require.NoError(t, err)
+		assert.Equal(t, []FrameItem{testFrameItems[1]}, items)
+	})
+}
+
+// TestFramePickerHandler 测试选择页面
+func TestFramePickerHandler(t *testing.T) {
+	get := func(h http.Handler, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	t.Run("Single", func(t *testing.T) {
+		frame := Elm.FrameImage("cover", "封面", "/frame/cover")
+		rec := get(FramePickerHandler(frame, testFrameItems), "/frame/cover")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+		body := rec.Body.String()
+		assert.Contains(t, body, `<title>封面</title>`)
+		assert.Contains(t, body, `type="radio" name="fb-frame-item" value="/static/sea.jpg"`)
+		assert.Contains(t, body, `<img src="/static/sea_thumb.jpg"`)
+		assert.Contains(t, body, `"field":"cover"`)
+		assert.Contains(t, body, `"multiple":false`)
+		assert.NotContains(t, body, "fb-frame-confirm\"", "单选不显示确定按钮")
+	})
+
+	t.Run("MultiplePaging", func(t *testing.T) {
+		frame := Elm.FrameImages("photos", "相册", "/frame/photos?album=1").MaxLength(5)
+		h := FramePickerHandler(frame, testFrameItems, FramePickerOptions{PageSize: 2, Title: "选择图片"})
+		body := get(h, "/frame/photos?album=1&page=2").Body.String()
+
+		assert.Contains(t, body, `<title>选择图片</title>`)
+		assert.Contains(t, body, `type="checkbox" name="fb-frame-item" value="/static/city.jpg"`)
+		assert.NotContains(t, body, "/static/sea.jpg")
+		assert.Contains(t, body, `"maxLength":5`)
+		assert.Contains(t, body, `"multiple":true`)
+		assert.Contains(t, body, `id="fb-frame-confirm"`)
+		assert.Contains(t, body, `2 / 2`)
+		assert.Contains(t, body, `href="?album=1&amp;page=1"`)
+		assert.NotContains(t, body, "下一页")
+		assert.Contains(t, body, `<input type="hidden" name="album" value="1">`, "搜索时保留src中的参数")
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		frame := Elm.FrameImages("photos", "相册", "/frame/photos")
+		body := get(FramePickerHandler(frame, testFrameItems), "/frame/photos?q=%3Cb%3E").Body.String()
+		assert.Contains(t, body, "暂无数据")
+		assert.Contains(t, body, `value="&lt;b&gt;"`, "关键字需要转义")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		frame := Elm.FrameImages("photos", "相册", "/frame/photos")
+		assert.Equal(t, http.StatusInternalServerError, get(FramePickerHandler(frame, failingFrameSource{}), "/frame/photos").Code)
+
+		rec := httptest.NewRecorder()
+		FramePickerHandler(frame, testFrameItems).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/frame/photos", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+// TestFramePickerMount 测试选择页面的挂载
+func TestFramePickerMount(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		Elm.FrameImages("photos", "相册", "/frame/photos?album=1").Picker(testFrameItems),
+		Elm.FrameFile("file", "文件", "https://cdn.example.com/picker").Picker(testFrameItems),
+		Elm.FrameFile("doc", "文档", "/frame/doc"),
+	}, nil)
+
+	endpoints := form.Endpoints()
+	assert.Len(t, endpoints, 1, "外站地址和未设置数据源的组件不注册")
+	assert.Contains(t, endpoints, "/frame/photos")
+
+	mux := http.NewServeMux()
+	require.NoError(t, form.Mount(mux))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/frame/photos?album=1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/static/hill.jpg")
+}
+
+// TestFramePickerValidate 测试提交值必须来自数据源
+func TestFramePickerValidate(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		Elm.FrameImage("cover", "封面", "/frame/cover").Picker(testFrameItems),
+		Elm.FrameImages("photos", "相册", "/frame/photos").MaxLength(2).Picker(testFrameItems),
+		Elm.FrameInput("note", "备注", "/frame/note"),
+	}, nil)
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, form.ValidateData(map[string]interface{}{
+			"cover":  "/static/sea.jpg",
+			"photos": []interface{}{"/static/hill.jpg", "/static/city.jpg"},
+			"note":   "任意值",
+		}))
+	})
+
+	t.Run("NotInSource", func(t *testing.T) {
+		err := form.ValidateData(map[string]interface{}{
+			"cover":  "/static/evil.jpg",
+			"photos": []interface{}{"/static/hill.jpg", "/static/evil.jpg"},
+		})
+		var errs FieldErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Len(t, errs, 2)
+		assert.ErrorIs(t, err, &FieldError{Field: "photos", Rule: "option"})
+	})
+
+	t.Run("MaxLength", func(t *testing.T) {
+		err := form.ValidateData(map[string]interface{}{
+			"photos": []interface{}{"/static/sea.jpg", "/static/hill.jpg", "/static/city.jpg"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "最多只能选择2项")
+	})
+
+	t.Run("SourceError", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			Elm.FrameImage("cover", "封面", "/frame/cover").Picker(failingFrameSource{}),
+		}, nil)
+		err := form.ValidateData(map[string]interface{}{"cover": "/static/sea.jpg"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), errOptionLookup.Error())
+	})
+}
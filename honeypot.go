@@ -0,0 +1,138 @@
+package formbuilder
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// honeypot.go 实现蜜罐字段和最短填写时间的反垃圾提交
+// 渲染表单时注入一个对用户不可见的输入框和签名的渲染时间，
+// ParseRequest拒绝填写了蜜罐字段、渲染时间无效或填写时间过短的提交。
+// 可以作为验证码之外更轻量的防护，也可以与Captcha同时使用
+
+// DefaultHoneypotField 蜜罐字段的默认字段名
+const DefaultHoneypotField = "fb_homepage"
+
+// RenderTimeField 渲染时间字段名
+const RenderTimeField = "_fb_ts"
+
+// DefaultMinFillTime 默认的最短填写时间
+const DefaultMinFillTime = 3 * time.Second
+
+var (
+	// errSpam 蜜罐字段被填写或渲染时间无效
+	errSpam = errors.New("提交被拒绝，请刷新页面后重试")
+
+	// errTooFast 提交速度过快
+	errTooFast = errors.New("提交速度过快，请稍后重试")
+)
+
+// HoneypotOptions 蜜罐防护配置
+type HoneypotOptions struct {
+	// Key 渲染时间的签名密钥，必填
+	Key []byte
+
+	// Field 蜜罐字段名，默认为DefaultHoneypotField，不能与表单字段重名。
+	// 使用website、homepage等看起来需要填写的名称更容易诱导机器人填写
+	Field string
+
+	// MinFillTime 从渲染到提交的最短时间，默认为DefaultMinFillTime，小于0时不检查
+	MinFillTime time.Duration
+
+	// MaxAge 渲染时间的有效期，超过后需要刷新页面，为0时不限制
+	MaxAge time.Duration
+}
+
+// SetHoneypot 开启蜜罐和最短填写时间防护
+// 开启后FormRule会追加隐藏的蜜罐输入框（display为false，用户不可见）和RenderTimeField隐藏字段；
+// ParseRequest校验提交数据，蜜罐字段不为空、渲染时间缺失或签名无效、填写时间小于MinFillTime时
+// 返回rule为"spam"的FieldErrors，两个字段都不会出现在返回的数据中。Key为空时关闭防护
+//
+// 使用示例：
+//
+//	form.SetHoneypot(formbuilder.HoneypotOptions{
+//	    Key:         []byte(os.Getenv("FORM_SIGN_KEY")),
+//	    Field:       "website",
+//	    MinFillTime: 5 * time.Second,
+//	})
+func (f *Form) SetHoneypot(opts HoneypotOptions) *Form {
+	f.honeypot = opts
+	return f
+}
+
+// honeypotField 返回蜜罐字段名
+func (f *Form) honeypotField() string {
+	return withDefault(f.honeypot.Field, DefaultHoneypotField)
+}
+
+// honeypotRules 返回蜜罐字段和渲染时间字段的规则
+// 蜜罐字段基于Hidden组件构建，类型改为input，使其作为普通输入框出现在页面中
+func (f *Form) honeypotRules() []map[string]interface{} {
+	trap := NewHidden(f.honeypotField(), "")
+	trap.data.RuleType = "input"
+	trap.data.Props["tabindex"] = "-1"
+	trap.data.Props["autocomplete"] = "off"
+	trap.AppendRule("display", false)
+	return []map[string]interface{}{
+		trap.Build(),
+		NewHidden(RenderTimeField, f.renderTimeToken(time.Now())).Build(),
+	}
+}
+
+// renderTimeToken 生成渲染时间字段的值
+// 格式为 毫秒时间戳 + "." + base64url(HMAC-SHA256)，签名包含表单的提交地址
+func (f *Form) renderTimeToken(t time.Time) string {
+	ts := strconv.FormatInt(t.UnixMilli(), 10)
+	return ts + "." + base64.RawURLEncoding.EncodeToString(f.signRenderTime(ts))
+}
+
+// signRenderTime 计算渲染时间的签名
+func (f *Form) signRenderTime(ts string) []byte {
+	return signHMAC(f.honeypot.Key, []byte("ts:"+f.action+":"+ts))
+}
+
+// verifyHoneypot 校验蜜罐字段和渲染时间
+func (f *Form) verifyHoneypot(values map[string]interface{}, now time.Time) error {
+	if len(f.honeypot.Key) == 0 {
+		return nil
+	}
+	if !isEmptyValue(values[f.honeypotField()]) {
+		return spamError(f.honeypotField(), errSpam)
+	}
+
+	token, _ := values[RenderTimeField].(string)
+	ts, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return spamError(RenderTimeField, errSpam)
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(sum, f.signRenderTime(ts)) {
+		return spamError(RenderTimeField, errSpam)
+	}
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return spamError(RenderTimeField, errSpam)
+	}
+
+	elapsed := now.Sub(time.UnixMilli(ms))
+	minFill := f.honeypot.MinFillTime
+	if minFill == 0 {
+		minFill = DefaultMinFillTime
+	}
+	if elapsed < minFill {
+		return spamError(RenderTimeField, errTooFast)
+	}
+	if f.honeypot.MaxAge > 0 && elapsed > f.honeypot.MaxAge {
+		return spamError(RenderTimeField, errSpam)
+	}
+	return nil
+}
+
+// spamError 返回反垃圾校验失败的FieldErrors
+func spamError(field string, err error) error {
+	return FieldErrors{{Field: field, Rule: "spam", Message: err.Error()}}
+}
@@ -0,0 +1,97 @@
+package formbuilder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// honeypot_test.go 测试蜜罐和最短填写时间防护
+
+// TestFormHoneypot 测试蜜罐字段和渲染时间的注入与校验
+func TestFormHoneypot(t *testing.T) {
+	key := []byte("honeypot-key")
+	newForm := func(opts HoneypotOptions) *Form {
+		opts.Key = key
+		return NewElmForm("/submit", []Component{NewInput("name", "名称")}, nil).SetHoneypot(opts)
+	}
+	post := func(form *Form, values url.Values) (map[string]interface{}, error) {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return ParseRequest(form, req, ParseOptions{Strict: true})
+	}
+
+	t.Run("Render", func(t *testing.T) {
+		rules := newForm(HoneypotOptions{Field: "website"}).FormRule()
+		require.Len(t, rules, 3)
+
+		trap := rules[1]
+		assert.Equal(t, "input", trap["type"])
+		assert.Equal(t, "website", trap["field"])
+		assert.Equal(t, "", trap["value"])
+		assert.Equal(t, false, trap["display"])
+		assert.Equal(t, "-1", trap["props"].(map[string]interface{})["tabindex"])
+
+		stamp := rules[2]
+		assert.Equal(t, "hidden", stamp["type"])
+		assert.Equal(t, RenderTimeField, stamp["field"])
+		assert.Regexp(t, `^\d+\.[\w-]+$`, stamp["value"])
+
+		plain := NewElmForm("/submit", []Component{NewInput("name", "名称")}, nil).SetHoneypot(HoneypotOptions{})
+		assert.Len(t, plain.FormRule(), 1, "没有密钥时不开启")
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		form := newForm(HoneypotOptions{})
+		values, err := post(form, url.Values{
+			"name":               {"a"},
+			DefaultHoneypotField: {""},
+			RenderTimeField:      {form.renderTimeToken(time.Now().Add(-5 * time.Second))},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"name": "a"}, values, "防护字段不出现在返回的数据中")
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		form := newForm(HoneypotOptions{MaxAge: time.Hour})
+		valid := form.renderTimeToken(time.Now().Add(-5 * time.Second))
+		other := NewElmForm("/other", nil, nil).SetHoneypot(HoneypotOptions{Key: key})
+
+		for name, tc := range map[string]struct {
+			values url.Values
+			field  string
+			err    error
+		}{
+			"Filled":    {url.Values{DefaultHoneypotField: {"http://spam"}, RenderTimeField: {valid}}, DefaultHoneypotField, errSpam},
+			"NoStamp":   {url.Values{}, RenderTimeField, errSpam},
+			"Forged":    {url.Values{RenderTimeField: {"1.AAAA"}}, RenderTimeField, errSpam},
+			"OtherForm": {url.Values{RenderTimeField: {other.renderTimeToken(time.Now().Add(-5 * time.Second))}}, RenderTimeField, errSpam},
+			"TooFast":   {url.Values{RenderTimeField: {form.renderTimeToken(time.Now().Add(-time.Second))}}, RenderTimeField, errTooFast},
+			"Expired":   {url.Values{RenderTimeField: {form.renderTimeToken(time.Now().Add(-2 * time.Hour))}}, RenderTimeField, errSpam},
+			"Tampered":  {url.Values{RenderTimeField: {"abc." + strings.SplitN(valid, ".", 2)[1]}}, RenderTimeField, errSpam},
+		} {
+			tc.values.Set("name", "a")
+			values, err := post(form, tc.values)
+			assert.Nil(t, values, name)
+			assert.ErrorIs(t, err, ErrSpam, name)
+			assert.ErrorIs(t, err, &FieldError{Field: tc.field}, name)
+			assert.Contains(t, err.Error(), tc.err.Error(), name)
+		}
+	})
+
+	t.Run("MinFillTime", func(t *testing.T) {
+		form := newForm(HoneypotOptions{MinFillTime: 10 * time.Second})
+		now := time.Now()
+		assert.Error(t, form.verifyHoneypot(map[string]interface{}{RenderTimeField: form.renderTimeToken(now.Add(-5 * time.Second))}, now))
+		assert.NoError(t, form.verifyHoneypot(map[string]interface{}{RenderTimeField: form.renderTimeToken(now.Add(-10 * time.Second))}, now))
+
+		disabled := newForm(HoneypotOptions{MinFillTime: -1})
+		assert.NoError(t, disabled.verifyHoneypot(map[string]interface{}{RenderTimeField: disabled.renderTimeToken(now)}, now))
+	})
+}
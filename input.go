@@ -168,6 +168,27 @@ func (i *Input) ValidateEvent(enable bool) *Input {
 	return i
 }
 
+// defaultSanitizers 按输入框类型返回默认清理器
+// email去除空白并转为小写，tel只保留数字，text/search/url/number去除首尾空白，
+// password和textarea保留原始输入；未设置type时按前端的默认类型text处理
+func (i *Input) defaultSanitizers() []Sanitizer {
+	switch withDefault(propString(i.data.Props, "type"), "text") {
+	case "email":
+		return []Sanitizer{Trim, Lowercase}
+	case "tel":
+		return []Sanitizer{Digits}
+	case "text", "search", "url", "number":
+		return []Sanitizer{Trim}
+	}
+	return nil
+}
+
+// Normalize 实现Normalizer接口
+// 输入框的值统一转换为字符串
+func (i *Input) Normalize(value interface{}) (interface{}, error) {
+	return normalizeString(value)
+}
+
 // 实现Component接口
 
 // GetField 返回字段名
@@ -182,7 +203,13 @@ func (i *Input) GetType() string {
 
 // Build 将组件转换为map，用于JSON序列化
 func (i *Input) Build() map[string]interface{} {
-	return buildComponent(i.data)
+	result := buildComponent(i.data)
+	if _, ok := result["sanitize"]; !ok && i.data.Sanitizers == nil {
+		if defaults := i.defaultSanitizers(); len(defaults) > 0 {
+			result["sanitize"] = sanitizerNames(defaults)
+		}
+	}
+	return result
 }
 
 // 便捷构造函数
@@ -5,6 +5,7 @@ package formbuilder
 // InputNumber 数字输入框组件
 type InputNumber struct {
 	Builder[*InputNumber]
+	decimal bool // Normalize转换为*big.Rat
 }
 
 // NewInputNumber 创建一个新的InputNumber组件
@@ -54,6 +55,13 @@ func (n *InputNumber) Precision(precision int) *InputNumber {
 	return n
 }
 
+// Decimal 设置Normalize是否转换为精确的十进制数（*big.Rat）
+// 用于金额等不能有浮点误差的字段，设置了精度时按精度四舍五入；只影响服务端，不输出到规则
+func (n *InputNumber) Decimal(enable bool) *InputNumber {
+	n.decimal = enable
+	return n
+}
+
 // Controls 设置是否显示控制按钮
 func (n *InputNumber) Controls(enable bool) *InputNumber {
 	n.data.Props["controls"] = enable
@@ -84,6 +92,22 @@ func (n *InputNumber) Size(size string) *InputNumber {
 	return n
 }
 
+// Normalize 实现Normalizer接口
+// 开启Decimal时转换为*big.Rat；否则精度为0时转换为int64，其余转换为float64并按精度四舍五入
+func (n *InputNumber) Normalize(value interface{}) (interface{}, error) {
+	precision, ok := propInt(n.data.Props, "precision")
+	if n.decimal {
+		if !ok {
+			precision = -1
+		}
+		return normalizeDecimal(value, precision)
+	}
+	if ok && precision == 0 {
+		return normalizeInt(value)
+	}
+	return normalizeFloat(value, precision)
+}
+
 // GetField 实现Component接口
 func (n *InputNumber) GetField() string {
 	return n.data.Field
@@ -0,0 +1,454 @@
+package formbuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// normalize.go 实现提交数据的类型规范化
+// JSON解码得到的float64、[]interface{}、各种格式的日期字符串
+// 由各组件转换为确定的Go类型，处理器无需再编写类型断言
+
+// Normalizer 值规范化接口
+// 组件实现此接口，将客户端提交的原始值转换为规范的Go值
+//
+// 各组件的转换结果：
+//   - Input、ColorPicker: string
+//   - InputNumber: 开启Decimal时为*big.Rat，precision为0时为int64，否则为float64
+//   - Switch: 设置了active-value/inactive-value时为对应的值，否则为bool
+//   - Radio、Select: 匹配选项的Value，多选Select和Checkbox为元素是选项Value的[]interface{}
+//   - Tree、Cascader: []string
+//   - DatePicker、TimePicker: time.Time，范围选择时为[2]time.Time
+type Normalizer interface {
+	// Normalize 转换单个字段的值，值无法转换时返回错误
+	Normalize(value interface{}) (interface{}, error)
+}
+
+// Normalize 按组件类型规范化提交数据
+// 返回新的map，只转换当前显示且实现了Normalizer的字段，其余值原样保留；
+// 转换失败时返回FieldErrors
+//
+// 使用示例：
+//
+//	values, err := form.Normalize(values)
+//	price := values["price"].(float64)
+//	period := values["period"].([2]time.Time)
+func (f *Form) Normalize(values map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+
+	var errs FieldErrors
+	f.eachActiveComponent(f.rules, values, func(c Component, data *ComponentData) {
+		normalizer, ok := c.(Normalizer)
+		if !ok || data.Field == "" {
+			return
+		}
+		value, exists := values[data.Field]
+		if !exists {
+			return
+		}
+		normalized, err := normalizer.Normalize(value)
+		if err != nil {
+			errs = append(errs, &FieldError{Field: data.Field, Rule: "type", Message: err.Error()})
+			return
+		}
+		result[data.Field] = normalized
+	})
+
+	if len(errs) > 0 {
+		return result, errs
+	}
+	return result, nil
+}
+
+// 规范化失败的错误信息
+var (
+	errNotString = errors.New("请输入文本")
+	errNotNumber = errors.New("请输入数字")
+	errNotInt    = errors.New("请输入整数")
+	errNotBool   = errors.New("开关值不正确")
+	errNotList   = errors.New("请选择正确的选项")
+	errNotDate   = errors.New("请输入正确的日期")
+	errNotRange  = errors.New("请选择正确的范围")
+)
+
+// normalizeString 转换为字符串
+// 数字按十进制格式输出
+func normalizeString(value interface{}) (interface{}, error) {
+	if value == nil {
+		return "", nil
+	}
+	if s, ok := stringValue(value); ok {
+		return s, nil
+	}
+	return nil, errNotString
+}
+
+// normalizeFloat 转换为float64
+// precision大于0时按精度四舍五入，空值转换为nil
+func normalizeFloat(value interface{}, precision int) (interface{}, error) {
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+	f, ok := toFloat(value)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errNotNumber
+	}
+	if precision > 0 {
+		pow := math.Pow(10, float64(precision))
+		f = math.Round(f*pow) / pow
+	}
+	return f, nil
+}
+
+// normalizeDecimal 转换为*big.Rat
+// 按十进制文本解析，不经过float64，precision不小于0时按精度四舍五入（0.5远离零），空值转换为nil
+func normalizeDecimal(value interface{}, precision int) (interface{}, error) {
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+	s, ok := stringValue(value)
+	s = strings.TrimSpace(s)
+	if !ok || strings.Contains(s, "/") {
+		return nil, errNotNumber
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errNotNumber
+	}
+	if precision >= 0 {
+		r.SetString(r.FloatString(precision))
+	}
+	return r, nil
+}
+
+// decimalString 将*big.Rat输出为十进制文本
+// 分母只含因子2和5时（十进制文本解析得到的值）输出精确的小数，否则保留20位小数
+func decimalString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	denom := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, p := range []int64{2, 5} {
+		n, factor, mod := 0, big.NewInt(p), new(big.Int)
+		for {
+			q, m := new(big.Int).QuoRem(denom, factor, mod)
+			if m.Sign() != 0 {
+				break
+			}
+			denom, n = q, n+1
+		}
+		digits = max(digits, n)
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		digits = 20
+	}
+	return r.FloatString(digits)
+}
+
+// normalizeInt 转换为int64
+// 字符串和json.Number按十进制文本解析，不经过float64；带小数的值返回错误，空值转换为nil
+func normalizeInt(value interface{}) (interface{}, error) {
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case json.Number:
+		text = v.String()
+	}
+	if s := strings.TrimSpace(text); s != "" && !strings.Contains(s, "/") {
+		// 按十进制文本解析，超过2^53的整数不经过float64
+		if r, ok := new(big.Rat).SetString(s); ok {
+			if !r.IsInt() || !r.Num().IsInt64() {
+				return nil, errNotInt
+			}
+			return r.Num().Int64(), nil
+		}
+	}
+	f, ok := toFloat(value)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errNotNumber
+	}
+	if f != math.Trunc(f) || f > math.MaxInt64 || f < math.MinInt64 {
+		return nil, errNotInt
+	}
+	return int64(f), nil
+}
+
+// normalizeBool 转换为bool
+// 支持bool、"true"/"false"、"1"/"0"、"on"/"off"以及数字1/0
+func normalizeBool(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "on", "yes":
+			return true, nil
+		case "false", "0", "off", "no", "":
+			return false, nil
+		}
+		return nil, errNotBool
+	}
+	if f, ok := toFloat(value); ok && (f == 0 || f == 1) {
+		return f == 1, nil
+	}
+	return nil, errNotBool
+}
+
+// normalizeStrings 转换为[]string
+func normalizeStrings(value interface{}) (interface{}, error) {
+	list, err := toStrings(value)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// toStrings 将值转换为[]string
+// 单个值视为只有一个元素的数组，空值转换为空数组
+func toStrings(value interface{}) ([]string, error) {
+	if value == nil {
+		return []string{}, nil
+	}
+	if s, ok := value.(string); ok {
+		if s == "" {
+			return []string{}, nil
+		}
+		return []string{s}, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		if s, ok := stringValue(value); ok {
+			return []string{s}, nil
+		}
+		return nil, errNotList
+	}
+
+	result := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		s, ok := stringValue(rv.Index(i).Interface())
+		if !ok {
+			return nil, errNotList
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// normalizeStringOrStrings 单选时转换为string，多选时转换为[]string
+// 用于Upload、Frame等根据数量限制决定值类型的组件
+func normalizeStringOrStrings(value interface{}, single bool) (interface{}, error) {
+	list, err := toStrings(value)
+	if err != nil {
+		return nil, err
+	}
+	if !single {
+		return list, nil
+	}
+	if len(list) == 0 {
+		return "", nil
+	}
+	if len(list) > 1 {
+		return nil, errNotList
+	}
+	return list[0], nil
+}
+
+// normalizeTime 按value-format转换为time.Time，空值转换为nil
+func normalizeTime(value interface{}, format string) (interface{}, error) {
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+	t, ok := parseTimeFormat(value, format)
+	if !ok {
+		return nil, errNotDate
+	}
+	return t, nil
+}
+
+// normalizeTimes 按value-format转换为[]time.Time
+func normalizeTimes(value interface{}, format string) (interface{}, error) {
+	items, ok := sliceItems(value)
+	if !ok {
+		return nil, errNotDate
+	}
+	result := make([]time.Time, 0, len(items))
+	for _, item := range items {
+		t, ok := parseTimeFormat(item, format)
+		if !ok {
+			return nil, errNotDate
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// normalizeTimeRange 按value-format转换为[2]time.Time，空值转换为nil
+func normalizeTimeRange(value interface{}, format string) (interface{}, error) {
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+	items, ok := sliceItems(value)
+	if !ok || len(items) != 2 {
+		return nil, errNotRange
+	}
+	var result [2]time.Time
+	for i, item := range items {
+		t, ok := parseTimeFormat(item, format)
+		if !ok {
+			return nil, errNotDate
+		}
+		result[i] = t
+	}
+	if result[1].Before(result[0]) {
+		return nil, errNotRange
+	}
+	return result, nil
+}
+
+// parseTimeFormat 按Element UI的value-format解析时间
+// timestamp/x为毫秒时间戳，X为秒级时间戳；未设置格式或按格式解析失败时使用parseDateValue
+func parseTimeFormat(value interface{}, format string) (time.Time, bool) {
+	switch format {
+	case "timestamp", "x":
+		if ms, ok := toFloat(value); ok {
+			return time.UnixMilli(int64(ms)), true
+		}
+		return time.Time{}, false
+	case "X":
+		if sec, ok := toFloat(value); ok {
+			return time.Unix(int64(sec), 0), true
+		}
+		return time.Time{}, false
+	}
+
+	if s, ok := value.(string); ok && format != "" {
+		if t, err := time.ParseInLocation(goTimeLayout(format), strings.TrimSpace(s), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return parseDateValue(value)
+}
+
+// timeLayoutReplacer 将Element UI / dayjs的日期格式转换为Go时间格式
+// 较长的标记必须排在前面；单个d在dayjs中表示星期几（0-6），Go没有对应的格式，不做转换，
+// 此时按格式解析失败，改用parseDateValue；ddd、dddd为星期的英文缩写和全称
+var timeLayoutReplacer = strings.NewReplacer(
+	"yyyy", "2006", "YYYY", "2006",
+	"yy", "06", "YY", "06",
+	"MM", "01", "M", "1",
+	"dddd", "Monday", "ddd", "Mon",
+	"dd", "02", "DD", "02", "D", "2",
+	"HH", "15", "H", "15",
+	"hh", "03", "h", "3",
+	"mm", "04", "m", "4",
+	"ss", "05", "s", "5",
+	"SSS", "000",
+	"A", "PM", "a", "pm",
+)
+
+// goTimeLayout 转换日期格式，如 "yyyy-MM-dd HH:mm:ss" 转换为 "2006-01-02 15:04:05"
+func goTimeLayout(format string) string {
+	return timeLayoutReplacer.Replace(format)
+}
+
+// sliceItems 将数组值展开为[]interface{}
+func sliceItems(value interface{}) ([]interface{}, bool) {
+	if value == nil {
+		return []interface{}{}, true
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// matchOption 在选项中查找与值相等的选项
+// 数字与数字字符串视为相等（如urlencoded提交的"1"匹配选项值1）
+func matchOption(options []Option, value interface{}) (Option, bool) {
+	for _, opt := range options {
+		if valuesEqual(opt.Value, value) {
+			return opt, true
+		}
+	}
+	for _, opt := range options {
+		if sameOptionValue(opt.Value, value) {
+			return opt, true
+		}
+	}
+	return Option{}, false
+}
+
+// propString 读取字符串属性
+func propString(props map[string]interface{}, key string) string {
+	s, _ := props[key].(string)
+	return s
+}
+
+// propBool 读取布尔属性
+func propBool(props map[string]interface{}, key string) bool {
+	b, _ := props[key].(bool)
+	return b
+}
+
+// propInt 读取整数属性
+func propInt(props map[string]interface{}, key string) (int, bool) {
+	f, ok := toFloat(props[key])
+	if !ok || isEmptyValue(props[key]) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// normalizeOptionValue 转换为匹配选项的Value
+// 未匹配任何选项时原样返回，选项合法性由验证负责；空值转换为nil，数组返回错误
+func normalizeOptionValue(options []Option, value interface{}) (interface{}, error) {
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+	if isSliceValue(value) {
+		return nil, errMultipleOptions
+	}
+	if opt, ok := matchOption(options, value); ok {
+		return opt.Value, nil
+	}
+	return value, nil
+}
+
+// normalizeOptionValues 将多选的值逐个转换为匹配选项的Value
+// 与单选一致，未匹配任何选项的元素原样保留；单个值视为只有一个元素的数组，空值转换为空数组
+func normalizeOptionValues(options []Option, value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok && s == "" {
+		return []interface{}{}, nil
+	}
+	items, ok := sliceItems(value)
+	if !ok {
+		items = []interface{}{value}
+	}
+	for i, item := range items {
+		if opt, ok := matchOption(options, item); ok {
+			items[i] = opt.Value
+		}
+	}
+	return items, nil
+}
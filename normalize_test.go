@@ -0,0 +1,170 @@
+package formbuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// normalize_test.go 测试提交数据的类型规范化
+
+// TestComponentNormalize 测试各组件的值转换
+func TestComponentNormalize(t *testing.T) {
+	local := func(layout, value string) time.Time {
+		tm, err := time.ParseInLocation(layout, value, time.Local)
+		require.NoError(t, err)
+		return tm
+	}
+
+	cases := []struct {
+		name      string
+		component Normalizer
+		value     interface{}
+		expected  interface{}
+	}{
+		{"InputString", NewInput("name", "名称"), "张三", "张三"},
+		{"InputNumberValue", NewInput("code", "编码"), float64(1001), "1001"},
+		{"InputNumberFloat", NewInputNumber("price", "价格"), "9.9", 9.9},
+		{"InputNumberPrecision", NewInputNumber("price", "价格").Precision(2), 9.999, 10.0},
+		{"InputNumberInt", NewInputNumber("qty", "数量").Precision(0), float64(3), int64(3)},
+		{"InputNumberEmpty", NewInputNumber("qty", "数量"), "", nil},
+		{"SliderRange", NewSlider("range", "区间").Range(true), []interface{}{float64(1), "5"}, [2]float64{1, 5}},
+		{"RateInt", NewRate("rate", "评分"), float64(4), int64(4)},
+		{"RateHalf", NewRate("rate", "评分").AllowHalf(true), 3.5, 3.5},
+		{"SwitchBool", NewSwitch("on", "开关"), "true", true},
+		{"SwitchActiveValue", NewSwitch("status", "状态").ActiveValue(1).InactiveValue(0), float64(1), 1},
+		{"SwitchStringValue", NewSwitch("status", "状态").ActiveValue("Y").InactiveValue("N"), "N", "N"},
+		{"InputNumberDecimal", NewInputNumber("amount", "金额").Decimal(true), "0.10", big.NewRat(1, 10)},
+		{"InputNumberDecimalPrecision", NewInputNumber("amount", "金额").Decimal(true).Precision(2), json.Number("2.345"),
+			big.NewRat(235, 100)},
+		{"InputNumberDecimalFloat", NewInputNumber("amount", "金额").Decimal(true), 0.1, big.NewRat(1, 10)},
+		{"Checkbox", NewCheckbox("tags", "标签"), []interface{}{"a", float64(2)}, []interface{}{"a", float64(2)}},
+		{"CheckboxOptionType", NewCheckbox("tags", "标签").SetOptions([]Option{{Value: 1, Label: "a"}, {Value: 2, Label: "b"}}),
+			[]interface{}{"1", float64(2)}, []interface{}{1, 2}},
+		{"CheckboxEmpty", NewCheckbox("tags", "标签"), nil, []interface{}{}},
+		{"RadioOption", NewRadio("sex", "性别").SetOptions([]Option{{Value: 1, Label: "男"}}), "1", 1},
+		{"SelectMultiple", NewSelect("city", "城市").Multiple(true), []interface{}{"bj"}, []interface{}{"bj"}},
+		{"SelectMultipleOptionType", NewSelect("level", "等级").Multiple(true).SetOptions([]Option{{Value: 1, Label: "一级"}}),
+			"1", []interface{}{1}},
+		{"SelectUnknownOption", NewSelect("city", "城市"), "sh", "sh"},
+		{"Cascader", NewCascader("area", "地区"), []interface{}{"bj", "cy"}, []string{"bj", "cy"}},
+		{"CascaderMultiple", NewCascader("area", "地区").CascaderProps(map[string]interface{}{"multiple": true}),
+			[]interface{}{[]interface{}{"bj", "cy"}}, [][]string{{"bj", "cy"}}},
+		{"Tree", NewTree("menu", "菜单"), []interface{}{float64(1), float64(2)}, []string{"1", "2"}},
+		{"UploadSingle", NewUpload("avatar", "头像").Limit(1), []interface{}{"/a.png"}, "/a.png"},
+		{"UploadMultiple", NewUpload("photos", "相册"), "/a.png", []string{"/a.png"}},
+		{"FrameSingle", Elm.FrameImage("cover", "封面", "/picker"), "/a.png", "/a.png"},
+		{"FrameMultiple", Elm.FrameImages("images", "图片", "/picker"), []interface{}{"/a.png"}, []string{"/a.png"}},
+		{"ColorPicker", NewColorPicker("color", "颜色"), "#409EFF", "#409EFF"},
+		{"DateValueFormat", NewDatePicker("day", "日期").ValueFormat("yyyy/MM/dd"), "2024/05/01",
+			local("2006-01-02", "2024-05-01")},
+		{"DateTimestamp", NewDatePicker("day", "日期").ValueFormat("timestamp"), float64(1714521600000),
+			time.UnixMilli(1714521600000)},
+		{"DateRange", NewDatePicker("period", "周期").DateType("daterange").ValueFormat("yyyy-MM-dd"),
+			[]interface{}{"2024-05-01", "2024-05-07"},
+			[2]time.Time{local("2006-01-02", "2024-05-01"), local("2006-01-02", "2024-05-07")}},
+		{"Dates", NewDatePicker("days", "日期").DateType("dates").ValueFormat("yyyy-MM-dd"),
+			[]interface{}{"2024-05-01"}, []time.Time{local("2006-01-02", "2024-05-01")}},
+		{"DateEmpty", NewDatePicker("day", "日期"), "", nil},
+		{"Time", NewTimePicker("at", "时间").ValueFormat("HH:mm"), "09:30", local("15:04", "09:30")},
+		{"TimeRange", NewTimePicker("hours", "营业时间").IsRange(true),
+			[]interface{}{"09:00:00", "18:00:00"},
+			[2]time.Time{local("15:04:05", "09:00:00"), local("15:04:05", "18:00:00")}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.component.Normalize(tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+// TestComponentNormalizeError 测试无法转换的值
+func TestComponentNormalizeError(t *testing.T) {
+	cases := []struct {
+		name      string
+		component Normalizer
+		value     interface{}
+	}{
+		{"NotNumber", NewInputNumber("price", "价格"), "abc"},
+		{"NotInt", NewInputNumber("qty", "数量").Precision(0), 1.5},
+		{"SwitchUnknown", NewSwitch("status", "状态").ActiveValue(1).InactiveValue(0), float64(2)},
+		{"SwitchNotBool", NewSwitch("on", "开关"), "maybe"},
+		{"InputObject", NewInput("name", "名称"), map[string]interface{}{"a": 1}},
+		{"UploadTooMany", NewUpload("avatar", "头像").Limit(1), []interface{}{"/a.png", "/b.png"}},
+		{"DateInvalid", NewDatePicker("day", "日期").ValueFormat("yyyy-MM-dd"), "tomorrow"},
+		{"RangeLength", NewDatePicker("period", "周期").DateType("daterange"), []interface{}{"2024-05-01"}},
+		{"RangeOrder", NewTimePicker("hours", "营业时间").IsRange(true), []interface{}{"18:00:00", "09:00:00"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.component.Normalize(tc.value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestGoTimeLayout 测试日期格式转换
+func TestGoTimeLayout(t *testing.T) {
+	assert.Equal(t, "2006-01-02 15:04:05", goTimeLayout("yyyy-MM-dd HH:mm:ss"))
+	assert.Equal(t, "2006-01-02", goTimeLayout("YYYY-MM-DD"))
+	assert.Equal(t, "2006年1月2日", goTimeLayout("YYYY年M月D日"))
+	assert.Equal(t, "2006-01-02 d", goTimeLayout("YYYY-MM-DD d"), "dayjs的d表示星期几，不转换为日期")
+	assert.Equal(t, "Mon 2006-01-02", goTimeLayout("ddd YYYY-MM-DD"))
+	assert.Equal(t, "03:04 PM", goTimeLayout("hh:mm A"))
+}
+
+// TestFormNormalize 测试表单数据规范化
+func TestFormNormalize(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		NewInputNumber("qty", "数量").Precision(0),
+		NewSwitch("express", "快递").ActiveValue("1").InactiveValue("0").Control([]ControlRule{
+			{Value: "1", Rule: []Component{
+				NewDatePicker("ship_at", "发货日期").ValueFormat("yyyy-MM-dd"),
+			}},
+		}),
+		NewCheckbox("tags", "标签"),
+	}, nil)
+
+	t.Run("Success", func(t *testing.T) {
+		values := map[string]interface{}{
+			"qty":     float64(2),
+			"express": "1",
+			"ship_at": "2024-05-01",
+			"extra":   "kept",
+		}
+		result, err := form.Normalize(values)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result["qty"])
+		assert.Equal(t, "1", result["express"])
+		assert.IsType(t, time.Time{}, result["ship_at"])
+		assert.Equal(t, "kept", result["extra"])
+		assert.NotContains(t, result, "tags", "未提交的字段不应被添加")
+		assert.Equal(t, float64(2), values["qty"], "不应修改原始数据")
+	})
+
+	t.Run("HiddenBranchSkipped", func(t *testing.T) {
+		result, err := form.Normalize(map[string]interface{}{"express": "0", "ship_at": "bad"})
+		require.NoError(t, err)
+		assert.Equal(t, "bad", result["ship_at"])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := form.Normalize(map[string]interface{}{"qty": "abc", "express": "1", "ship_at": "bad"})
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Equal(t, map[string][]string{
+			"qty":     {"请输入数字"},
+			"ship_at": {"请输入正确的日期"},
+		}, errs.ByField())
+		assert.Equal(t, "type", errs[0].Rule)
+	})
+}
@@ -1,5 +1,10 @@
 package formbuilder
 
+import (
+	"context"
+	"errors"
+)
+
 // option.go 定义选项结构，用于Select、Radio、Checkbox、Cascader等组件
 // 对应PHP的Option类
 
@@ -38,6 +43,9 @@ type Option struct {
 	// Children 子选项（用于级联选择）
 	Children []Option
 
+	// Leaf 是否为叶子节点（用于级联选择懒加载，叶子节点不再加载子选项）
+	Leaf bool
+
 	// Extra 额外的自定义字段
 	// 某些UI组件可能需要额外的字段，如icon、color等
 	Extra map[string]interface{}
@@ -54,6 +62,10 @@ func (o Option) ToMap() map[string]interface{} {
 		m["disabled"] = true
 	}
 
+	if o.Leaf {
+		m["leaf"] = true
+	}
+
 	// 递归处理子选项
 	if len(o.Children) > 0 {
 		children := make([]map[string]interface{}, len(o.Children))
@@ -138,3 +150,140 @@ func NewOptionsFromPairs(pairs [][2]interface{}) []Option {
 	}
 	return options
 }
+
+// optionChecker 选项合法性检查接口
+// 持有选项列表的组件实现此接口，ValidateData会检查提交的值是否为可选的选项，
+// 防止篡改请求提交选项之外的值
+type optionChecker interface {
+	// checkOptions 检查非空的提交值，值不在可选范围内时返回错误
+	// defaults为字段的初始值（FormData/SetValue设置的值优先于组件Value），
+	// 禁用的选项只有在初始值中时才视为有效
+	checkOptions(ctx context.Context, value, defaults interface{}) error
+}
+
+// errInvalidOption 提交值不在可选范围内
+var errInvalidOption = errors.New("所选的值不在可选范围内")
+
+// errMultipleOptions 单选字段提交了多个值
+var errMultipleOptions = errors.New("只能选择一个选项")
+
+// errOptionLookup 查询选项的数据源失败
+var errOptionLookup = errors.New("无法验证所选的值，请稍后重试")
+
+// errUnverifiableOption 组件配置无法在服务端验证提交的值
+var errUnverifiableOption = errors.New("无法验证所选的值")
+
+// checkOptionValues 检查值（或数组中的每个元素）是否为可选的选项
+// 禁用的选项只有在字段初始值中时才视为有效，因为用户无法取消默认选中的禁用项
+func checkOptionValues(options []Option, value, defaults interface{}) error {
+	items, ok := sliceItems(value)
+	if !ok {
+		items = []interface{}{value}
+	}
+	for _, item := range items {
+		opt, ok := matchOption(options, item)
+		if !ok || opt.Disabled && !containsOptionValue(defaults, item) {
+			return errInvalidOption
+		}
+	}
+	return nil
+}
+
+// checkOptionPath 检查级联选择的路径是否存在
+// strict为false时路径必须以叶子节点结束；allowDisabled为true时允许路径经过禁用的节点
+func checkOptionPath(options []Option, path []interface{}, strict, allowDisabled bool) error {
+	level := options
+	for i, item := range path {
+		opt, ok := matchOption(level, item)
+		if !ok || opt.Disabled && !allowDisabled {
+			return errInvalidOption
+		}
+		if i == len(path)-1 && !strict && len(opt.Children) > 0 {
+			return errInvalidOption
+		}
+		level = opt.Children
+	}
+	if len(path) == 0 {
+		return errInvalidOption
+	}
+	return nil
+}
+
+// findOptionNode 在选项树中查找值对应的节点
+// strict为false时只匹配叶子节点；allowDisabled为false时跳过禁用的节点及其子节点
+func findOptionNode(options []Option, value interface{}, strict, allowDisabled bool) bool {
+	for _, opt := range options {
+		if opt.Disabled && !allowDisabled {
+			continue
+		}
+		if (strict || len(opt.Children) == 0) && sameOptionValue(opt.Value, value) {
+			return true
+		}
+		if findOptionNode(opt.Children, value, strict, allowDisabled) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsOptionValue 判断默认值（单个值或数组）是否包含value
+func containsOptionValue(defaults, value interface{}) bool {
+	items, ok := sliceItems(defaults)
+	if !ok {
+		items = []interface{}{defaults}
+	}
+	for _, item := range items {
+		if sameOptionValue(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSelection 判断初始值是否包含级联选择的选中项
+// multiple为true时初始值为选中项数组；选中项可以是路径数组或单个节点值
+func containsSelection(defaults, selection interface{}, multiple bool) bool {
+	candidates := []interface{}{defaults}
+	if multiple {
+		items, ok := sliceItems(defaults)
+		if !ok {
+			return false
+		}
+		candidates = items
+	}
+	for _, candidate := range candidates {
+		a, okA := sliceItems(candidate)
+		b, okB := sliceItems(selection)
+		if !okA || !okB {
+			if !okA && !okB && sameOptionValue(candidate, selection) {
+				return true
+			}
+			continue
+		}
+		if len(a) != len(b) {
+			continue
+		}
+		same := true
+		for i := range a {
+			if !sameOptionValue(a[i], b[i]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOptionValue 判断两个选项值是否相等
+// 数字与数字字符串视为相等（如urlencoded提交的"1"与选项值1）
+func sameOptionValue(a, b interface{}) bool {
+	if valuesEqual(a, b) {
+		return true
+	}
+	sa, okA := stringValue(a)
+	sb, okB := stringValue(b)
+	return okA && okB && sa == sb
+}
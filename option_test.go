@@ -0,0 +1,187 @@
+package formbuilder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// option_test.go 测试选项合法性检查
+
+// createAreaOptions 创建测试用的级联选项
+func createAreaOptions() []Option {
+	return []Option{
+		{Value: "bj", Label: "北京", Children: []Option{
+			{Value: "cy", Label: "朝阳区"},
+			{Value: "hd", Label: "海淀区", Disabled: true},
+		}},
+		{Value: 1, Label: "上海"},
+	}
+}
+
+// TestOptionCheck 测试各组件的选项检查
+func TestOptionCheck(t *testing.T) {
+	cityOptions := []Option{
+		{Value: "bj", Label: "北京"},
+		{Value: "sh", Label: "上海"},
+		{Value: "gz", Label: "广州", Disabled: true},
+		{Value: 1, Label: "数字选项"},
+	}
+
+	cases := []struct {
+		name      string
+		component optionChecker
+		value     interface{}
+		valid     bool
+	}{
+		{"SelectValid", NewSelect("city", "城市").SetOptions(cityOptions), "bj", true},
+		{"SelectInvalid", NewSelect("city", "城市").SetOptions(cityOptions), "sz", false},
+		{"SelectNumericString", NewSelect("city", "城市").SetOptions(cityOptions), "1", true},
+		{"SelectDisabled", NewSelect("city", "城市").SetOptions(cityOptions), "gz", false},
+		{"SelectDisabledDefault", NewSelect("city", "城市", "gz").SetOptions(cityOptions), "gz", true},
+		{"SelectMultiple", NewSelect("city", "城市").SetOptions(cityOptions).Multiple(true), []interface{}{"bj", "sz"}, false},
+		{"SelectAllowCreate", NewSelect("city", "城市").SetOptions(cityOptions).AllowCreate(true), "sz", true},
+		{"SelectRemote", NewSelect("city", "城市").SetOptions(cityOptions).Remote(true), "sz", true},
+		{"SelectNoOptions", NewSelect("city", "城市"), "sz", true},
+		{"RadioValid", NewRadio("sex", "性别").SetOptions([]Option{{Value: 1, Label: "男"}}), float64(1), true},
+		{"RadioInvalid", NewRadio("sex", "性别").SetOptions([]Option{{Value: 1, Label: "男"}}), float64(3), false},
+		{"CheckboxValid", NewCheckbox("city", "城市").SetOptions(cityOptions), []interface{}{"bj", "sh"}, true},
+		{"CheckboxInvalid", NewCheckbox("city", "城市").SetOptions(cityOptions), []interface{}{"bj", "x"}, false},
+		{"CheckboxDisabledDefault", NewCheckbox("city", "城市", []string{"gz"}).SetOptions(cityOptions), []interface{}{"gz"}, true},
+		{"CascaderPath", NewCascader("area", "地区").SetOptions(createAreaOptions()), []interface{}{"bj", "cy"}, true},
+		{"CascaderLeafNumeric", NewCascader("area", "地区").SetOptions(createAreaOptions()), []interface{}{"1"}, true},
+		{"CascaderPartial", NewCascader("area", "地区").SetOptions(createAreaOptions()), []interface{}{"bj"}, false},
+		{"CascaderWrongParent", NewCascader("area", "地区").SetOptions(createAreaOptions()), []interface{}{"sh", "cy"}, false},
+		{"CascaderDisabled", NewCascader("area", "地区").SetOptions(createAreaOptions()), []interface{}{"bj", "hd"}, false},
+		{"CascaderDisabledDefault", NewCascader("area", "地区", []string{"bj", "hd"}).SetOptions(createAreaOptions()), []interface{}{"bj", "hd"}, true},
+		{"CascaderCheckStrictly", NewCascader("area", "地区").SetOptions(createAreaOptions()).
+			CascaderProps(map[string]interface{}{"checkStrictly": true}), []interface{}{"bj"}, true},
+		{"CascaderMultiple", NewCascader("area", "地区").SetOptions(createAreaOptions()).
+			CascaderProps(map[string]interface{}{"multiple": true}),
+			[]interface{}{[]interface{}{"bj", "cy"}, []interface{}{"bj", "xx"}}, false},
+		{"CascaderNoEmitPath", NewCascader("area", "地区").SetOptions(createAreaOptions()).
+			CascaderProps(map[string]interface{}{"emitPath": false}), "cy", true},
+		{"CascaderLazy", NewCascader("area", "地区").SetOptions(createAreaOptions()).
+			CascaderProps(map[string]interface{}{"lazy": true}), []interface{}{"xx"}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defaults := tc.component.(Component).Build()["value"]
+			err := tc.component.checkOptions(context.Background(), tc.value, defaults)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+// TestTreeOptionCheck 测试树形控件的节点检查
+func TestTreeOptionCheck(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": 1, "label": "系统", "children": []map[string]interface{}{
+			{"id": 11, "label": "用户"},
+			{"id": 12, "label": "日志", "disabled": true},
+		}},
+	}
+
+	tree := NewTree("menu", "菜单").Data(data).ShowCheckbox(true)
+	assert.NoError(t, tree.checkOptions(context.Background(), []interface{}{float64(1), "11"}, nil))
+	assert.Error(t, tree.checkOptions(context.Background(), []interface{}{float64(12)}, nil))
+	assert.Error(t, tree.checkOptions(context.Background(), []interface{}{float64(99)}, nil))
+	assert.NoError(t, tree.checkOptions(context.Background(), []interface{}{float64(12)}, []interface{}{12}), "初始值中的禁用节点有效")
+
+	t.Run("CustomKeys", func(t *testing.T) {
+		tree := NewTree("menu", "菜单").NodeKey("code").
+			TreeProps(map[string]interface{}{"children": "items"}).
+			Data([]interface{}{
+				map[string]interface{}{"code": "a", "items": []interface{}{
+					map[string]interface{}{"code": "b"},
+				}},
+			})
+		assert.NoError(t, tree.checkOptions(context.Background(), []interface{}{"b"}, nil))
+		assert.Error(t, tree.checkOptions(context.Background(), []interface{}{"c"}, nil))
+	})
+
+	t.Run("NoData", func(t *testing.T) {
+		assert.NoError(t, NewTree("menu", "菜单").checkOptions(context.Background(), []interface{}{"x"}, nil))
+	})
+
+	t.Run("InvalidData", func(t *testing.T) {
+		tree := NewTree("menu", "菜单").Data([]interface{}{map[string]interface{}{"id": make(chan int)}})
+		assert.Error(t, tree.checkOptions(context.Background(), []interface{}{"x"}, nil), "data无法解析时拒绝提交")
+	})
+}
+
+// TestValidateDataOptions 测试ValidateData中的选项检查
+func TestValidateDataOptions(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		NewSelect("city", "城市").SetOptions([]Option{{Value: "bj", Label: "北京"}}),
+		NewCheckbox("tags", "标签").SetOptions([]Option{{Value: "a", Label: "A"}}),
+	}, nil)
+
+	assert.NoError(t, form.ValidateData(map[string]interface{}{"city": "bj", "tags": []interface{}{}}))
+	assert.NoError(t, form.ValidateData(map[string]interface{}{}), "空值不检查选项")
+
+	err := form.ValidateData(map[string]interface{}{"city": "hacked", "tags": []interface{}{"a", "b"}})
+	var errs FieldErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "option", errs[0].Rule)
+	assert.Equal(t, map[string][]string{
+		"city": {"所选的值不在可选范围内"},
+		"tags": {"所选的值不在可选范围内"},
+	}, errs.ByField())
+}
+
+// TestValidateDataOptionsSingle 测试单选字段拒绝数组
+func TestValidateDataOptionsSingle(t *testing.T) {
+	options := []Option{{Value: "a", Label: "A"}, {Value: 1, Label: "1"}, {Value: 2, Label: "2"}}
+	form := NewElmForm("/submit", []Component{
+		NewRadio("r", "单选").SetOptions(options),
+		NewSelect("s", "下拉").SetOptions(options),
+		NewSelect("free", "可创建").AllowCreate(true),
+		NewSelect("m", "多选").Multiple(true).SetOptions(options),
+	}, nil)
+
+	err := form.ValidateData(map[string]interface{}{
+		"r":    []interface{}{"a", "b"},
+		"s":    []interface{}{float64(1), float64(2)},
+		"free": []interface{}{"x", "y"},
+		"m":    []interface{}{float64(1), float64(2)},
+	})
+	var errs FieldErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, map[string][]string{
+		"r":    {"只能选择一个选项"},
+		"s":    {"只能选择一个选项"},
+		"free": {"只能选择一个选项"},
+	}, errs.ByField())
+
+	_, err = NewRadio("r", "单选").SetOptions(options).Normalize([]interface{}{"a"})
+	assert.ErrorIs(t, err, errMultipleOptions, "Normalize不原样保留数组")
+}
+
+// TestValidateDataOptionsPrefill 测试FormData/SetValue预填的禁用选项
+func TestValidateDataOptionsPrefill(t *testing.T) {
+	newForm := func() *Form {
+		return NewElmForm("/submit", []Component{
+			NewRadio("level", "等级").SetOptions([]Option{
+				{Value: "normal", Label: "普通"},
+				{Value: "vip", Label: "VIP", Disabled: true},
+			}),
+			NewCascader("area", "地区").SetOptions(createAreaOptions()),
+		}, nil)
+	}
+	values := map[string]interface{}{"level": "vip", "area": []interface{}{"bj", "hd"}}
+
+	assert.Error(t, newForm().ValidateData(values))
+
+	form := newForm().FormData(map[string]interface{}{"level": "vip"}).SetValue("area", []interface{}{"bj", "hd"})
+	assert.NoError(t, form.ValidateData(values))
+}
@@ -0,0 +1,339 @@
+package formbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// parse.go 实现HTTP请求数据的解析
+// form-create提交JSON，View()生成的页面和传统表单可能提交urlencoded或multipart数据，
+// 根据组件树将不同编码的数据整理为统一格式的值
+
+// DefaultMaxMemory multipart请求默认的内存上限
+const DefaultMaxMemory = 32 << 20
+
+// DefaultMaxBytes 请求体默认的大小上限
+const DefaultMaxBytes = 32 << 20
+
+// ParseOptions 请求解析选项
+type ParseOptions struct {
+	// Strict 严格模式，提交了表单中未声明的字段时返回FieldErrors；
+	// 非严格模式下未声明的字段被丢弃
+	Strict bool
+
+	// MaxMemory multipart请求保存在内存中的最大字节数，超出部分写入临时文件
+	// 为0时使用DefaultMaxMemory
+	MaxMemory int64
+
+	// MaxBytes 请求体的最大字节数，超出时返回错误
+	// 为0时使用DefaultMaxBytes，小于0时不限制
+	MaxBytes int64
+
+	// KeepHidden 保留处于未激活control分支中的字段的值
+	// 默认这些值会被丢弃（见Form.StripHidden），防止客户端向隐藏字段提交数据
+	KeepHidden bool
+}
+
+// valueKind 字段值的类型
+type valueKind int
+
+const (
+	kindScalar  valueKind = iota // 单个值
+	kindNumber                   // 数字
+	kindArray                    // 数组
+	kindNumbers                  // 数字数组
+	kindDecimal                  // 保留十进制文本的数字
+)
+
+// ParseRequest 解析请求数据
+// 支持application/json、application/x-www-form-urlencoded和multipart/form-data，
+// 根据组件类型整理数据：
+//   - 数组字段（Checkbox、多选Select、FrameImages、UploadImages等）总是返回[]interface{}，
+//     urlencoded中的重复字段和"field[]"形式的字段名会被合并
+//   - 数字字段（InputNumber、Slider、Rate）返回float64；开启Decimal或精度为0的InputNumber
+//     返回json.Number，保留提交的十进制文本，由Normalize无损转换为*big.Rat或int64；
+//     JSON中其他字段的数字解码为json.Number，不丢失大整数（如ID）的精度
+//   - multipart上传的文件返回[]*multipart.FileHeader
+//
+// 数据会经过组件声明的清理器（见Form.Sanitize），签名等系统字段会被保留，
+// 处于未激活control分支中的字段的值默认被丢弃（见ParseOptions.KeepHidden），
+// 返回的数据可直接用于ValidateData、Normalize和Bind。
+// 开启了CSRF防护（见Form.SetCSRF）时先校验令牌，令牌无效时返回rule为"csrf"的FieldErrors，
+// 令牌字段不会出现在返回的数据中。
+// 开启了蜜罐防护（见Form.SetHoneypot）时拒绝蜜罐字段被填写或填写时间过短的提交，返回rule为"spam"的FieldErrors
+//
+// 使用示例：
+//
+//	values, err := formbuilder.ParseRequest(form, r, formbuilder.ParseOptions{Strict: true})
+//	if err != nil {
+//	    // 请求格式错误，或严格模式下的未知字段（FieldErrors）
+//	}
+//	err = form.ValidateData(values)
+func ParseRequest(form *Form, r *http.Request, opts ...ParseOptions) (map[string]interface{}, error) {
+	var opt ParseOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxMemory <= 0 {
+		opt.MaxMemory = DefaultMaxMemory
+	}
+	if opt.MaxBytes == 0 {
+		opt.MaxBytes = DefaultMaxBytes
+	}
+	if opt.MaxBytes > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, opt.MaxBytes)
+	}
+
+	raw, files, err := readRequest(r, opt.MaxMemory)
+	if err != nil {
+		return nil, err
+	}
+	if err := form.verifyCSRF(r, raw); err != nil {
+		return nil, err
+	}
+	delete(raw, CSRFField)
+	if err := form.verifyHoneypot(raw, time.Now()); err != nil {
+		return nil, err
+	}
+	delete(raw, RenderTimeField)
+	if len(form.honeypot.Key) > 0 {
+		delete(raw, form.honeypotField())
+	}
+
+	kinds := make(map[string]valueKind)
+	form.eachComponent(form.rules, func(c Component, data *ComponentData) {
+		if data.Field != "" {
+			kinds[data.Field] = fieldKind(c, data)
+		}
+	})
+
+	system := make(map[string]bool)
+	for _, field := range form.systemFields() {
+		system[field] = true
+	}
+
+	values := make(map[string]interface{}, len(raw))
+	var errs FieldErrors
+	for key, value := range raw {
+		if system[key] {
+			values[key], _ = shapeValue(kindScalar, value)
+			continue
+		}
+		kind, declared := kinds[key]
+		if !declared {
+			if opt.Strict {
+				errs = append(errs, &FieldError{Field: key, Rule: "unknown", Message: "不允许提交此字段"})
+			}
+			continue
+		}
+		shaped, ok := shapeValue(kind, value)
+		if !ok {
+			errs = append(errs, &FieldError{Field: key, Rule: "type", Message: errNotNumber.Error()})
+			continue
+		}
+		values[key] = shaped
+	}
+	for key, headers := range files {
+		if _, declared := kinds[key]; !declared {
+			if opt.Strict {
+				errs = append(errs, &FieldError{Field: key, Rule: "unknown", Message: "不允许提交此字段"})
+			}
+			continue
+		}
+		values[key] = headers
+	}
+
+	values = form.Sanitize(values)
+	if !opt.KeepHidden {
+		values = form.StripHidden(values)
+	}
+	if len(errs) > 0 {
+		return values, errs
+	}
+	return values, nil
+}
+
+// readRequest 按Content-Type读取请求数据
+func readRequest(r *http.Request, maxMemory int64) (map[string]interface{}, map[string][]*multipart.FileHeader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "application/json":
+		values := make(map[string]interface{})
+		dec := json.NewDecoder(r.Body)
+		dec.UseNumber()
+		if err := dec.Decode(&values); err != nil {
+			return nil, nil, fmt.Errorf("invalid json body: %w", err)
+		}
+		return values, nil, nil
+
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+		files := make(map[string][]*multipart.FileHeader, len(r.MultipartForm.File))
+		for key, headers := range r.MultipartForm.File {
+			key = strings.TrimSuffix(key, "[]")
+			files[key] = append(files[key], headers...)
+		}
+		return formValues(r.MultipartForm.Value), files, nil
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, fmt.Errorf("invalid form body: %w", err)
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return formValues(r.Form), nil, nil
+	}
+	return formValues(r.PostForm), nil, nil
+}
+
+// formValues 将url.Values转换为值map
+// 单个值为string，重复字段或"field[]"形式的字段为[]string
+func formValues(form url.Values) map[string]interface{} {
+	lists := make(map[string][]string, len(form))
+	multi := make(map[string]bool)
+	for key, list := range form {
+		name := strings.TrimSuffix(key, "[]")
+		lists[name] = append(lists[name], list...)
+		if name != key {
+			multi[name] = true
+		}
+	}
+
+	values := make(map[string]interface{}, len(lists))
+	for name, list := range lists {
+		if len(list) == 1 && !multi[name] {
+			values[name] = list[0]
+		} else {
+			values[name] = list
+		}
+	}
+	return values
+}
+
+// fieldKind 根据组件类型和属性判断字段值的类型
+func fieldKind(c Component, data *ComponentData) valueKind {
+	props := data.Props
+	switch n := c.(type) {
+	case *InputNumber:
+		if precision, ok := propInt(props, "precision"); n.decimal || ok && precision == 0 {
+			return kindDecimal
+		}
+		return kindNumber
+	case *Rate:
+		return kindNumber
+	case *Slider:
+		if propBool(props, "range") {
+			return kindNumbers
+		}
+		return kindNumber
+	case *Checkbox, *Tree, *Cascader:
+		return kindArray
+	case *Select:
+		if propBool(props, "multiple") {
+			return kindArray
+		}
+	case *Upload:
+		if limit, _ := propInt(props, "limit"); limit != 1 {
+			return kindArray
+		}
+	case *Frame:
+		if maxLength, _ := propInt(props, "maxLength"); maxLength != 1 {
+			return kindArray
+		}
+	case *DatePicker:
+		dtype := propString(props, "type")
+		if strings.HasSuffix(dtype, "range") || dtype == "dates" {
+			return kindArray
+		}
+	case *TimePicker:
+		if propBool(props, "is-range") {
+			return kindArray
+		}
+	}
+	return kindScalar
+}
+
+// shapeValue 按字段类型整理值
+// 数字字段中无法解析为数字的字符串返回false
+func shapeValue(kind valueKind, value interface{}) (interface{}, bool) {
+	if list, ok := value.([]string); ok && len(list) > 0 && kind != kindArray && kind != kindNumbers {
+		// 表单提交了多个同名字段时取第一个
+		value = list[0]
+	}
+
+	switch kind {
+	case kindNumber:
+		return shapeNumber(value)
+	case kindDecimal:
+		return shapeDecimal(value)
+	case kindArray, kindNumbers:
+		items, ok := sliceItems(value)
+		if !ok {
+			items = []interface{}{value}
+		}
+		if s, ok := value.(string); ok && s == "" {
+			items = []interface{}{}
+		}
+		if kind == kindNumbers {
+			for i, item := range items {
+				n, ok := shapeNumber(item)
+				if !ok {
+					return nil, false
+				}
+				items[i] = n
+			}
+		}
+		return items, true
+	}
+	return value, true
+}
+
+// shapeDecimal 将数字字符串转换为json.Number，空字符串转换为nil
+// 不经过float64，超过2^53的整数和高精度小数不丢失精度
+func shapeDecimal(value interface{}) (interface{}, bool) {
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = strings.TrimSpace(v)
+		if s == "" {
+			return nil, true
+		}
+	default:
+		return value, true
+	}
+	if _, ok := new(big.Rat).SetString(s); !ok || strings.Contains(s, "/") {
+		return nil, false
+	}
+	return json.Number(s), true
+}
+
+// shapeNumber 将数字字符串和json.Number转换为float64，空字符串转换为nil
+func shapeNumber(value interface{}) (interface{}, bool) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return value, true
+	}
+	if strings.TrimSpace(s) == "" {
+		return nil, true
+	}
+	f, ok := toFloat(s)
+	if !ok {
+		return nil, false
+	}
+	return f, true
+}
@@ -0,0 +1,732 @@
+package formbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// parse_rules.go 实现form-create规则JSON到组件的还原
+// 组件按type在注册表中查找构造函数，field、title、props、validate、control、children等
+// 写回ComponentData，选项写回Select、Radio、Checkbox、Cascader的选项列表；
+// 无法对应到结构化字段的内容保存在AppendRule中，保证 Build -> ParseRules -> Build 的输出不变
+
+// RuleTypeFactory 创建指定类型的空组件
+// ParseRules随后将规则中的数据写入组件，返回的组件需要嵌入Builder
+type RuleTypeFactory func() Component
+
+// ruleTypeRegistry 已注册的组件类型
+var ruleTypeRegistry = struct {
+	sync.RWMutex
+	factories map[string]RuleTypeFactory
+}{factories: map[string]RuleTypeFactory{
+	"input":       func() Component { return NewInput("", "") },
+	"inputNumber": func() Component { return NewInputNumber("", "") },
+	"select":      func() Component { return NewSelect("", "") },
+	"radio":       func() Component { return NewRadio("", "") },
+	"checkbox":    func() Component { return NewCheckbox("", "") },
+	"datePicker":  func() Component { return NewDatePicker("", "") },
+	"timePicker":  func() Component { return NewTimePicker("", "") },
+	"slider":      func() Component { return NewSlider("", "") },
+	"switch":      func() Component { return NewSwitch("", "") },
+	"upload":      func() Component { return NewUpload("", "") },
+	"cascader":    func() Component { return NewCascader("", "") },
+	"tree":        func() Component { return NewTree("", "") },
+	"rate":        func() Component { return NewRate("", "") },
+	"colorPicker": func() Component { return NewColorPicker("", "") },
+	"frame":       func() Component { return NewFrame("", "", "") },
+	"hidden":      func() Component { return NewHidden("") },
+}}
+
+// RegisterRuleType 注册组件类型，ParseRules遇到该类型时使用factory创建组件
+// 相同类型重复注册时覆盖之前的构造函数；未注册的类型还原为Element。
+// 组件的Build不能总是输出规则中没有的键（AppendRule只能覆盖键，无法删除），
+// 否则ParseRules无法保持输出不变，返回错误
+//
+// 使用示例：
+//
+//	formbuilder.RegisterRuleType("richEditor", func() formbuilder.Component {
+//	    return NewRichEditor("", "")
+//	})
+func RegisterRuleType(ruleType string, factory RuleTypeFactory) {
+	ruleTypeRegistry.Lock()
+	defer ruleTypeRegistry.Unlock()
+	ruleTypeRegistry.factories[ruleType] = factory
+}
+
+// lookupRuleType 查找组件类型的构造函数
+func lookupRuleType(ruleType string) (RuleTypeFactory, bool) {
+	ruleTypeRegistry.RLock()
+	defer ruleTypeRegistry.RUnlock()
+	factory, ok := ruleTypeRegistry.factories[ruleType]
+	return factory, ok
+}
+
+// optionLoader 从规则中还原选项的组件
+// 读取成功时从rest中删除已处理的键
+type optionLoader interface {
+	loadOptions(rest map[string]interface{})
+}
+
+// ruleLoader 需要从规则中还原自身数据的组件
+// loadRule在通用字段写回后调用，从rest中删除已处理的键，
+// 返回每次生成都会变化的键（如验证码的挑战ID），这些键不参与还原后的比较
+type ruleLoader interface {
+	loadRule(rest map[string]interface{}) []string
+}
+
+// ParseRulesOptions 规则还原选项
+type ParseRulesOptions struct {
+	// AllowJS 将无法识别为内置规则的验证规则中的validator字符串还原为JSFunc，FormScript将其作为函数输出。
+	// 只有规则JSON完全由开发者控制时才能开启；默认作为普通字符串输出，
+	// 避免数据库或设计器中保存的规则在页面中执行任意脚本
+	AllowJS bool
+}
+
+// ParseRules 将form-create规则JSON还原为组件
+// 支持FormRule、ParseFormRule和Component.Build生成的规则，也支持手写或数据库中保存的规则。
+// 验证规则能还原为内置规则时同样在服务端执行，包含内置规则不输出的键（如type: "string"）时
+// 按其中的内置规则验证，其余键原样输出；required、pattern、min等可以在服务端验证的键
+// 无法还原为内置规则时返回错误，还原后的表单不会比规则JSON验证得更少。
+// 只有validator等无法在服务端验证的键的规则还原为CustomRule，只在前端生效，
+// 其中的validator字符串只有开启AllowJS时才作为函数输出。
+// 验证码组件还原为没有校验器的Captcha，校验总是失败，需要通过Captcha.Verifier重新设置。
+// 还原后的组件生成了规则中没有的键时返回错误，见RegisterRuleType
+//
+// 使用示例：
+//
+//	rules, err := formbuilder.ParseRules([]byte(saved))
+//	if err != nil {
+//	    return err
+//	}
+//	rules[0].(*formbuilder.Input).Placeholder("请输入新的用户名")
+func ParseRules(data []byte, opts ...ParseRulesOptions) ([]Component, error) {
+	var raw interface{}
+	if err := decodeJSON(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析规则失败: %w", err)
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("解析规则失败: 规则必须是数组")
+	}
+	return parseRuleList(list, "rules", parseRulesOptions(opts))
+}
+
+// ParseRule 将单个规则还原为组件
+func ParseRule(rule map[string]interface{}, opts ...ParseRulesOptions) (Component, error) {
+	var normalized map[string]interface{}
+	if err := remarshalJSON(rule, &normalized); err != nil {
+		return nil, fmt.Errorf("解析规则失败: %w", err)
+	}
+	return parseRule(normalized, "rule", parseRulesOptions(opts))
+}
+
+// parseRulesOptions 返回第一个选项，未传入时返回零值
+func parseRulesOptions(opts []ParseRulesOptions) ParseRulesOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return ParseRulesOptions{}
+}
+
+// LoadFormRule 从规则JSON加载表单的组件，替换原有组件
+// 签名、CSRF令牌、蜜罐等FormRule自动注入的系统字段会被忽略，渲染时重新生成
+//
+// 使用示例：
+//
+//	form := formbuilder.NewElmForm("/user/save", nil, nil)
+//	if err := form.LoadFormRule([]byte(saved)); err != nil {
+//	    return err
+//	}
+func (f *Form) LoadFormRule(data []byte, opts ...ParseRulesOptions) error {
+	rules, err := ParseRules(data, opts...)
+	if err != nil {
+		return err
+	}
+	return f.loadRules(rules)
+}
+
+// loadRules 去掉系统字段后替换表单的组件，字段重复时保留原有组件并返回错误
+func (f *Form) loadRules(rules []Component) error {
+	loaded := make([]Component, 0, len(rules))
+	for _, rule := range rules {
+		if !f.isSystemRule(rule) {
+			loaded = append(loaded, rule)
+		}
+	}
+
+	previous := f.rules
+	f.rules = loaded
+	if err := f.checkFieldUnique(); err != nil {
+		f.rules = previous
+		return err
+	}
+	return nil
+}
+
+// isSystemRule 判断组件是否为FormRule注入的系统字段，见systemFields
+// 规则中验证码的挑战ID字段按CaptchaIDSuffix后缀识别
+func (f *Form) isSystemRule(c Component) bool {
+	field := c.GetField()
+	if c.GetType() == "hidden" && strings.HasSuffix(field, CaptchaIDSuffix) {
+		return true
+	}
+	return slices.Contains(f.systemFields(), field)
+}
+
+// parseRuleList 还原规则数组，path用于错误信息
+func parseRuleList(list []interface{}, path string, opts ParseRulesOptions) ([]Component, error) {
+	components := make([]Component, 0, len(list))
+	for i, item := range list {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: 规则必须是对象", itemPath)
+		}
+		c, err := parseRule(rule, itemPath, opts)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// parseRule 还原单个规则
+// 先按结构化字段写回数据，再与重新生成的规则逐项比较，不一致的键原样保存在AppendRule中
+func parseRule(rule map[string]interface{}, path string, opts ParseRulesOptions) (Component, error) {
+	ruleType, _ := rule["type"].(string)
+	if ruleType == "" {
+		return nil, fmt.Errorf("%s: 缺少组件类型", path)
+	}
+
+	var c Component = NewElement(ruleType)
+	if factory, ok := lookupRuleType(ruleType); ok {
+		c = factory()
+	}
+	if isCaptchaRule(rule) {
+		c = NewCaptcha("", "", nil)
+	}
+	dg, ok := c.(interface{ GetData() *ComponentData })
+	if !ok {
+		return nil, fmt.Errorf("%s: 组件类型%s不支持从规则还原", path, ruleType)
+	}
+	data := dg.GetData()
+	*data = ComponentData{RuleType: ruleType, Props: make(map[string]interface{})}
+
+	rest := make(map[string]interface{}, len(rule))
+	for k, v := range rule {
+		rest[k] = v
+	}
+	delete(rest, "type")
+
+	if field, ok := rest["field"].(string); ok && field != "" {
+		data.Field = field
+		delete(rest, "field")
+	}
+	if title, ok := rest["title"].(string); ok && title != "" {
+		data.Title = title
+		delete(rest, "title")
+	}
+	if value, ok := rest["value"]; ok && value != nil {
+		data.Value = value
+		delete(rest, "value")
+	}
+	if props, ok := rest["props"].(map[string]interface{}); ok && len(props) > 0 {
+		for k, v := range props {
+			data.Props[k] = v
+		}
+		delete(rest, "props")
+	}
+	if emit, ok := rest["emit"].(map[string]interface{}); ok && len(emit) > 0 {
+		data.Emit = emit
+		delete(rest, "emit")
+	}
+	validate, ok, err := parseValidateList(rest["validate"], path+".validate", opts)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		data.Validate = validate
+		delete(rest, "validate")
+	}
+	if sanitizers, ok := parseSanitizers(rest["sanitize"]); ok {
+		data.Sanitizers = sanitizers
+		delete(rest, "sanitize")
+	} else if _, ok := rest["sanitize"]; !ok {
+		// 规则中没有sanitize时不使用组件的默认清理器，与规则保持一致
+		if d, ok := c.(sanitizerDefaulter); ok && len(d.defaultSanitizers()) > 0 {
+			data.Sanitizers = []Sanitizer{}
+		}
+	}
+	if children, ok := rest["children"].([]interface{}); ok && len(children) > 0 && allObjects(children) {
+		parsed, err := parseRuleList(children, path+".children", opts)
+		if err != nil {
+			return nil, err
+		}
+		data.Children = parsed
+		delete(rest, "children")
+	}
+	if control, ok := rest["control"].([]interface{}); ok && len(control) > 0 {
+		parsed, ok, err := parseControlList(control, path+".control", opts)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			data.Control = parsed
+			delete(rest, "control")
+		}
+	}
+	if loader, ok := c.(optionLoader); ok {
+		loader.loadOptions(rest)
+	}
+	var generated []string
+	if loader, ok := c.(ruleLoader); ok {
+		generated = loader.loadRule(rest)
+	}
+
+	if len(rest) > 0 {
+		data.AppendRule = rest
+	}
+
+	// 逐项比较，结构化还原后输出不同的键以原始值覆盖
+	built := c.Build()
+	for k, v := range rule {
+		if slices.Contains(generated, k) {
+			continue
+		}
+		if out, ok := built[k]; !ok || !jsonEqual(out, v) {
+			if data.AppendRule == nil {
+				data.AppendRule = make(map[string]interface{})
+			}
+			data.AppendRule[k] = v
+		}
+	}
+	// 多出的键无法通过AppendRule删除，还原结果与规则不一致，返回错误而不是静默改变规则
+	var extra []string
+	for k := range c.Build() {
+		if _, ok := rule[k]; !ok {
+			extra = append(extra, k)
+		}
+	}
+	if len(extra) > 0 {
+		sort.Strings(extra)
+		return nil, fmt.Errorf("%s: 无法还原组件类型%s，生成的规则多出%s", path, ruleType, strings.Join(extra, ", "))
+	}
+	return c, nil
+}
+
+// isCaptchaRule 判断规则是否为Captcha生成的验证码输入框，按验证规则中记录的规则名称识别
+func isCaptchaRule(rule map[string]interface{}) bool {
+	list, _ := rule["validate"].([]interface{})
+	for _, item := range list {
+		m, _ := item.(map[string]interface{})
+		if data, ok := m[ruleDataKey].(map[string]interface{}); ok && data["name"] == "captcha" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseControlList 还原control规则
+// 包含value和rule以外的键时返回false，由调用方原样保留
+func parseControlList(list []interface{}, path string, opts ParseRulesOptions) ([]ControlRule, bool, error) {
+	controls := make([]ControlRule, 0, len(list))
+	for i, item := range list {
+		ctrl, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false, nil
+		}
+		rules, ok := ctrl["rule"].([]interface{})
+		if !ok || !allObjects(rules) {
+			return nil, false, nil
+		}
+		for k := range ctrl {
+			if k != "value" && k != "rule" {
+				return nil, false, nil
+			}
+		}
+		parsed, err := parseRuleList(rules, fmt.Sprintf("%s[%d].rule", path, i), opts)
+		if err != nil {
+			return nil, false, err
+		}
+		controls = append(controls, ControlRule{Value: ctrl["value"], Rule: parsed})
+	}
+	return controls, true, nil
+}
+
+// builtinSanitizers 按名称查找内置清理器
+var builtinSanitizers = map[string]Sanitizer{
+	Trim.Name:           Trim,
+	StripTags.Name:      StripTags,
+	CollapseSpaces.Name: CollapseSpaces,
+	Lowercase.Name:      Lowercase,
+	Digits.Name:         Digits,
+}
+
+// parseSanitizers 按名称还原清理器，包含非内置清理器时返回false
+func parseSanitizers(v interface{}) ([]Sanitizer, bool) {
+	names, ok := v.([]interface{})
+	if !ok || len(names) == 0 {
+		return nil, false
+	}
+	sanitizers := make([]Sanitizer, len(names))
+	for i, name := range names {
+		s, ok := name.(string)
+		if !ok {
+			return nil, false
+		}
+		if sanitizers[i], ok = builtinSanitizers[s]; !ok {
+			return nil, false
+		}
+	}
+	return sanitizers, true
+}
+
+// parseOptions 还原选项列表
+// 每个选项的ToMap结果必须与原始值一致，否则返回false
+func parseOptions(v interface{}) ([]Option, bool) {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	options := make([]Option, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		options[i] = optionFromMap(m)
+		if !jsonEqual(options[i].ToMap(), m) {
+			return nil, false
+		}
+	}
+	return options, true
+}
+
+// optionFromMap 将选项map转换为Option，非标准的键和值保存在Extra中
+func optionFromMap(m map[string]interface{}) Option {
+	opt := Option{Value: m["value"]}
+	extra := make(map[string]interface{})
+	for k, v := range m {
+		switch k {
+		case "value":
+			continue
+		case "label":
+			if label, ok := v.(string); ok {
+				opt.Label = label
+				continue
+			}
+		case "disabled":
+			if v == true {
+				opt.Disabled = true
+				continue
+			}
+		case "leaf":
+			if v == true {
+				opt.Leaf = true
+				continue
+			}
+		case "children":
+			if children, ok := parseOptions(v); ok {
+				opt.Children = children
+				continue
+			}
+		}
+		extra[k] = v
+	}
+	if len(extra) > 0 {
+		opt.Extra = extra
+	}
+	return opt
+}
+
+// allObjects 判断数组元素是否都是对象
+func allObjects(list []interface{}) bool {
+	for _, item := range list {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeJSON 解码JSON，数字保留为json.Number，避免大整数和小数格式的变化
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// remarshalJSON 将Go值经JSON编码后解码到out，统一为map、[]interface{}和json.Number
+func remarshalJSON(v interface{}, out interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(data, out)
+}
+
+// jsonEqual 判断两个值的JSON表示是否相同
+func jsonEqual(a, b interface{}) bool {
+	var na, nb interface{}
+	if remarshalJSON(a, &na) != nil || remarshalJSON(b, &nb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(na, nb)
+}
+
+// compareOperators 还原比较规则时尝试的运算符
+var compareOperators = []string{CompareEQ, CompareNE, CompareGT, CompareGTE, CompareLT, CompareLTE}
+
+// parseValidateList 还原验证规则数组，包含非对象元素时返回false
+// 规则中可以在服务端验证的键无法还原为内置规则时返回错误
+func parseValidateList(v interface{}, path string, opts ParseRulesOptions) ([]ValidateRule, bool, error) {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 || !allObjects(list) {
+		return nil, false, nil
+	}
+	rules := make([]ValidateRule, len(list))
+	for i, item := range list {
+		rule, err := parseValidateRule(item.(map[string]interface{}), opts)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s[%d]: %w", path, i, err)
+		}
+		rules[i] = rule
+	}
+	return rules, true, nil
+}
+
+// parseValidateRule 还原单个验证规则
+// 按规则的键构造可能的内置规则，生成validator函数的内置规则按ruleDataKey记录的名称和参数构造，
+// ToMap结果与原始规则一致的即为还原结果；
+// 否则ToMap结果包含在原始规则中的内置规则都参与服务端验证，还原为combinedRule，原样输出规则。
+// 可以在服务端验证的键（见checkedRuleKeys）没有对应的内置规则时返回错误；
+// 没有这些键的规则还原为CustomRule，只在前端生效，validator字符串只有开启AllowJS时才还原为JSFunc
+func parseValidateRule(m map[string]interface{}, opts ParseRulesOptions) (ValidateRule, error) {
+	var matched []ValidateRule
+	claimed := make(map[string]bool)
+	for _, candidate := range validateCandidates(m) {
+		out := candidate.rule.ToMap()
+		if jsonEqual(out, m) {
+			return candidate.rule, nil
+		}
+		if containsRule(m, out) {
+			matched = append(matched, candidate.rule)
+			for _, k := range candidate.keys {
+				claimed[k] = true
+			}
+		}
+	}
+
+	var unchecked []string
+	for _, k := range checkedRuleKeys(m) {
+		if !claimed[k] {
+			unchecked = append(unchecked, k)
+		}
+	}
+	if len(unchecked) > 0 {
+		return nil, fmt.Errorf("验证规则中的%s无法还原为内置规则，无法在服务端验证", strings.Join(unchecked, ", "))
+	}
+
+	rule := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		rule[k] = v
+	}
+	if validator, ok := m["validator"].(string); ok && opts.AllowJS && !claimed["validator"] {
+		rule["validator"] = JSFunc(validator)
+	}
+	if len(matched) == 0 {
+		return CustomRule{Rule: rule}, nil
+	}
+	return combinedRule{rules: matched, rule: rule}, nil
+}
+
+// containsRule 判断规则m是否包含out中的全部键且值相同
+func containsRule(m, out map[string]interface{}) bool {
+	for k, v := range out {
+		if mv, ok := m[k]; !ok || !jsonEqual(v, mv) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkedRuleKeys 返回规则中可以在服务端验证的键
+// 值为false的required、whitespace不做任何检查；type只有内置规则支持的类型需要验证
+func checkedRuleKeys(m map[string]interface{}) []string {
+	var keys []string
+	for _, k := range []string{"required", "whitespace"} {
+		if m[k] == true {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range []string{"pattern", "enum", "min", "max", "len", ruleDataKey} {
+		if m[k] != nil {
+			keys = append(keys, k)
+		}
+	}
+	switch m["type"] {
+	case "email", "url", "date", "number":
+		keys = append(keys, "type")
+	}
+	return keys
+}
+
+// combinedRule ParseRules还原的验证规则
+// 规则包含内置规则不输出的键（如type: "string"）或同时包含多个内置规则的键时使用：
+// 前端原样输出规则，服务端依次执行其中的内置规则
+type combinedRule struct {
+	rules []ValidateRule
+	rule  map[string]interface{}
+}
+
+// ToMap 实现ValidateRule接口
+// 内置规则输出的键（如生成的validator函数）使用内置规则的值
+func (r combinedRule) ToMap() map[string]interface{} {
+	result := make(map[string]interface{}, len(r.rule))
+	for k, v := range r.rule {
+		result[k] = v
+	}
+	for _, rule := range r.rules {
+		for k, v := range rule.ToMap() {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// Check 实现Checker接口
+func (r combinedRule) Check(value interface{}, vc *ValidateContext) error {
+	for _, rule := range r.rules {
+		if checker, ok := rule.(Checker); ok {
+			if err := checker.Check(value, vc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// builtinRules 返回验证规则包含的内置规则，combinedRule展开为其中的各个规则
+func builtinRules(rule ValidateRule) []ValidateRule {
+	if combined, ok := rule.(combinedRule); ok {
+		return combined.rules
+	}
+	return []ValidateRule{rule}
+}
+
+// ruleCandidate 可能的内置规则
+type ruleCandidate struct {
+	rule ValidateRule
+	keys []string // 内置规则验证的键
+}
+
+// validateCandidates 根据规则的键返回可能的内置规则
+func validateCandidates(m map[string]interface{}) []ruleCandidate {
+	message, _ := m["message"].(string)
+	trigger, _ := m["trigger"].(string)
+
+	var candidates []ruleCandidate
+	add := func(rule ValidateRule, keys ...string) {
+		candidates = append(candidates, ruleCandidate{rule: rule, keys: keys})
+	}
+	if m["required"] == true {
+		add(RequiredRule{Message: message, Trigger: trigger}, "required")
+	}
+	if pattern, ok := m["pattern"].(string); ok {
+		add(PatternRule{Pattern: pattern, Message: message, Trigger: trigger}, "pattern")
+	}
+	if enum, ok := m["enum"].([]interface{}); ok {
+		add(EnumRule{Enum: enum, Message: message, Trigger: trigger}, "enum")
+	}
+	if whitespace, ok := m["whitespace"].(bool); ok {
+		add(WhitespaceRule{Whitespace: whitespace, Message: message, Trigger: trigger}, "whitespace")
+	}
+
+	minValue, _ := toFloat(m["min"])
+	maxValue, _ := toFloat(m["max"])
+	switch m["type"] {
+	case "email":
+		add(EmailRule{Message: message, Trigger: trigger}, "type")
+	case "url":
+		add(URLRule{Message: message, Trigger: trigger}, "type")
+	case "date":
+		add(DateRule{Message: message, Trigger: trigger}, "type")
+	case "number":
+		add(RangeRule{
+			Min: minValue, Max: maxValue, Message: message, Trigger: trigger,
+			MinSet: m["min"] != nil && minValue == 0, MaxSet: m["max"] != nil && maxValue == 0,
+		}, "type", "min", "max")
+	case nil, "string", "array":
+		if m["min"] != nil || m["max"] != nil {
+			add(LengthRule{Min: int(minValue), Max: int(maxValue), Message: message, Trigger: trigger}, "min", "max")
+		}
+	}
+
+	if rule, ok := ruleFromData(m[ruleDataKey], message, trigger); ok {
+		add(rule, ruleDataKey, "validator")
+	}
+	return candidates
+}
+
+// ruleDataParsers 按ruleDataKey中记录的规则名称和参数还原内置规则
+var ruleDataParsers = map[string]func(params map[string]interface{}, message, trigger string) ValidateRule{
+	"equalTo": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		return EqualToFieldRule{Field: paramString(p, "field"), Message: message, Trigger: trigger}
+	},
+	"compare": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		return CompareFieldRule{Field: paramString(p, "field"), Operator: paramString(p, "operator"), Message: message, Trigger: trigger}
+	},
+	"dateOrder": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		return DateOrderRule{Field: paramString(p, "field"), Operator: paramString(p, "operator"), Message: message, Trigger: trigger}
+	},
+	"requiredIf": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		values, _ := p["values"].([]interface{})
+		return RequiredIfRule{Field: paramString(p, "field"), Values: values, Message: message, Trigger: trigger}
+	},
+	"requiredUnless": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		values, _ := p["values"].([]interface{})
+		return RequiredUnlessRule{Field: paramString(p, "field"), Values: values, Message: message, Trigger: trigger}
+	},
+	"requiredWith": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		list, _ := p["fields"].([]interface{})
+		fields := make([]string, 0, len(list))
+		for _, field := range list {
+			if s, ok := field.(string); ok {
+				fields = append(fields, s)
+			}
+		}
+		return RequiredWithRule{Fields: fields, Message: message, Trigger: trigger}
+	},
+	"remote": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		return RemoteRule{ID: paramString(p, "id"), URL: paramString(p, "url"), Message: message, Trigger: trigger}
+	},
+	"captcha": func(p map[string]interface{}, message, trigger string) ValidateRule {
+		return captchaRule{}
+	},
+}
+
+// ruleFromData 按ruleDataKey的值还原内置规则，值不是对象或规则名称未知时返回false
+func ruleFromData(v interface{}, message, trigger string) (ValidateRule, bool) {
+	params, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	parse, ok := ruleDataParsers[paramString(params, "name")]
+	if !ok {
+		return nil, false
+	}
+	return parse(params, message, trigger), true
+}
+
+// paramString 读取字符串参数，不存在或不是字符串时返回空字符串
+func paramString(params map[string]interface{}, key string) string {
+	s, _ := params[key].(string)
+	return s
+}
@@ -0,0 +1,373 @@
+package formbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parse_rules_test.go 测试规则JSON到组件的还原
+
+// roundTrip 序列化组件，还原后再次序列化，返回两次的JSON
+func roundTrip(t *testing.T, components []Component) ([]Component, string, string) {
+	t.Helper()
+	rules := make([]map[string]interface{}, len(components))
+	for i, c := range components {
+		rules[i] = c.Build()
+	}
+	first, err := json.Marshal(rules)
+	require.NoError(t, err)
+
+	parsed, err := ParseRules(first)
+	require.NoError(t, err)
+	rules = make([]map[string]interface{}, len(parsed))
+	for i, c := range parsed {
+		rules[i] = c.Build()
+	}
+	second, err := json.Marshal(rules)
+	require.NoError(t, err)
+	return parsed, string(first), string(second)
+}
+
+// TestParseRulesRoundTrip 测试各类组件 Build -> ParseRules -> Build 的输出不变
+func TestParseRulesRoundTrip(t *testing.T) {
+	components := []Component{
+		NewInput("name", "名称").Placeholder("请输入").Required().
+			Validate(NewLength(2, 20, "长度2-20"), NewPattern(`^\w+$`, "格式错误")).
+			Sanitize(Trim, CollapseSpaces).AppendRule("suffix", "元"),
+		Email("email", "邮箱"),
+		Password("password", "密码").Validate(NewMin(6, "")),
+		Password("confirm", "确认密码").Validate(NewEqualTo("password", "")),
+		Textarea("bio", "简介"),
+		NewInputNumber("age", "年龄", 18).Validate(RangeRule{Min: 1, Max: 120, Trigger: "blur"}),
+		NewInputNumber("max_age", "最大年龄").Validate(NewCompare("age", CompareGTE, "不能小于年龄")),
+		NewSelect("city", "城市", "bj").SetOptions([]Option{
+			{Value: "bj", Label: "北京"},
+			{Value: 2, Label: "上海", Disabled: true, Extra: map[string]interface{}{"icon": "sh"}},
+		}).Multiple(false).Validate(EnumRule{Enum: []interface{}{"bj", 2}}),
+		NewRadio("gender", "性别").SetOptions([]Option{{Value: 1, Label: "男"}, {Value: 0, Label: "女"}}).
+			Control([]ControlRule{{Value: 1, Rule: []Component{
+				NewInput("beard", "胡子").Validate(NewRequiredIf("gender", []interface{}{1}, "")),
+			}}}),
+		NewCheckbox("tags", "标签").SetOptions([]Option{{Value: "a", Label: "A"}}).
+			Validate(NewRequiredWith([]string{"city"}, "请选择标签")),
+		NewDatePicker("start", "开始"),
+		NewDatePicker("end", "结束").Validate(NewDateAfter("start", ""), DateRule{}),
+		NewTimePicker("time", "时间"),
+		NewSlider("volume", "音量", 30),
+		NewSwitch("enabled", "启用", true),
+		NewUpload("avatar", "头像").Action("/upload"),
+		NewCascader("area", "地区").SetOptions([]Option{
+			{Value: "bj", Label: "北京", Children: []Option{{Value: "hd", Label: "海淀", Leaf: true}}},
+		}).CascaderProps(map[string]interface{}{"checkStrictly": true}),
+		NewCascader("dept", "部门").LazySource(func(ctx context.Context, path []string) ([]Option, error) {
+			return nil, nil
+		}),
+		NewTree("perm", "权限").Data([]map[string]interface{}{{"id": 1, "label": "用户"}}),
+		NewRate("score", "评分"),
+		NewColorPicker("color", "颜色"),
+		NewFrame("photo", "照片", "/frame").Picker(FrameItems{{ID: "a", Label: "A"}}),
+		NewHidden("id", 12345678901234567),
+		NewSelect("owner", "负责人").RemoteSource(func(ctx context.Context, q string, page int) ([]Option, bool, error) {
+			return nil, false, nil
+		}),
+		NewInput("nick", "昵称").Validate(NewRemote("nick_unique", func(ctx context.Context, value interface{}, values map[string]interface{}) error {
+			return nil
+		}, "昵称已被占用"), WhitespaceRule{Whitespace: true}, URLRule{Message: "x"}),
+		NewInput("price", "价格").Emit("change", "onPriceChange").Col(map[string]interface{}{"span": 12}).
+			Children([]Component{NewElement("span").AppendRule("children", []string{"元"})}),
+	}
+
+	parsed, first, second := roundTrip(t, components)
+	assert.JSONEq(t, first, second)
+
+	_, again, third := roundTrip(t, parsed)
+	assert.JSONEq(t, again, third, "还原结果可以再次还原")
+	assert.JSONEq(t, first, third)
+
+	t.Run("Types", func(t *testing.T) {
+		for i, c := range parsed {
+			want := components[i]
+			assert.IsType(t, want, c, want.GetField())
+			assert.Equal(t, want.GetField(), c.GetField())
+		}
+
+		city := parsed[7].(*Select)
+		assert.Equal(t, []Option{
+			{Value: "bj", Label: "北京"},
+			{Value: json.Number("2"), Label: "上海", Disabled: true, Extra: map[string]interface{}{"icon": "sh"}},
+		}, city.options)
+		assert.Equal(t, "bj", city.data.Value)
+
+		area := parsed[16].(*Cascader)
+		require.Len(t, area.options, 1)
+		assert.Equal(t, Option{Value: "hd", Label: "海淀", Leaf: true}, area.options[0].Children[0])
+		assert.NotContains(t, area.data.Props, "options", "选项还原到组件，不留在props中")
+
+		name := parsed[0].(*Input)
+		assert.Equal(t, []string{"trim", "collapseSpaces"}, sanitizerNames(name.data.Sanitizers))
+		assert.Equal(t, map[string]interface{}{"suffix": "元"}, name.data.AppendRule)
+
+		assert.Equal(t, json.Number("12345678901234567"), parsed[22].(*Hidden).data.Value, "大整数不丢失精度")
+		assert.IsType(t, &Element{}, parsed[len(parsed)-1].(*Input).data.Children[0])
+	})
+
+	t.Run("ValidateTypes", func(t *testing.T) {
+		expected := map[string][]ValidateRule{
+			"name":     {RequiredRule{}, LengthRule{}, PatternRule{}},
+			"password": {LengthRule{}},
+			"confirm":  {EqualToFieldRule{}},
+			"age":      {RangeRule{}},
+			"max_age":  {CompareFieldRule{}},
+			"city":     {EnumRule{}},
+			"tags":     {RequiredWithRule{}},
+			"end":      {DateOrderRule{}, DateRule{}},
+			"nick":     {RemoteRule{}, WhitespaceRule{}, URLRule{}},
+		}
+		form := NewElmForm("/submit", parsed, nil)
+		form.eachComponent(parsed, func(c Component, data *ComponentData) {
+			if want, ok := expected[data.Field]; ok {
+				require.Len(t, data.Validate, len(want), data.Field)
+				for i := range want {
+					assert.IsType(t, want[i], data.Validate[i], data.Field)
+				}
+			}
+			if data.Field == "beard" {
+				assert.Equal(t, RequiredIfRule{Field: "gender", Values: []interface{}{json.Number("1")}, Message: "此项必填"}, data.Validate[0])
+			}
+		})
+		assert.Equal(t, CompareFieldRule{Field: "age", Operator: CompareGTE, Message: "不能小于年龄"}, parsed[6].(*InputNumber).data.Validate[0])
+		assert.Equal(t, DateOrderRule{Field: "start", Operator: CompareGT, Message: "日期先后顺序不正确"}, parsed[11].(*DatePicker).data.Validate[0])
+	})
+
+	t.Run("ServerValidation", func(t *testing.T) {
+		form := NewElmForm("/submit", parsed[:12], nil)
+		err := form.ValidateData(map[string]interface{}{
+			"name": "a", "email": "bad", "password": "123", "confirm": "x",
+			"age": 200, "max_age": 1, "city": "gz", "gender": 1, "tags": []interface{}{"z"},
+		})
+		var errs FieldErrors
+		require.ErrorAs(t, err, &errs)
+		fields := errs.ByField()
+		for _, field := range []string{"name", "email", "password", "confirm", "age", "max_age", "city", "beard", "tags"} {
+			assert.Contains(t, fields, field)
+		}
+	})
+}
+
+// TestParseRulesLossless 测试手写规则中非标准的内容原样保留
+func TestParseRulesLossless(t *testing.T) {
+	source := `[
+		{"type": "input", "field": "a", "title": {"title": "带提示", "tip": "x"}, "value": null, "props": {}, "unknown": [1, 2.50]},
+		{"type": "select", "field": "b", "options": [{"value": 1, "label": 1}, {"label": "无值"}]},
+		{"type": "radio", "field": "c", "options": [{"value": 1, "label": "是", "disabled": false, "children": []}]},
+		{"type": "checkbox", "field": "d", "options": "$api.options"},
+		{"type": "input", "field": "e", "validate": [{"required": true, "message": "必填", "custom": 1}, "bad"]},
+		{"type": "input", "field": "f", "validate": [{"validator": "function(rule, value, cb) { cb(); }", "trigger": "change"}]},
+		{"type": "input", "field": "g", "control": [{"value": 1, "handle": "function(v) { return v > 1; }", "rule": [{"type": "input", "field": "h"}]}]},
+		{"type": "el-card", "props": {"header": "卡片"}, "children": ["文本", {"type": "span", "children": ["a"]}]},
+		{"type": "input", "field": "i", "sanitize": ["trim", "myCleaner"]},
+		{"type": "input", "field": "j", "emit": ["change"], "children": []}
+	]`
+	parsed, err := ParseRules([]byte(source))
+	require.NoError(t, err)
+
+	rules := make([]map[string]interface{}, len(parsed))
+	for i, c := range parsed {
+		rules[i] = c.Build()
+	}
+	output, err := json.Marshal(rules)
+	require.NoError(t, err)
+	assert.JSONEq(t, source, string(output))
+
+	assert.Empty(t, parsed[1].(*Select).options, "无法还原的选项原样保留")
+	assert.Equal(t, []Option{{Value: json.Number("1"), Label: "是", Extra: map[string]interface{}{"disabled": false, "children": []interface{}{}}}}, parsed[2].(*Radio).options)
+	assert.Equal(t, "$api.options", parsed[3].(*Checkbox).data.AppendRule["options"])
+	validate := parsed[4].(*Input).data.Validate
+	assert.Nil(t, validate, "包含非对象元素时原样保留")
+	assert.Equal(t, CustomRule{Rule: map[string]interface{}{"validator": "function(rule, value, cb) { cb(); }", "trigger": "change"}}, parsed[5].(*Input).data.Validate[0])
+	assert.Nil(t, parsed[6].(*Input).data.Control, "control包含handle时原样保留")
+	assert.IsType(t, &Element{}, parsed[7])
+	assert.Nil(t, parsed[8].(*Input).data.Sanitizers, "包含非内置清理器时原样保留")
+}
+
+// TestParseRulesRuleData 测试按__rule记录的名称和参数还原验证规则
+func TestParseRulesRuleData(t *testing.T) {
+	rule := NewCompare("age", CompareGT, "太小").ToMap()
+	assert.Equal(t, map[string]interface{}{"name": "compare", "field": "age", "operator": CompareGT}, rule[ruleDataKey])
+
+	var m map[string]interface{}
+	require.NoError(t, remarshalJSON(rule, &m))
+	parsed, err := parseValidateRule(m, ParseRulesOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, CompareFieldRule{Field: "age", Operator: CompareGT, Message: "太小"}, parsed)
+
+	t.Run("WithoutData", func(t *testing.T) {
+		var legacy map[string]interface{}
+		require.NoError(t, remarshalJSON(rule, &legacy))
+		delete(legacy, ruleDataKey)
+		parsed, err := parseValidateRule(legacy, ParseRulesOptions{})
+		require.NoError(t, err)
+		assert.IsType(t, CustomRule{}, parsed, "不解析validator源码")
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		var edited map[string]interface{}
+		require.NoError(t, remarshalJSON(rule, &edited))
+		edited[ruleDataKey].(map[string]interface{})["operator"] = CompareLT
+		_, err := parseValidateRule(edited, ParseRulesOptions{})
+		assert.Error(t, err, "参数与validator不一致时无法在服务端验证")
+	})
+}
+
+// TestParseRulesValidatorJS 测试保存的validator字符串只有开启AllowJS时才作为函数输出
+func TestParseRulesValidatorJS(t *testing.T) {
+	source := []byte(`[{"type": "input", "field": "f", "validate": [{"validator": "function(){alert(1)}", "trigger": "blur"}]}]`)
+
+	rules, err := ParseRules(source)
+	require.NoError(t, err)
+	script := NewElmForm("/submit", rules, nil).FormScript()
+	assert.Contains(t, script, `"validator": "function(){alert(1)}"`, "默认作为字符串输出")
+	assert.NotContains(t, script, `(function(){alert(1)})`)
+
+	form := NewElmForm("/submit", nil, nil)
+	require.NoError(t, form.LoadFormRule(source, ParseRulesOptions{AllowJS: true}))
+	assert.Contains(t, form.FormScript(), `"validator": (function(){alert(1)})`)
+
+	_, _, err = ImportDesigner(source, nil)
+	require.NoError(t, err)
+}
+
+// TestParseRulesExtraKeys 测试包含内置规则不输出的键的验证规则仍在服务端执行
+func TestParseRulesExtraKeys(t *testing.T) {
+	source := `[
+		{"type": "input", "field": "name", "validate": [{"required": true, "type": "string", "message": "请输入名称"}]},
+		{"type": "input", "field": "code", "validate": [{"pattern": "^[a-z]+$", "min": 2, "max": 4, "trigger": "blur"}]},
+		{"type": "inputNumber", "field": "age", "validate": [{"type": "number", "min": 1, "max": 120, "extra": 1}]}
+	]`
+	rules, err := ParseRules([]byte(source))
+	require.NoError(t, err)
+
+	built := make([]map[string]interface{}, len(rules))
+	for i, c := range rules {
+		built[i] = c.Build()
+	}
+	output, err := json.Marshal(built)
+	require.NoError(t, err)
+	assert.JSONEq(t, source, string(output), "额外的键原样输出")
+
+	form := NewElmForm("/submit", rules, nil)
+	err = form.ValidateData(map[string]interface{}{"code": "ABCDEF", "age": 200})
+	var errs FieldErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, map[string][]string{
+		"name": {"请输入名称"},
+		"code": {"格式不正确"},
+		"age":  {"数值超出范围"},
+	}, errs.ByField())
+	assert.NoError(t, form.ValidateData(map[string]interface{}{"name": "a", "code": "abc", "age": 18}))
+
+	t.Run("Unchecked", func(t *testing.T) {
+		for _, rule := range []string{
+			`{"required": true, "len": 6}`,
+			`{"type": "integer", "min": 1}`,
+		} {
+			_, err := ParseRules([]byte(`[{"type": "input", "field": "a", "validate": [` + rule + `]}]`))
+			require.Error(t, err, rule)
+			assert.Contains(t, err.Error(), "rules[0].validate[0]", rule)
+		}
+	})
+}
+
+// TestParseRulesErrors 测试无效的规则
+func TestParseRulesErrors(t *testing.T) {
+	for source, message := range map[string]string{
+		`{"type": "input"}`: "规则必须是数组",
+		`[{"type": "input"`: "解析规则失败",
+		`[1]`:               "rules[0]: 规则必须是对象",
+		`[{"field": "a"}]`:  "rules[0]: 缺少组件类型",
+		`[{"type": "input", "control": [{"value": 1, "rule": [{"field": "x"}]}]}]`: "rules[0].control[0].rule[0]: 缺少组件类型",
+		`[{"type": "row", "children": [{"type": "col", "children": [{}]}]}]`:       "rules[0].children[0].children[0]: 缺少组件类型",
+	} {
+		_, err := ParseRules([]byte(source))
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), message, source)
+	}
+}
+
+// ratingInput 测试用的自定义组件
+type ratingInput struct {
+	Builder[*ratingInput]
+}
+
+func (r *ratingInput) GetField() string              { return r.data.Field }
+func (r *ratingInput) GetType() string               { return r.data.RuleType }
+func (r *ratingInput) Build() map[string]interface{} { return buildComponent(r.data) }
+
+// TestRegisterRuleType 测试注册自定义组件类型
+func TestRegisterRuleType(t *testing.T) {
+	RegisterRuleType("starRating", func() Component {
+		r := &ratingInput{}
+		r.data = &ComponentData{Props: map[string]interface{}{}}
+		r.inst = r
+		return r
+	})
+
+	c, err := ParseRule(map[string]interface{}{"type": "starRating", "field": "stars", "props": map[string]interface{}{"max": 10}})
+	require.NoError(t, err)
+	require.IsType(t, &ratingInput{}, c)
+	assert.Equal(t, "stars", c.GetField())
+	assert.Equal(t, map[string]interface{}{"max": json.Number("10")}, c.(*ratingInput).data.Props)
+
+	t.Run("ExtraKeys", func(t *testing.T) {
+		RegisterRuleType("badgeInput", func() Component {
+			return &stampedElement{Element: NewElement("")}
+		})
+		_, err := ParseRule(map[string]interface{}{"type": "badgeInput", "field": "x"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "生成的规则多出stamp")
+	})
+}
+
+// stampedElement Build总是输出stamp键的组件
+type stampedElement struct {
+	*Element
+}
+
+func (e *stampedElement) Build() map[string]interface{} {
+	rule := e.Element.Build()
+	rule["stamp"] = 1
+	return rule
+}
+
+// TestFormLoadFormRule 测试表单加载规则
+func TestFormLoadFormRule(t *testing.T) {
+	source := NewElmForm("/submit", []Component{
+		NewInput("name", "名称").Required(),
+		NewCaptcha("code", "验证码", NewImageCaptcha(nil)),
+	}, nil).SetSignKey([]byte("k")).SetHoneypot(HoneypotOptions{Key: []byte("k")})
+	saved, err := source.ParseFormRule()
+	require.NoError(t, err)
+
+	form := NewElmForm("/submit", nil, nil)
+	require.NoError(t, form.LoadFormRule([]byte(saved)))
+	require.Len(t, form.GetRules(), 3, "签名、挑战ID和渲染时间被忽略")
+	assert.Equal(t, "name", form.GetRules()[0].GetField())
+	assert.Equal(t, DefaultHoneypotField, form.GetRules()[2].GetField(), "未开启蜜罐的表单保留同名字段")
+
+	form = NewElmForm("/submit", nil, nil).SetHoneypot(HoneypotOptions{Key: []byte("k")})
+	require.NoError(t, form.LoadFormRule([]byte(saved)))
+	assert.Len(t, form.GetRules(), 2)
+	err = form.ValidateData(map[string]interface{}{})
+	assert.ErrorIs(t, err, &FieldError{Field: "name", Rule: "required"})
+
+	t.Run("DuplicateField", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{NewInput("keep", "保留")}, nil)
+		err := form.LoadFormRule([]byte(`[{"type": "input", "field": "a"}, {"type": "input", "field": "a"}]`))
+		assert.Error(t, err)
+		assert.Equal(t, "keep", form.GetRules()[0].GetField(), "加载失败时保留原有组件")
+	})
+}
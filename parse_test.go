@@ -0,0 +1,250 @@
+package formbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parse_test.go 测试HTTP请求数据的解析
+
+// createParseForm 创建测试用的表单
+func createParseForm() *Form {
+	return NewElmForm("/submit", []Component{
+		NewInput("name", "名称").Required(),
+		NewInputNumber("price", "价格"),
+		NewSlider("range", "区间").Range(true),
+		NewCheckbox("tags", "标签"),
+		NewSelect("city", "城市").Multiple(true),
+		Elm.FrameImages("images", "图片", "/picker"),
+		Elm.FrameImage("cover", "封面", "/picker"),
+		Elm.UploadImages("photos", "相册", "/upload"),
+		NewDatePicker("period", "周期").DateType("daterange"),
+	}, nil)
+}
+
+// newFormRequest 创建urlencoded请求
+func newFormRequest(values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// TestParseRequest 测试请求解析
+func TestParseRequest(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(
+			`{"name":"手机","price":"9.9","tags":"a","cover":"/a.png","images":["/b.png"],"unknown":1}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		values, err := ParseRequest(createParseForm(), req)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"name":   "手机",
+			"price":  9.9,
+			"tags":   []interface{}{"a"},
+			"cover":  "/a.png",
+			"images": []interface{}{"/b.png"},
+		}, values)
+	})
+
+	t.Run("JSONNumbers", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewSelect("user_id", "用户"),
+			NewInputNumber("price", "价格"),
+		}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`{"user_id":9007199254740993,"price":9.9}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		values, err := ParseRequest(form, req)
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("9007199254740993"), values["user_id"], "大整数不丢失精度")
+		assert.Equal(t, 9.9, values["price"])
+	})
+
+	t.Run("ExactNumbers", func(t *testing.T) {
+		// 开启Decimal或精度为0的InputNumber保留十进制文本，超过2^53的值经Normalize后不丢失精度
+		form := NewElmForm("/submit", []Component{
+			NewInputNumber("amount", "金额").Decimal(true),
+			NewInputNumber("qty", "数量").Precision(0),
+		}, nil)
+		check := func(req *http.Request) {
+			values, err := ParseRequest(form, req)
+			require.NoError(t, err)
+			assert.Equal(t, json.Number("12345678901234567.89"), values["amount"])
+			assert.Equal(t, json.Number("9007199254740993"), values["qty"])
+
+			result, err := form.Normalize(values)
+			require.NoError(t, err)
+			assert.Equal(t, "12345678901234567.89", decimalString(result["amount"].(*big.Rat)))
+			assert.Equal(t, int64(9007199254740993), result["qty"])
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`{"amount":12345678901234567.89,"qty":9007199254740993}`))
+		req.Header.Set("Content-Type", "application/json")
+		check(req)
+		check(newFormRequest(url.Values{"amount": {" 12345678901234567.89 "}, "qty": {"9007199254740993"}}))
+
+		_, err := ParseRequest(form, newFormRequest(url.Values{"amount": {"1/3"}}))
+		assert.Error(t, err, "分数形式不是数字")
+	})
+
+	t.Run("MaxBytes", func(t *testing.T) {
+		body := `{"name":"` + strings.Repeat("a", 100) + `"}`
+		newReq := func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			return req
+		}
+
+		_, err := ParseRequest(createParseForm(), newReq(), ParseOptions{MaxBytes: 64})
+		var tooLarge *http.MaxBytesError
+		assert.ErrorAs(t, err, &tooLarge)
+
+		_, err = ParseRequest(createParseForm(), newReq(), ParseOptions{MaxBytes: 1024})
+		assert.NoError(t, err)
+	})
+
+	t.Run("URLEncoded", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("name", "手机")
+		form.Set("price", "12")
+		form.Add("range", "1")
+		form.Add("range", "5")
+		form.Add("tags[]", "a")
+		form.Add("city", "bj")
+		form.Set("cover", "/a.png")
+		form.Add("images[]", "/b.png")
+		form.Add("images[]", "/c.png")
+		form.Add("period", "2024-05-01")
+		form.Add("period", "2024-05-07")
+
+		values, err := ParseRequest(createParseForm(), newFormRequest(form))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"name":   "手机",
+			"price":  float64(12),
+			"range":  []interface{}{float64(1), float64(5)},
+			"tags":   []interface{}{"a"},
+			"city":   []interface{}{"bj"},
+			"cover":  "/a.png",
+			"images": []interface{}{"/b.png", "/c.png"},
+			"period": []interface{}{"2024-05-01", "2024-05-07"},
+		}, values)
+	})
+
+	t.Run("EmptyValues", func(t *testing.T) {
+		form := url.Values{}
+		form.Set("price", "")
+		form.Set("tags", "")
+
+		values, err := ParseRequest(createParseForm(), newFormRequest(form))
+		require.NoError(t, err)
+		assert.Nil(t, values["price"])
+		assert.Equal(t, []interface{}{}, values["tags"])
+	})
+
+	t.Run("DuplicateScalar", func(t *testing.T) {
+		form := url.Values{"name": {"a", "b"}}
+		values, err := ParseRequest(createParseForm(), newFormRequest(form))
+		require.NoError(t, err)
+		assert.Equal(t, "a", values["name"])
+	})
+
+	t.Run("InvalidNumber", func(t *testing.T) {
+		values, err := ParseRequest(createParseForm(), newFormRequest(url.Values{"price": {"abc"}, "name": {"x"}}))
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Equal(t, "price", errs[0].Field)
+		assert.Equal(t, "x", values["name"])
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		form := url.Values{"name": {"x"}, "is_admin": {"1"}}
+
+		values, err := ParseRequest(createParseForm(), newFormRequest(form))
+		require.NoError(t, err)
+		assert.NotContains(t, values, "is_admin")
+
+		_, err = ParseRequest(createParseForm(), newFormRequest(form), ParseOptions{Strict: true})
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		require.Len(t, errs, 1)
+		assert.Equal(t, "is_admin", errs[0].Field)
+		assert.Equal(t, "unknown", errs[0].Rule)
+	})
+
+	t.Run("HiddenFields", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewRadio("type", "类型").Control([]ControlRule{
+				{Value: "company", Rule: []Component{NewInput("company", "公司")}},
+			}),
+		}, nil)
+		body := url.Values{"type": {"person"}, "company": {"x"}}
+
+		values, err := ParseRequest(form, newFormRequest(body))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"type": "person"}, values, "未激活分支中的字段被丢弃")
+
+		values, err = ParseRequest(form, newFormRequest(body), ParseOptions{KeepHidden: true})
+		require.NoError(t, err)
+		assert.Equal(t, "x", values["company"])
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.WriteField("name", "手机"))
+		require.NoError(t, writer.WriteField("tags[]", "a"))
+		for _, name := range []string{"a.png", "b.png"} {
+			part, err := writer.CreateFormFile("photos[]", name)
+			require.NoError(t, err)
+			_, _ = part.Write([]byte("data"))
+		}
+		require.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/submit", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		form := createParseForm()
+		values, err := ParseRequest(form, req)
+		require.NoError(t, err)
+		assert.Equal(t, "手机", values["name"])
+		assert.Equal(t, []interface{}{"a"}, values["tags"])
+
+		files, ok := values["photos"].([]*multipart.FileHeader)
+		require.True(t, ok)
+		require.Len(t, files, 2)
+		assert.Equal(t, "a.png", files[0].Filename)
+
+		normalized, err := form.Normalize(values)
+		require.NoError(t, err)
+		assert.Equal(t, files, normalized["photos"])
+		assert.NoError(t, form.ValidateData(values))
+	})
+
+	t.Run("GetQuery", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/submit?name=x&tags=a&tags=b", nil)
+		values, err := ParseRequest(createParseForm(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "x", values["name"])
+		assert.Equal(t, []interface{}{"a", "b"}, values["tags"])
+	})
+
+	t.Run("BadJSON", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`[1]`))
+		req.Header.Set("Content-Type", "application/json")
+		_, err := ParseRequest(createParseForm(), req)
+		assert.Error(t, err)
+	})
+}
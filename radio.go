@@ -1,5 +1,7 @@
 package formbuilder
 
+import "context"
+
 // radio.go 实现Radio单选框组件
 
 // Radio 单选框组件
@@ -71,6 +73,24 @@ func (r *Radio) Button(enable bool) *Radio {
 	return r
 }
 
+// Normalize 实现Normalizer接口
+// 转换为匹配选项的Value（保留选项值的Go类型）
+func (r *Radio) Normalize(value interface{}) (interface{}, error) {
+	return normalizeOptionValue(r.options, value)
+}
+
+// checkOptions 实现optionChecker接口
+// 提交数组时返回错误；未设置选项时不检查
+func (r *Radio) checkOptions(ctx context.Context, value, defaults interface{}) error {
+	if isSliceValue(value) {
+		return errMultipleOptions
+	}
+	if len(r.options) == 0 {
+		return nil
+	}
+	return checkOptionValues(r.options, value, defaults)
+}
+
 // GetField 实现Component接口
 func (r *Radio) GetField() string {
 	return r.data.Field
@@ -93,3 +113,11 @@ func (r *Radio) Build() map[string]interface{} {
 	}
 	return result
 }
+
+// loadOptions 实现optionLoader接口
+func (r *Radio) loadOptions(rest map[string]interface{}) {
+	if options, ok := parseOptions(rest["options"]); ok {
+		r.options = options
+		delete(rest, "options")
+	}
+}
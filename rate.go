@@ -65,6 +65,15 @@ func (r *Rate) Disabled(disabled bool) *Rate {
 	return r
 }
 
+// Normalize 实现Normalizer接口
+// 允许半选时转换为float64，否则转换为int64
+func (r *Rate) Normalize(value interface{}) (interface{}, error) {
+	if propBool(r.data.Props, "allow-half") {
+		return normalizeFloat(value, 0)
+	}
+	return normalizeInt(value)
+}
+
 // GetField 实现Component接口
 func (r *Rate) GetField() string {
 	return r.data.Field
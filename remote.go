@@ -0,0 +1,206 @@
+package formbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// remote.go 实现远程验证规则
+// 验证逻辑以Go函数注册，前端validator通过HTTP接口调用，
+// 服务端验证时直接执行同一个函数
+
+// DefaultRemoteURL 远程验证接口的默认地址前缀，完整地址为 DefaultRemoteURL + "/" + ID
+const DefaultRemoteURL = "/formbuilder/remote"
+
+// RemoteFunc 远程验证函数
+// value为当前字段的值，values为完整的表单数据，验证失败时返回错误
+type RemoteFunc func(ctx context.Context, value interface{}, values map[string]interface{}) error
+
+// remoteRegistry 已注册的远程验证函数
+var remoteRegistry = struct {
+	sync.RWMutex
+	funcs map[string]RemoteFunc
+}{funcs: make(map[string]RemoteFunc)}
+
+// RegisterRemote 注册远程验证函数，供只有ID的RemoteRule（如ParseRules、LoadForm还原的规则）使用
+// 相同ID重复注册时panic，避免不同的验证函数被静默替换；应在程序初始化时注册
+func RegisterRemote(id string, fn RemoteFunc) {
+	remoteRegistry.Lock()
+	defer remoteRegistry.Unlock()
+	if _, ok := remoteRegistry.funcs[id]; ok {
+		panic(fmt.Errorf("远程验证函数重复注册: %s", id))
+	}
+	remoteRegistry.funcs[id] = fn
+}
+
+// lookupRemote 查找远程验证函数
+func lookupRemote(id string) (RemoteFunc, bool) {
+	remoteRegistry.RLock()
+	defer remoteRegistry.RUnlock()
+	fn, ok := remoteRegistry.funcs[id]
+	return fn, ok
+}
+
+// RemoteRule 远程验证规则
+// 如用户名唯一性、SKU是否存在等需要访问服务端数据的验证
+//
+// 使用示例：
+//
+//	input.Validate(NewRemote("username_unique", func(ctx context.Context, value interface{}, values map[string]interface{}) error {
+//	    if userExists(ctx, value) {
+//	        return errors.New("用户名已被占用")
+//	    }
+//	    return nil
+//	}, ""))
+type RemoteRule struct {
+	ID      string // 远程验证函数的ID
+	URL     string // 远程验证接口地址，为空时为 DefaultRemoteURL + "/" + ID
+	Message string // 验证失败提示信息，为空时使用验证函数返回的错误信息
+	Trigger string // 触发方式：blur, change
+
+	fn RemoteFunc // NewRemote传入的验证函数，为空时使用RegisterRemote注册的函数
+}
+
+// ToMap 实现ValidateRule接口
+func (r RemoteRule) ToMap() map[string]interface{} {
+	message := withDefault(r.Message, "验证失败")
+	body := "if (isEmpty(value)) { return callback(); }" +
+		" var message = " + jsLiteral(message) + ";" +
+		" fetch(" + jsLiteral(r.url()) + ", {" +
+		" method: 'POST', credentials: 'same-origin'," +
+		" headers: {'Content-Type': 'application/json'}," +
+		" body: JSON.stringify({id: " + jsLiteral(r.ID) + ", field: rule.field, value: value, values: api ? api.formData() : {}})" +
+		" }).then(function(res) { return res.json(); }).then(function(res) {" +
+		" if (res.valid) { callback(); } else { callback(new Error(" + jsLiteral(r.Message) + " || res.message || message)); }" +
+		" }).catch(function() { callback(new Error(message)); });"
+
+	rule := map[string]interface{}{
+		"validator": jsValidator(body),
+	}
+	if r.Message != "" {
+		rule["message"] = r.Message
+	}
+	if r.Trigger != "" {
+		rule["trigger"] = r.Trigger
+	}
+	return withRuleData(rule, "remote", map[string]interface{}{"id": r.ID, "url": r.url()})
+}
+
+// Check 实现Checker接口
+// 直接执行注册的验证函数，空值跳过
+func (r RemoteRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	if fe := r.run(vc.context(), r.Message, value, vc.Values); fe != nil {
+		return fe
+	}
+	return nil
+}
+
+// url 返回远程验证接口地址
+func (r RemoteRule) url() string {
+	return withDefault(r.URL, DefaultRemoteURL+"/"+url.PathEscape(r.ID))
+}
+
+// endpoints 实现endpointProvider接口
+// 接口只执行本规则的验证函数；多个规则使用相同URL时由Form.Endpoints合并
+func (r RemoteRule) endpoints() map[string]http.Handler {
+	return map[string]http.Handler{r.url(): RemoteHandler(r)}
+}
+
+// NewRemote 创建远程验证规则
+// 验证函数保存在规则中，不写入全局注册表
+func NewRemote(id string, fn RemoteFunc, message string) RemoteRule {
+	return RemoteRule{ID: id, Message: message, fn: fn}
+}
+
+// function 返回规则的验证函数
+func (r RemoteRule) function() (RemoteFunc, bool) {
+	if r.fn != nil {
+		return r.fn, true
+	}
+	return lookupRemote(r.ID)
+}
+
+// run 执行远程验证函数
+func (r RemoteRule) run(ctx context.Context, message string, value interface{}, values map[string]interface{}) *FieldError {
+	fn, ok := r.function()
+	if !ok {
+		return newRuleError("remote", "", "远程验证未注册: "+r.ID)
+	}
+	if err := fn(ctx, value, values); err != nil {
+		return newRuleError("remote", message, err.Error()).
+			withParams(map[string]interface{}{"id": r.ID})
+	}
+	return nil
+}
+
+// remoteRequest 远程验证请求
+type remoteRequest struct {
+	ID     string                 `json:"id"`
+	Field  string                 `json:"field"`
+	Value  interface{}            `json:"value"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// remoteResponse 远程验证响应
+type remoteResponse struct {
+	Valid   bool   `json:"valid"`
+	Message string `json:"message,omitempty"`
+}
+
+// RemoteHandler 返回远程验证HTTP接口
+// 接收RemoteRule生成的前端validator发送的JSON请求，只执行rules中的验证函数，
+// 其他ID返回404，避免接口被用来调用表单之外的验证函数。
+// 通常由Form.Mount按表单中声明的规则注册，无需直接调用
+//
+// 使用示例：
+//
+//	rule := NewRemote("username_unique", usernameUnique, "")
+//	http.Handle("/api/check", RemoteHandler(rule))
+func RemoteHandler(rules ...RemoteRule) http.Handler {
+	allowed := make(map[string]RemoteRule, len(rules))
+	for _, rule := range rules {
+		allowed[rule.ID] = rule
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, remoteResponse{Message: "method not allowed"})
+			return
+		}
+
+		var req remoteRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, remoteResponse{Message: "invalid request"})
+			return
+		}
+		rule, ok := allowed[req.ID]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, remoteResponse{Message: "unknown validator"})
+			return
+		}
+		if _, ok := rule.function(); !ok {
+			writeJSON(w, http.StatusNotFound, remoteResponse{Message: "unknown validator"})
+			return
+		}
+
+		if fe := rule.run(r.Context(), "", req.Value, req.Values); fe != nil {
+			writeJSON(w, http.StatusOK, remoteResponse{Valid: false, Message: fe.Message})
+			return
+		}
+		writeJSON(w, http.StatusOK, remoteResponse{Valid: true})
+	})
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
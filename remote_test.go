@@ -0,0 +1,147 @@
+package formbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remote_test.go 测试远程验证规则
+
+// usernameTaken 测试用远程验证函数
+func usernameTaken(ctx context.Context, value interface{}, values map[string]interface{}) error {
+	if value == "admin" {
+		return errors.New("用户名已被占用")
+	}
+	return nil
+}
+
+// TestRemoteRule 测试远程验证规则
+func TestRemoteRule(t *testing.T) {
+	rule := NewRemote("test_username", usernameTaken, "")
+
+	t.Run("Check", func(t *testing.T) {
+		vc := &ValidateContext{Values: map[string]interface{}{}}
+		assert.NoError(t, rule.Check("guest", vc))
+		assert.NoError(t, rule.Check("", vc))
+
+		err := rule.Check("admin", vc)
+		var fe *FieldError
+		require.True(t, errors.As(err, &fe))
+		assert.Equal(t, "remote", fe.Rule)
+		assert.Equal(t, "用户名已被占用", fe.Message)
+	})
+
+	t.Run("RuleMessagePreferred", func(t *testing.T) {
+		r := rule
+		r.Message = "请换一个用户名"
+		err := r.Check("admin", &ValidateContext{})
+		assert.EqualError(t, err, "请换一个用户名")
+	})
+
+	t.Run("Unregistered", func(t *testing.T) {
+		err := RemoteRule{ID: "test_missing"}.Check("x", &ValidateContext{})
+		assert.Error(t, err)
+	})
+
+	t.Run("ToMap", func(t *testing.T) {
+		m := RemoteRule{ID: "test_username", URL: "/api/check", Trigger: "blur"}.ToMap()
+		validator := m["validator"].(JSFunc)
+		assert.Contains(t, validator, `fetch("/api/check"`)
+		assert.Contains(t, validator, `id: "test_username"`)
+		assert.Equal(t, "blur", m["trigger"])
+		assert.NotContains(t, m, "message")
+	})
+
+	t.Run("ContextPassed", func(t *testing.T) {
+		type ctxKey struct{}
+		form := NewElmForm("/submit", []Component{
+			NewInput("code", "编码").Validate(NewRemote("test_ctx", func(ctx context.Context, value interface{}, values map[string]interface{}) error {
+				if ctx.Value(ctxKey{}) != "tenant" {
+					return errors.New("missing context")
+				}
+				return nil
+			}, "")),
+		}, nil)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "tenant")
+		assert.NoError(t, form.ValidateDataContext(ctx, map[string]interface{}{"code": "x"}))
+		assert.Error(t, form.ValidateData(map[string]interface{}{"code": "x"}))
+	})
+
+	t.Run("Registered", func(t *testing.T) {
+		RegisterRemote("test_registered", usernameTaken)
+		t.Cleanup(func() {
+			remoteRegistry.Lock()
+			delete(remoteRegistry.funcs, "test_registered")
+			remoteRegistry.Unlock()
+		})
+		assert.Error(t, RemoteRule{ID: "test_registered"}.Check("admin", &ValidateContext{}))
+		assert.Panics(t, func() { RegisterRemote("test_registered", usernameTaken) }, "重复注册时panic")
+	})
+
+	t.Run("NoGlobalSideEffect", func(t *testing.T) {
+		_, ok := lookupRemote("test_username")
+		assert.False(t, ok, "NewRemote不写入全局注册表")
+	})
+}
+
+// TestRemoteHandler 测试远程验证接口
+func TestRemoteHandler(t *testing.T) {
+	handler := RemoteHandler(NewRemote("test_username", usernameTaken, ""))
+
+	post := func(body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, DefaultRemoteURL+"/test_username", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var resp map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		rec, resp := post(`{"id":"test_username","field":"username","value":"guest"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, true, resp["valid"])
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, resp := post(`{"id":"test_username","field":"username","value":"admin"}`)
+		assert.Equal(t, false, resp["valid"])
+		assert.Equal(t, "用户名已被占用", resp["message"])
+	})
+
+	t.Run("UnknownID", func(t *testing.T) {
+		rec, _ := post(`{"id":"test_missing","value":"x"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("NotDeclared", func(t *testing.T) {
+		RegisterRemote("test_other", usernameTaken)
+		t.Cleanup(func() {
+			remoteRegistry.Lock()
+			delete(remoteRegistry.funcs, "test_other")
+			remoteRegistry.Unlock()
+		})
+		rec, _ := post(`{"id":"test_other","value":"x"}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code, "已注册但不在接口规则中的ID不能调用")
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		rec, _ := post(`not json`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultRemoteURL, nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
@@ -0,0 +1,165 @@
+package formbuilder
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// sanitize.go 实现提交数据的清理
+// 组件声明清理器后，在Go端验证之前对提交的字符串执行清理，
+// 清理器名称同时输出到规则的sanitize字段，供前端实现相同的处理
+
+// Sanitizer 输入清理器
+// 对提交的字符串值（包括数组中的字符串元素）进行转换
+type Sanitizer struct {
+	// Name 清理器名称，输出到规则的sanitize字段
+	Name string
+
+	// Fn 清理函数
+	Fn func(string) string
+}
+
+// 内置清理器
+var (
+	// Trim 去除首尾空白字符
+	Trim = Sanitizer{Name: "trim", Fn: strings.TrimSpace}
+
+	// StripTags 去除HTML标签
+	StripTags = Sanitizer{Name: "stripTags", Fn: stripTags}
+
+	// CollapseSpaces 将连续的空白字符合并为一个空格
+	CollapseSpaces = Sanitizer{Name: "collapseSpaces", Fn: collapseSpaces}
+
+	// Lowercase 转换为小写
+	Lowercase = Sanitizer{Name: "lowercase", Fn: strings.ToLower}
+
+	// Digits 只保留数字
+	Digits = Sanitizer{Name: "digits", Fn: digitsOnly}
+)
+
+// tagPattern 匹配HTML标签
+var tagPattern = regexp.MustCompile(`(?s)<[^<>]*>`)
+
+// tagOpenPattern 匹配未闭合标签的开始，HTML中"<"后跟字母、"/"、"!"或"?"时开始一个标签
+var tagOpenPattern = regexp.MustCompile(`<([A-Za-z/!?])`)
+
+// spacePattern 匹配连续的空白字符
+var spacePattern = regexp.MustCompile(`\s+`)
+
+// stripTags 去除HTML标签
+// 重复替换直到结果不再变化（如"<<b>script>"去除<b>后会拼出新的标签），
+// 并删除可能开始标签的"<"，未闭合的标签（如"<img src=x onerror=..."）因此无法被浏览器解析；
+// 不构成标签的"<"（如"a < b"）保留
+func stripTags(s string) string {
+	for {
+		stripped := tagPattern.ReplaceAllString(s, "")
+		if stripped == s {
+			stripped = tagOpenPattern.ReplaceAllString(s, "$1")
+		}
+		if stripped == s {
+			return s
+		}
+		s = stripped
+	}
+}
+
+// collapseSpaces 合并连续的空白字符
+func collapseSpaces(s string) string {
+	return spacePattern.ReplaceAllString(s, " ")
+}
+
+// digitsOnly 只保留0-9数字
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// sanitizerDefaulter 提供默认清理器的组件
+// 未调用Sanitize时使用组件的默认清理器
+type sanitizerDefaulter interface {
+	defaultSanitizers() []Sanitizer
+}
+
+// sanitizersOf 返回组件生效的清理器
+func sanitizersOf(c Component, data *ComponentData) []Sanitizer {
+	if data.Sanitizers != nil {
+		return data.Sanitizers
+	}
+	if d, ok := c.(sanitizerDefaulter); ok {
+		return d.defaultSanitizers()
+	}
+	return nil
+}
+
+// sanitizerNames 返回清理器名称列表
+func sanitizerNames(sanitizers []Sanitizer) []string {
+	names := make([]string, len(sanitizers))
+	for i, s := range sanitizers {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Sanitize 对提交数据执行组件声明的清理器
+// 返回新的map，处理表单中声明的字段（包括未激活control分支中的字段）的字符串值和字符串数组，
+// 其余值原样保留；同一字段声明多次时使用第一次声明的清理器。
+// 不判断control分支，分支应根据清理后的值计算（如StripHidden、ValidateData）
+// ParseRequest会自动执行此步骤
+//
+// 使用示例：
+//
+//	values = form.Sanitize(values)
+//	err := form.ValidateData(values)
+func (f *Form) Sanitize(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+
+	done := make(map[string]bool)
+	f.eachComponent(f.rules, func(c Component, data *ComponentData) {
+		value, ok := values[data.Field]
+		if !ok || data.Field == "" || done[data.Field] {
+			return
+		}
+		done[data.Field] = true
+		if sanitizers := sanitizersOf(c, data); len(sanitizers) > 0 {
+			result[data.Field] = sanitizeValue(value, sanitizers)
+		}
+	})
+	return result
+}
+
+// sanitizeValue 清理字符串或数组中的字符串元素
+func sanitizeValue(value interface{}, sanitizers []Sanitizer) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, s := range sanitizers {
+			if s.Fn != nil {
+				v = s.Fn(v)
+			}
+		}
+		return v
+	case []string:
+		list := make([]string, len(v))
+		for i, item := range v {
+			list[i] = sanitizeValue(item, sanitizers).(string)
+		}
+		return list
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Interface {
+		return value
+	}
+	list := make([]interface{}, rv.Len())
+	for i := range list {
+		list[i] = sanitizeValue(rv.Index(i).Interface(), sanitizers)
+	}
+	return list
+}
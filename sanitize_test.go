@@ -0,0 +1,136 @@
+package formbuilder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sanitize_test.go 测试提交数据的清理
+
+// TestSanitizers 测试内置清理器
+func TestSanitizers(t *testing.T) {
+	cases := []struct {
+		name      string
+		sanitizer Sanitizer
+		input     string
+		expected  string
+	}{
+		{"Trim", Trim, "  hello \n", "hello"},
+		{"StripTags", StripTags, `<b>粗体</b><script>alert(1)</script> a < b`, "粗体alert(1) a < b"},
+		{"StripTagsNested", StripTags, `<<b>script>alert(1)<</b>/script>`, "alert(1)"},
+		{"StripTagsUnclosed", StripTags, `x<img src=x onerror=alert(1)`, "ximg src=x onerror=alert(1)"},
+		{"StripTagsOpenBracket", StripTags, `<<img src=x`, "img src=x"},
+		{"CollapseSpaces", CollapseSpaces, "a  b\t\nc", "a b c"},
+		{"Lowercase", Lowercase, "User@Example.COM", "user@example.com"},
+		{"Digits", Digits, "+86 138-0013-8000", "8613800138000"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.sanitizer.Fn(tc.input))
+		})
+	}
+}
+
+// TestBuilderSanitize 测试组件声明清理器
+func TestBuilderSanitize(t *testing.T) {
+	t.Run("Explicit", func(t *testing.T) {
+		input := NewInput("name", "名称").Sanitize(Trim, StripTags)
+		assert.Equal(t, []string{"trim", "stripTags"}, input.Build()["sanitize"])
+	})
+
+	t.Run("InputDefaults", func(t *testing.T) {
+		assert.Equal(t, []string{"trim"}, NewInput("name", "名称").Build()["sanitize"])
+		assert.Equal(t, []string{"trim", "lowercase"}, Email("email", "邮箱").Build()["sanitize"])
+		assert.Equal(t, []string{"digits"}, Tel("phone", "电话").Build()["sanitize"])
+		assert.NotContains(t, Password("pwd", "密码").Build(), "sanitize")
+		assert.NotContains(t, Textarea("desc", "描述").Build(), "sanitize")
+	})
+
+	t.Run("InputWithoutType", func(t *testing.T) {
+		input := NewInput("name", "名称")
+		delete(input.GetData().Props, "type")
+		assert.Equal(t, []string{"trim"}, input.Build()["sanitize"], "未设置type时按text处理")
+		assert.Equal(t, []string{"trim"}, sanitizerNames(sanitizersOf(input, input.GetData())))
+	})
+
+	t.Run("ClearDefaults", func(t *testing.T) {
+		input := Email("email", "邮箱").Sanitize()
+		assert.NotContains(t, input.Build(), "sanitize")
+		assert.Empty(t, sanitizersOf(input, input.GetData()))
+	})
+
+	t.Run("OtherComponents", func(t *testing.T) {
+		checkbox := NewCheckbox("tags", "标签").Sanitize(Trim)
+		assert.Equal(t, []string{"trim"}, checkbox.Build()["sanitize"])
+		assert.NotContains(t, NewSelect("city", "城市").Build(), "sanitize")
+	})
+}
+
+// TestFormSanitize 测试表单数据清理
+func TestFormSanitize(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		Email("email", "邮箱"),
+		Tel("phone", "电话"),
+		Password("password", "密码"),
+		Textarea("bio", "简介").Sanitize(StripTags, CollapseSpaces, Trim),
+		NewCheckbox("tags", "标签").Sanitize(Trim),
+		NewInputNumber("age", "年龄"),
+	}, nil)
+
+	values := map[string]interface{}{
+		"email":    "  John@Example.com ",
+		"phone":    "138-0013-8000",
+		"password": " secret ",
+		"bio":      " <p>Hello   world</p> ",
+		"tags":     []interface{}{" a ", "b "},
+		"age":      float64(18),
+		"unknown":  " keep ",
+	}
+	result := form.Sanitize(values)
+
+	assert.Equal(t, map[string]interface{}{
+		"email":    "john@example.com",
+		"phone":    "13800138000",
+		"password": " secret ",
+		"bio":      "Hello world",
+		"tags":     []interface{}{"a", "b"},
+		"age":      float64(18),
+		"unknown":  " keep ",
+	}, result)
+	assert.Equal(t, "  John@Example.com ", values["email"], "不应修改原始数据")
+
+	t.Run("ParseRequest", func(t *testing.T) {
+		body := url.Values{"email": {" A@B.COM "}, "tags[]": {" x "}}
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		parsed, err := ParseRequest(form, req)
+		require.NoError(t, err)
+		assert.Equal(t, "a@b.com", parsed["email"])
+		assert.Equal(t, []interface{}{"x"}, parsed["tags"])
+	})
+
+	t.Run("ControlAfterSanitize", func(t *testing.T) {
+		// 分支按清理后的值判断：" vip "清理为"vip"后激活分支，分支中的字段同样被清理
+		form := NewElmForm("/submit", []Component{
+			NewInput("level", "等级").Control([]ControlRule{
+				{Value: "vip", Rule: []Component{NewInput("card", "会员卡号")}},
+			}),
+		}, nil)
+		body := url.Values{"level": {" vip "}, "card": {" 001 "}}
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		parsed, err := ParseRequest(form, req)
+		require.NoError(t, err)
+		assert.Equal(t, "vip", parsed["level"])
+		assert.Equal(t, "001", parsed["card"])
+	})
+}
@@ -1,11 +1,16 @@
 package formbuilder
 
+import "context"
+
 // select.go 实现Select下拉选择组件
 
 // Select 下拉选择框组件
 type Select struct {
 	Builder[*Select]
-	options []Option // 选项列表
+	options   []Option       // 选项列表
+	searcher  OptionSearcher // 远程搜索函数，见RemoteSource
+	searchURL string         // 远程搜索接口地址
+	lookup    OptionLookup   // 按值查询选项的函数，见RemoteLookup
 }
 
 // NewSelect 创建一个新的Select组件
@@ -116,6 +121,31 @@ func (s *Select) DefaultFirstOption(enable bool) *Select {
 	return s
 }
 
+// Normalize 实现Normalizer接口
+// 单选时转换为匹配选项的Value（保留选项值的Go类型），多选时逐个转换，结果为[]interface{}
+func (s *Select) Normalize(value interface{}) (interface{}, error) {
+	if propBool(s.data.Props, "multiple") {
+		return normalizeOptionValues(s.options, value)
+	}
+	return normalizeOptionValue(s.options, value)
+}
+
+// checkOptions 实现optionChecker接口
+// 未开启多选时提交数组返回错误（包括远程搜索和AllowCreate）；设置了RemoteSource时通过搜索函数检查；
+// 开启AllowCreate或手动配置的远程搜索、以及未设置选项时不检查
+func (s *Select) checkOptions(ctx context.Context, value, defaults interface{}) error {
+	if !propBool(s.data.Props, "multiple") && isSliceValue(value) {
+		return errMultipleOptions
+	}
+	if s.searcher != nil && !propBool(s.data.Props, "allow-create") {
+		return s.checkRemoteOptions(ctx, value, defaults)
+	}
+	if len(s.options) == 0 || propBool(s.data.Props, "allow-create") || propBool(s.data.Props, "remote") {
+		return nil
+	}
+	return checkOptionValues(s.options, value, defaults)
+}
+
 // GetField 实现Component接口
 func (s *Select) GetField() string {
 	return s.data.Field
@@ -139,3 +169,11 @@ func (s *Select) Build() map[string]interface{} {
 	}
 	return result
 }
+
+// loadOptions 实现optionLoader接口
+func (s *Select) loadOptions(rest map[string]interface{}) {
+	if options, ok := parseOptions(rest["options"]); ok {
+		s.options = options
+		delete(rest, "options")
+	}
+}
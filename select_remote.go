@@ -0,0 +1,271 @@
+package formbuilder
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// select_remote.go 实现Select组件的远程搜索
+// 选项由Go函数按关键字分页查询，前端remote-method通过JSON接口调用，
+// 服务端验证时使用同一个函数确认提交的值是可选的选项，适用于从大表中选择用户、商品等
+
+// DefaultOptionSearchURL 远程搜索接口的默认地址前缀，完整地址为 前缀 + "/" + 字段名
+const DefaultOptionSearchURL = "/formbuilder/options"
+
+// maxOptionCheckPages 服务端验证时每个值最多查询的页数
+const maxOptionCheckPages = 5
+
+// OptionSearcher 远程搜索函数
+// query为搜索关键字，page为页码（从1开始），more表示是否还有下一页。
+// 未设置RemoteLookup时，服务端验证以提交的值作为关键字调用，结果中应包含该值对应的选项
+type OptionSearcher func(ctx context.Context, query string, page int) (options []Option, more bool, err error)
+
+// OptionLookup 按值查询选项的函数
+// values为提交的选项值（转换为字符串），返回存在的选项，不存在的值直接忽略
+type OptionLookup func(ctx context.Context, values []string) ([]Option, error)
+
+// OptionSearchResponse 远程搜索接口的响应
+//
+// 响应示例：
+//
+//	{"code": 200, "message": "ok", "data": {"options": [{"value": 1, "label": "张三"}], "more": true}}
+type OptionSearchResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    OptionSearchPage `json:"data"`
+}
+
+// OptionSearchPage 一页搜索结果
+type OptionSearchPage struct {
+	Options []map[string]interface{} `json:"options"`
+	More    bool                     `json:"more"`
+}
+
+// RemoteSource 设置远程搜索函数，由Form.Mount注册搜索接口并生成remote-method
+// url为搜索接口地址，默认为 DefaultOptionSearchURL + "/" + 字段名；
+// 下拉列表滚动到底部时自动加载下一页。需要回显的默认值可以通过SetOptions提供对应的选项
+//
+// 使用示例：
+//
+//	Elm.Select("user_id", "用户").Multiple(true).
+//	    RemoteSource(func(ctx context.Context, query string, page int) ([]formbuilder.Option, bool, error) {
+//	        users, more, err := userRepo.Search(ctx, query, page, 20)
+//	        if err != nil {
+//	            return nil, false, err
+//	        }
+//	        options := make([]formbuilder.Option, len(users))
+//	        for i, u := range users {
+//	            options[i] = formbuilder.Option{Value: u.ID, Label: u.Name}
+//	        }
+//	        return options, more, nil
+//	    })
+func (s *Select) RemoteSource(searcher OptionSearcher, searchURL ...string) *Select {
+	s.searcher = searcher
+	s.searchURL = DefaultOptionSearchURL + "/" + s.data.Field
+	if len(searchURL) > 0 && searchURL[0] != "" {
+		s.searchURL = searchURL[0]
+	}
+
+	popper := "fb-remote-" + cssIdent(s.data.Field)
+	popperClass := popper
+	if class := propString(s.data.Props, "popper-class"); class != "" {
+		popperClass = class + " " + popper
+	}
+	s.data.Props["remote"] = true
+	s.data.Props["filterable"] = true
+	s.data.Props["loading"] = false
+	s.data.Props["popper-class"] = popperClass
+	s.data.Props["remote-method"] = s.remoteMethodScript(popper)
+	return s
+}
+
+// RemoteLookup 设置服务端验证时按值查询选项的函数
+// 设置后验证不再以提交的值作为关键字调用搜索函数，搜索函数只需处理用户输入的关键字；
+// 一次验证中不在SetOptions选项里的值合并为一次调用
+//
+// 使用示例：
+//
+//	Elm.Select("user_id", "用户").Multiple(true).
+//	    RemoteSource(searchUsers).
+//	    RemoteLookup(func(ctx context.Context, ids []string) ([]formbuilder.Option, error) {
+//	        users, err := userRepo.FindByIDs(ctx, ids)
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        options := make([]formbuilder.Option, len(users))
+//	        for i, u := range users {
+//	            options[i] = formbuilder.Option{Value: u.ID, Label: u.Name}
+//	        }
+//	        return options, nil
+//	    })
+func (s *Select) RemoteLookup(lookup OptionLookup) *Select {
+	s.lookup = lookup
+	return s
+}
+
+// endpoints 实现endpointProvider接口
+func (s *Select) endpoints() map[string]http.Handler {
+	if s.searcher == nil {
+		return nil
+	}
+	return map[string]http.Handler{searchPath(s.searchURL): OptionSearchHandler(s.searcher)}
+}
+
+// OptionSearchHandler 返回远程搜索接口
+// 接收GET请求，q为搜索关键字，page为页码，返回OptionSearchResponse
+func OptionSearchHandler(searcher OptionSearcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Code: http.StatusMethodNotAllowed, Message: "method not allowed"})
+			return
+		}
+
+		params := r.URL.Query()
+		page, _ := strconv.Atoi(params.Get("page"))
+		options, more, err := searcher(r.Context(), strings.TrimSpace(params.Get("q")), max(page, 1))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Code: http.StatusInternalServerError, Message: "搜索失败"})
+			return
+		}
+
+		result := OptionSearchPage{Options: make([]map[string]interface{}, len(options)), More: more}
+		for i, opt := range options {
+			result.Options[i] = opt.ToMap()
+		}
+		writeJSON(w, http.StatusOK, OptionSearchResponse{Code: http.StatusOK, Message: "ok", Data: result})
+	})
+}
+
+// checkRemoteOptions 通过查询函数检查提交的值
+// 值在SetOptions设置的选项中时不再查询；设置了RemoteLookup时按值查询，否则以值为关键字调用搜索函数
+func (s *Select) checkRemoteOptions(ctx context.Context, value, defaults interface{}) error {
+	items, ok := sliceItems(value)
+	if !ok {
+		items = []interface{}{value}
+	}
+	var pending []interface{}
+	var queries []string
+	for _, item := range items {
+		if opt, ok := matchOption(s.options, item); ok && !opt.Disabled {
+			continue
+		}
+		query, ok := stringValue(item)
+		if !ok {
+			return errInvalidOption
+		}
+		pending = append(pending, item)
+		queries = append(queries, query)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var found []Option
+	if s.lookup != nil {
+		options, err := s.lookup(ctx, queries)
+		if err != nil {
+			return errOptionLookup
+		}
+		found = options
+	}
+	for i, item := range pending {
+		var opt *Option
+		if s.lookup != nil {
+			if matched, ok := matchOption(found, item); ok {
+				opt = &matched
+			}
+		} else {
+			var err error
+			if opt, err = s.searchOption(ctx, queries[i], item); err != nil {
+				return errOptionLookup
+			}
+		}
+		if opt == nil || opt.Disabled && !containsOptionValue(defaults, item) {
+			return errInvalidOption
+		}
+	}
+	return nil
+}
+
+// searchOption 以query为关键字查找值为value的选项，找不到时返回nil
+func (s *Select) searchOption(ctx context.Context, query string, value interface{}) (*Option, error) {
+	for page := 1; page <= maxOptionCheckPages; page++ {
+		options, more, err := s.searcher(ctx, query, page)
+		if err != nil {
+			return nil, err
+		}
+		if opt, ok := matchOption(options, value); ok {
+			return &opt, nil
+		}
+		if !more {
+			break
+		}
+	}
+	return nil, nil
+}
+
+// remoteMethodScript 生成remote-method函数
+// 选项写入表单规则的options；请求按序号丢弃过期的响应；
+// 通过popper-class识别下拉列表，滚动到底部且还有下一页时追加加载
+func (s *Select) remoteMethodScript(popper string) JSFunc {
+	return JSFunc(`function(query) {
+    var api = window.$fApi, field = ` + jsLiteral(s.data.Field) + `, url = ` + jsLiteral(s.searchURL) + `;
+    var rule = api && api.getRule(field);
+    if (!rule) { return; }
+    var states = window.$fbRemoteSelect = window.$fbRemoteSelect || {};
+    var state = states[field] = states[field] || {seq: 0};
+    var load = function(q, page) {
+        var seq = ++state.seq;
+        state.query = q; state.page = page; state.loading = true;
+        if (page === 1) { rule.props.loading = true; }
+        fetch(url + (url.indexOf('?') < 0 ? '?' : '&') + 'q=' + encodeURIComponent(q) + '&page=' + page,
+            {credentials: 'same-origin', headers: {'Accept': 'application/json'}}).then(function(res) {
+            return res.json();
+        }).then(function(res) {
+            if (seq !== state.seq) { return; }
+            var data = (res && res.data) || {};
+            rule.options = page === 1 ? (data.options || []) : (rule.options || []).concat(data.options || []);
+            state.more = !!data.more;
+        }).catch(function() {
+            if (seq === state.seq) { state.more = false; }
+        }).then(function() {
+            if (seq === state.seq) { state.loading = false; rule.props.loading = false; }
+        });
+    };
+    if (!state.installed) {
+        state.installed = true;
+        document.addEventListener('scroll', function(e) {
+            var el = e.target;
+            if (!el || !el.closest || !el.closest(` + jsLiteral("."+popper) + `) || !state.more || state.loading) { return; }
+            if (el.scrollTop + el.clientHeight >= el.scrollHeight - 20) { load(state.query, state.page + 1); }
+        }, true);
+    }
+    load(query || '', 1);
+}`)
+}
+
+// cssIdent 将字段名转换为可以用作CSS类名的字符串
+func cssIdent(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteString("_" + strconv.FormatInt(int64(r), 16) + "_")
+		}
+	}
+	return b.String()
+}
+
+// searchPath 返回搜索接口地址中的路径部分
+func searchPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
@@ -0,0 +1,254 @@
+package formbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// select_remote_test.go 测试Select组件的远程搜索
+
+// testUsers 测试用的用户表，ID为1到25
+func testUsers() []Option {
+	users := make([]Option, 25)
+	for i := range users {
+		users[i] = Option{Value: i + 1, Label: "用户" + strconv.Itoa(i+1)}
+	}
+	users[24].Disabled = true
+	return users
+}
+
+// searchUsers 按标签或ID搜索用户，每页10条
+func searchUsers(ctx context.Context, query string, page int) ([]Option, bool, error) {
+	var matched []Option
+	for _, u := range testUsers() {
+		if strings.Contains(u.Label, query) || strconv.Itoa(u.Value.(int)) == query {
+			matched = append(matched, u)
+		}
+	}
+	start := (page - 1) * 10
+	if start >= len(matched) {
+		return nil, false, nil
+	}
+	end := min(start+10, len(matched))
+	return matched[start:end], end < len(matched), nil
+}
+
+// TestSelectRemoteSource 测试远程搜索的规则生成
+func TestSelectRemoteSource(t *testing.T) {
+	t.Run("Props", func(t *testing.T) {
+		sel := NewSelect("user_id", "用户").Props("popper-class", "wide").RemoteSource(searchUsers)
+		props := sel.Build()["props"].(map[string]interface{})
+
+		assert.Equal(t, true, props["remote"])
+		assert.Equal(t, true, props["filterable"])
+		assert.Equal(t, false, props["loading"])
+		assert.Equal(t, "wide fb-remote-user_id", props["popper-class"])
+
+		method := string(props["remote-method"].(JSFunc))
+		assert.True(t, strings.HasPrefix(method, "function(query)"))
+		assert.Contains(t, method, `url = "/formbuilder/options/user_id"`)
+		assert.Contains(t, method, `el.closest(".fb-remote-user_id")`)
+		assert.Contains(t, method, "rule.options = page === 1")
+	})
+
+	t.Run("CustomURL", func(t *testing.T) {
+		sel := NewSelect("user.id", "用户").RemoteSource(searchUsers, "/api/users?scope=all")
+		props := sel.Build()["props"].(map[string]interface{})
+		assert.Contains(t, props["remote-method"], `url = "/api/users?scope=all"`)
+		assert.Equal(t, "fb-remote-user_2e_id", props["popper-class"])
+		assert.Contains(t, sel.endpoints(), "/api/users", "注册的地址不包含查询参数")
+	})
+}
+
+// TestOptionSearchHandler 测试远程搜索接口
+func TestOptionSearchHandler(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		NewSelect("user_id", "用户").RemoteSource(searchUsers),
+		NewSelect("role", "角色").SetOptions([]Option{{Value: "admin", Label: "管理员"}}),
+	}, nil)
+	endpoints := form.Endpoints()
+	require.Len(t, endpoints, 1)
+
+	mux := http.NewServeMux()
+	require.NoError(t, form.Mount(mux))
+
+	search := func(target string) (int, OptionSearchResponse) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var resp OptionSearchResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	t.Run("FirstPage", func(t *testing.T) {
+		code, resp := search("/formbuilder/options/user_id?q=%E7%94%A8%E6%88%B7")
+		require.Equal(t, http.StatusOK, code)
+		assert.Len(t, resp.Data.Options, 10)
+		assert.True(t, resp.Data.More)
+		assert.Equal(t, map[string]interface{}{"value": float64(1), "label": "用户1"}, resp.Data.Options[0])
+	})
+
+	t.Run("LastPage", func(t *testing.T) {
+		_, resp := search("/formbuilder/options/user_id?q=%E7%94%A8%E6%88%B7&page=3")
+		assert.Len(t, resp.Data.Options, 5)
+		assert.False(t, resp.Data.More)
+		assert.Equal(t, true, resp.Data.Options[4]["disabled"])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/formbuilder/options/user_id", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+		failing := OptionSearchHandler(func(ctx context.Context, query string, page int) ([]Option, bool, error) {
+			return nil, false, errors.New("db down")
+		})
+		rec = httptest.NewRecorder()
+		failing.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?q=a", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "搜索失败")
+	})
+}
+
+// TestSelectRemoteValidate 测试通过搜索函数验证提交的值
+func TestSelectRemoteValidate(t *testing.T) {
+	form := NewElmForm("/submit", []Component{
+		NewSelect("owner", "负责人").RemoteSource(searchUsers),
+		NewSelect("members", "成员").Multiple(true).RemoteSource(searchUsers),
+		NewSelect("reviewer", "审核人", 25).RemoteSource(searchUsers),
+	}, nil)
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, form.ValidateData(map[string]interface{}{
+			"owner":    "3",
+			"members":  []interface{}{float64(1), "12"},
+			"reviewer": 25,
+		}))
+	})
+
+	t.Run("LaterPage", func(t *testing.T) {
+		// 搜索函数忽略关键字时，用户21在第三页
+		form := NewElmForm("/submit", []Component{
+			NewSelect("owner", "负责人").RemoteSource(func(ctx context.Context, query string, page int) ([]Option, bool, error) {
+				return searchUsers(ctx, "", page)
+			}),
+		}, nil)
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"owner": 21}))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		err := form.ValidateData(map[string]interface{}{
+			"owner":   "99",
+			"members": []interface{}{"1", "25"},
+		})
+		var errs FieldErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Len(t, errs, 2, "不存在的值和非默认值的禁用选项都无效")
+		assert.ErrorIs(t, err, &FieldError{Field: "owner", Rule: "option"})
+		assert.ErrorIs(t, err, &FieldError{Field: "members", Rule: "option"})
+	})
+
+	t.Run("SingleValueArray", func(t *testing.T) {
+		err := form.ValidateData(map[string]interface{}{"owner": []interface{}{"1", "3"}})
+		assert.ErrorIs(t, err, &FieldError{Field: "owner", Rule: "option"})
+	})
+
+	t.Run("StaticOptions", func(t *testing.T) {
+		calls := 0
+		form := NewElmForm("/submit", []Component{
+			NewSelect("owner", "负责人", 100).
+				SetOptions([]Option{{Value: 100, Label: "已离职用户"}}).
+				RemoteSource(func(ctx context.Context, query string, page int) ([]Option, bool, error) {
+					calls++
+					return searchUsers(ctx, query, page)
+				}),
+		}, nil)
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"owner": "100"}))
+		assert.Zero(t, calls, "SetOptions中的值不需要查询")
+	})
+
+	t.Run("SearcherError", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewSelect("owner", "负责人").RemoteSource(func(ctx context.Context, query string, page int) ([]Option, bool, error) {
+				return nil, false, errors.New("db down")
+			}),
+		}, nil)
+		err := form.ValidateData(map[string]interface{}{"owner": "1"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), errOptionLookup.Error())
+	})
+
+	t.Run("AllowCreate", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewSelect("tags", "标签").AllowCreate(true).RemoteSource(searchUsers),
+		}, nil)
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"tags": "新标签"}))
+	})
+
+	t.Run("Lookup", func(t *testing.T) {
+		var searched bool
+		var looked [][]string
+		form := NewElmForm("/submit", []Component{
+			NewSelect("members", "成员", []int{25}).Multiple(true).
+				SetOptions([]Option{{Value: 1, Label: "用户1"}}).
+				RemoteSource(func(ctx context.Context, query string, page int) ([]Option, bool, error) {
+					searched = true
+					return searchUsers(ctx, query, page)
+				}).
+				RemoteLookup(func(ctx context.Context, values []string) ([]Option, error) {
+					looked = append(looked, values)
+					var options []Option
+					for _, u := range testUsers() {
+						for _, v := range values {
+							if strconv.Itoa(u.Value.(int)) == v {
+								options = append(options, u)
+							}
+						}
+					}
+					return options, nil
+				}),
+		}, nil)
+
+		assert.NoError(t, form.ValidateData(map[string]interface{}{"members": []interface{}{float64(1), "12", float64(25)}}))
+		assert.False(t, searched, "设置RemoteLookup后不再调用搜索函数")
+		assert.Equal(t, [][]string{{"12", "25"}}, looked, "SetOptions之外的值合并为一次查询")
+
+		err := form.ValidateData(map[string]interface{}{"members": []interface{}{"99"}})
+		assert.ErrorIs(t, err, &FieldError{Field: "members", Rule: "option"})
+	})
+
+	t.Run("LookupError", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewSelect("owner", "负责人").RemoteSource(searchUsers).
+				RemoteLookup(func(ctx context.Context, values []string) ([]Option, error) {
+					return nil, errors.New("db down")
+				}),
+		}, nil)
+		err := form.ValidateData(map[string]interface{}{"owner": "1"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), errOptionLookup.Error())
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		type ctxKey struct{}
+		var got interface{}
+		form := NewElmForm("/submit", []Component{
+			NewSelect("owner", "负责人").RemoteSource(func(ctx context.Context, query string, page int) ([]Option, bool, error) {
+				got = ctx.Value(ctxKey{})
+				return searchUsers(ctx, query, page)
+			}),
+		}, nil)
+		ctx := context.WithValue(context.Background(), ctxKey{}, "tenant-1")
+		assert.NoError(t, form.ValidateDataContext(ctx, map[string]interface{}{"owner": "1"}))
+		assert.Equal(t, "tenant-1", got)
+	})
+}
@@ -0,0 +1,241 @@
+package formbuilder
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// sign.go 实现隐藏字段与只读字段的防篡改签名
+// 渲染表单时用服务端密钥对Hidden、禁用和只读字段的值签名，签名随隐藏字段提交，
+// 提交后校验这些字段是否被修改
+
+// SignatureField 签名字段名
+const SignatureField = "_fb_sig"
+
+// DefaultSignMaxAge 签名的默认有效期
+const DefaultSignMaxAge = 24 * time.Hour
+
+var (
+	// errInvalidSignature 签名缺失或无效
+	errInvalidSignature = errors.New("表单签名无效")
+
+	// errSignatureExpired 签名已过期
+	errSignatureExpired = errors.New("表单已过期，请刷新页面后重试")
+)
+
+// signedPayload 签名内容
+// 签发时间、有效期和随机数一同签名，过期的签名值不能再被重放
+type signedPayload struct {
+	Action   string                 `json:"a"`
+	Values   map[string]interface{} `json:"v"`
+	IssuedAt int64                  `json:"t"`           // 签发时间（Unix毫秒）
+	MaxAge   int64                  `json:"m,omitempty"` // 有效期（毫秒），为0时不限制
+	Nonce    string                 `json:"n"`           // 随机数，使每次签发的值都不相同
+}
+
+// SetSignKey 设置签名密钥，开启防篡改签名
+// 开启后FormRule会追加名为SignatureField的隐藏字段，携带Hidden、Disabled(true)和Readonly(true)字段的签名值；
+// 提交后使用VerifySigned拒绝被修改的值，或使用RestoreSigned恢复为签名时的值。
+// 签名包含签发时间，超过有效期（默认DefaultSignMaxAge，通过SetSignMaxAge修改）后视为无效。
+// 密钥为空时关闭签名
+//
+// 使用示例：
+//
+//	form.SetSignKey([]byte(os.Getenv("FORM_SIGN_KEY")))
+//
+//	// 提交处理
+//	if err := form.VerifySigned(values); err != nil {
+//	    // 返回FieldErrors
+//	}
+func (f *Form) SetSignKey(key []byte) *Form {
+	f.signKey = key
+	return f
+}
+
+// SetSignMaxAge 设置签名的有效期
+// 有效期随签名一同签发，修改后只影响之后渲染的表单；为0时使用DefaultSignMaxAge，小于0时不限制。
+// 有效期内的签名值仍可重复提交，需要一次性提交时应记录已使用的SignatureField值直到其过期
+func (f *Form) SetSignMaxAge(maxAge time.Duration) *Form {
+	f.signMaxAge = maxAge
+	return f
+}
+
+// signatureMaxAge 返回签发时使用的有效期，0表示不限制
+func (f *Form) signatureMaxAge() time.Duration {
+	switch {
+	case f.signMaxAge < 0:
+		return 0
+	case f.signMaxAge == 0:
+		return DefaultSignMaxAge
+	}
+	return f.signMaxAge
+}
+
+// signedValues 收集需要签名的字段及其渲染值
+// 包括Hidden组件以及设置了disabled或readonly属性的组件
+func (f *Form) signedValues() map[string]interface{} {
+	values := make(map[string]interface{})
+	f.eachComponent(f.rules, func(c Component, data *ComponentData) {
+		if data.Field == "" {
+			return
+		}
+		if data.RuleType != "hidden" && !propBool(data.Props, "disabled") && !propBool(data.Props, "readonly") {
+			return
+		}
+		values[data.Field] = f.initialValue(data)
+	})
+	return values
+}
+
+// signatureToken 生成签名字段的值
+// 格式为 base64url(payload) + "." + base64url(HMAC-SHA256(payload))
+func (f *Form) signatureToken(now time.Time) string {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return ""
+	}
+	payload, err := json.Marshal(signedPayload{
+		Action:   f.action,
+		Values:   f.signedValues(),
+		IssuedAt: now.UnixMilli(),
+		MaxAge:   f.signatureMaxAge().Milliseconds(),
+		Nonce:    base64.RawURLEncoding.EncodeToString(nonce),
+	})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signHMAC(f.signKey, payload))
+}
+
+// parseSignature 校验签名和有效期并返回签名时的字段值
+func (f *Form) parseSignature(values map[string]interface{}, now time.Time) (map[string]interface{}, error) {
+	token, _ := values[SignatureField].(string)
+	encoded, mac, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errInvalidSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(sum, signHMAC(f.signKey, payload)) {
+		return nil, errInvalidSignature
+	}
+
+	var signed signedPayload
+	if err := json.Unmarshal(payload, &signed); err != nil || signed.Action != f.action || signed.IssuedAt == 0 {
+		return nil, errInvalidSignature
+	}
+	if signed.MaxAge > 0 && now.Sub(time.UnixMilli(signed.IssuedAt)) > time.Duration(signed.MaxAge)*time.Millisecond {
+		return nil, errSignatureExpired
+	}
+	return signed.Values, nil
+}
+
+// VerifySigned 校验签名字段的值是否被修改
+// 未设置签名密钥时直接返回nil；签名缺失、无效或过期时返回SignatureField的错误，
+// 被修改的字段以rule为"signature"的FieldErrors返回，未提交的签名字段不视为修改
+func (f *Form) VerifySigned(values map[string]interface{}) error {
+	if len(f.signKey) == 0 {
+		return nil
+	}
+	signed, err := f.parseSignature(values, time.Now())
+	if err != nil {
+		return FieldErrors{{Field: SignatureField, Rule: "signature", Message: err.Error()}}
+	}
+
+	var errs FieldErrors
+	f.eachComponent(f.rules, func(c Component, data *ComponentData) {
+		expected, ok := signed[data.Field]
+		if !ok {
+			return
+		}
+		if value, submitted := values[data.Field]; submitted && !signedValueEqual(expected, value) {
+			errs = append(errs, &FieldError{Field: data.Field, Rule: "signature", Message: "该字段不允许修改"})
+		}
+	})
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// RestoreSigned 将签名字段恢复为签名时的值
+// 返回新的map，其中签名字段使用渲染时的值，并移除SignatureField；
+// 未设置签名密钥时只移除SignatureField，签名缺失、无效或过期时返回错误
+func (f *Form) RestoreSigned(values map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if k != SignatureField {
+			result[k] = v
+		}
+	}
+	if len(f.signKey) == 0 {
+		return result, nil
+	}
+
+	signed, err := f.parseSignature(values, time.Now())
+	if err != nil {
+		return nil, FieldErrors{{Field: SignatureField, Rule: "signature", Message: err.Error()}}
+	}
+	for field, expected := range signed {
+		if value, ok := result[field]; !ok || !signedValueEqual(expected, value) {
+			result[field] = expected
+		}
+	}
+	return result, nil
+}
+
+// signHMAC 计算HMAC-SHA256
+func signHMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// signedValueEqual 判断提交值与签名值是否一致
+// 签名值经过JSON编码，数字与数字字符串视为相等（urlencoded提交的值都是字符串）
+func signedValueEqual(signed, submitted interface{}) bool {
+	if isEmptyValue(signed) && isEmptyValue(submitted) {
+		return true
+	}
+	if sameOptionValue(signed, submitted) {
+		return true
+	}
+
+	a, okA := sliceItems(signed)
+	b, okB := sliceItems(submitted)
+	if okA && okB && signed != nil && submitted != nil {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !signedValueEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// map等其他类型按JSON编码比较
+	ja, errA := json.Marshal(signed)
+	jb, errB := json.Marshal(submitted)
+	if errA != nil || errB != nil {
+		return false
+	}
+	var va, vb interface{}
+	if json.Unmarshal(ja, &va) != nil || json.Unmarshal(jb, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,191 @@
+package formbuilder
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sign_test.go 测试防篡改签名
+
+// createSignedForm 创建开启签名的表单
+func createSignedForm() *Form {
+	form := NewElmForm("/order/save", []Component{
+		NewHidden("id", 42),
+		NewInput("price", "价格").Disabled(true),
+		NewInput("sku", "SKU").Readonly(true),
+		NewCheckbox("tags", "标签").Disabled(true),
+		NewInput("remark", "备注"),
+	}, nil)
+	form.SetValue("price", "99.00").SetValue("tags", []string{"a", "b"})
+	return form.SetSignKey([]byte("secret"))
+}
+
+// signatureOf 从FormRule中取出签名字段的值
+func signatureOf(t *testing.T, form *Form) string {
+	rules := form.FormRule()
+	last := rules[len(rules)-1]
+	require.Equal(t, SignatureField, last["field"])
+	assert.Equal(t, "hidden", last["type"])
+	token, ok := last["value"].(string)
+	require.True(t, ok)
+	return token
+}
+
+// TestFormSignature 测试签名字段的生成
+func TestFormSignature(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{NewHidden("id", 1)}, nil)
+		assert.Len(t, form.FormRule(), 1, "未设置密钥时不追加签名字段")
+		assert.NoError(t, form.VerifySigned(map[string]interface{}{"id": 2}))
+	})
+
+	t.Run("SignedValues", func(t *testing.T) {
+		assert.Equal(t, map[string]interface{}{
+			"id":    42,
+			"price": "99.00",
+			"sku":   nil,
+			"tags":  []string{"a", "b"},
+		}, createSignedForm().signedValues())
+	})
+
+	t.Run("InScript", func(t *testing.T) {
+		form := createSignedForm()
+		assert.Contains(t, form.FormScript(), SignatureField)
+	})
+}
+
+// TestVerifySigned 测试签名校验
+func TestVerifySigned(t *testing.T) {
+	form := createSignedForm()
+	token := signatureOf(t, form)
+
+	t.Run("Unchanged", func(t *testing.T) {
+		assert.NoError(t, form.VerifySigned(map[string]interface{}{
+			SignatureField: token,
+			"id":           float64(42),
+			"price":        "99.00",
+			"tags":         []interface{}{"a", "b"},
+			"remark":       "任意修改",
+		}))
+	})
+
+	t.Run("URLEncodedValues", func(t *testing.T) {
+		assert.NoError(t, form.VerifySigned(map[string]interface{}{SignatureField: token, "id": "42"}))
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		err := form.VerifySigned(map[string]interface{}{
+			SignatureField: token,
+			"id":           float64(43),
+			"price":        "0.01",
+			"tags":         []interface{}{"a"},
+		})
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Equal(t, map[string][]string{
+			"id":    {"该字段不允许修改"},
+			"price": {"该字段不允许修改"},
+			"tags":  {"该字段不允许修改"},
+		}, errs.ByField())
+		assert.Equal(t, "signature", errs[0].Rule)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		for name, sig := range map[string]interface{}{
+			"Missing":   nil,
+			"Malformed": "abc",
+			"Forged":    token[:strings.Index(token, ".")] + ".AAAA",
+			"OtherKey":  signatureOf(t, createSignedForm().SetSignKey([]byte("other"))),
+			"OtherForm": signatureOf(t, createSignedForm().SetAction("/other")),
+			"Expired":   createSignedForm().signatureToken(time.Now().Add(-DefaultSignMaxAge - time.Minute)),
+		} {
+			t.Run(name, func(t *testing.T) {
+				err := form.VerifySigned(map[string]interface{}{SignatureField: sig, "id": float64(42)})
+				var errs FieldErrors
+				require.True(t, errors.As(err, &errs))
+				assert.Equal(t, SignatureField, errs[0].Field)
+			})
+		}
+	})
+}
+
+// TestSignMaxAge 测试签名有效期
+func TestSignMaxAge(t *testing.T) {
+	issued := time.Now().Add(-2 * time.Hour)
+
+	t.Run("Custom", func(t *testing.T) {
+		form := createSignedForm().SetSignMaxAge(time.Hour)
+		_, err := form.parseSignature(map[string]interface{}{SignatureField: form.signatureToken(issued)}, time.Now())
+		assert.ErrorIs(t, err, errSignatureExpired)
+
+		_, err = form.parseSignature(map[string]interface{}{SignatureField: form.signatureToken(time.Now())}, time.Now())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Signed", func(t *testing.T) {
+		token := createSignedForm().SetSignMaxAge(time.Hour).signatureToken(issued)
+		form := createSignedForm().SetSignMaxAge(time.Hour * 24)
+		_, err := form.parseSignature(map[string]interface{}{SignatureField: token}, time.Now())
+		assert.ErrorIs(t, err, errSignatureExpired, "有效期以签发时为准")
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		form := createSignedForm().SetSignMaxAge(-1)
+		token := form.signatureToken(time.Now().Add(-365 * 24 * time.Hour))
+		_, err := form.parseSignature(map[string]interface{}{SignatureField: token}, time.Now())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		form := createSignedForm()
+		now := time.Now()
+		assert.NotEqual(t, form.signatureToken(now), form.signatureToken(now))
+	})
+}
+
+// TestRestoreSigned 测试恢复签名字段
+func TestRestoreSigned(t *testing.T) {
+	form := createSignedForm()
+	token := signatureOf(t, form)
+
+	result, err := form.RestoreSigned(map[string]interface{}{
+		SignatureField: token,
+		"id":           float64(1),
+		"price":        "99.00",
+		"remark":       "备注",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":     float64(42),
+		"price":  "99.00",
+		"sku":    nil,
+		"tags":   []interface{}{"a", "b"},
+		"remark": "备注",
+	}, result)
+
+	_, err = form.RestoreSigned(map[string]interface{}{"id": float64(1)})
+	assert.Error(t, err)
+}
+
+// TestParseRequestKeepsSignature 测试请求解析保留签名字段
+func TestParseRequestKeepsSignature(t *testing.T) {
+	form := createSignedForm()
+	token := signatureOf(t, form)
+
+	body := url.Values{SignatureField: {token}, "id": {"42"}, "price": {"99.00"}}
+	req := httptest.NewRequest(http.MethodPost, "/order/save", strings.NewReader(body.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	values, err := ParseRequest(form, req, ParseOptions{Strict: true})
+	require.NoError(t, err)
+	assert.Equal(t, token, values[SignatureField])
+	assert.NoError(t, form.VerifySigned(values))
+}
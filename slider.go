@@ -65,6 +65,30 @@ func (s *Slider) Disabled(disabled bool) *Slider {
 	return s
 }
 
+// Normalize 实现Normalizer接口
+// 范围选择时转换为[2]float64，否则转换为float64
+func (s *Slider) Normalize(value interface{}) (interface{}, error) {
+	if !propBool(s.data.Props, "range") {
+		return normalizeFloat(value, 0)
+	}
+	if isEmptyValue(value) {
+		return nil, nil
+	}
+	items, ok := sliceItems(value)
+	if !ok || len(items) != 2 {
+		return nil, errNotRange
+	}
+	var result [2]float64
+	for i, item := range items {
+		f, ok := toFloat(item)
+		if !ok {
+			return nil, errNotNumber
+		}
+		result[i] = f
+	}
+	return result, nil
+}
+
 // GetField 实现Component接口
 func (s *Slider) GetField() string {
 	return s.data.Field
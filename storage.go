@@ -0,0 +1,179 @@
+package formbuilder
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// storage.go 定义上传文件的存储接口
+// 上传接口只负责校验和接收文件，文件保存到哪里由Storage决定，
+// 内置保存到本地目录的LocalStorage
+
+// Storage 上传文件存储接口
+// 可以实现为本地目录、对象存储等
+type Storage interface {
+	// Save 保存文件内容并返回文件的访问地址
+	// name为上传接口生成的相对路径，如 "2024/01/02/3f2a9c....jpg"
+	Save(ctx context.Context, name string, content io.Reader) (string, error)
+}
+
+// StorageDeleter 支持删除文件的存储
+// 一次上传多个文件或生成缩略图中途失败时，上传接口通过此接口删除本次已保存的文件；
+// 未实现时已保存的文件会被保留
+type StorageDeleter interface {
+	// Delete 删除Save保存的文件，name与Save的参数相同
+	Delete(ctx context.Context, name string) error
+}
+
+// errInvalidStorageName 文件路径不合法
+var errInvalidStorageName = errors.New("invalid storage name")
+
+// LocalStorage 本地目录存储
+//
+// 使用示例：
+//
+//	storage := formbuilder.NewLocalStorage("./public/uploads", "/uploads")
+//	http.Handle("/uploads/", http.StripPrefix("/uploads/", http.FileServer(http.Dir("./public/uploads"))))
+type LocalStorage struct {
+	// Dir 保存文件的目录
+	Dir string
+
+	// BaseURL 文件的访问地址前缀，返回的地址为 BaseURL + "/" + name
+	BaseURL string
+}
+
+// NewLocalStorage 创建本地目录存储
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{Dir: dir, BaseURL: baseURL}
+}
+
+// Save 实现Storage接口
+// 按name创建子目录，已存在同名文件时返回错误
+func (s *LocalStorage) Save(ctx context.Context, name string, content io.Reader) (string, error) {
+	target, err := s.path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(target)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(target)
+		return "", err
+	}
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + name, nil
+}
+
+// Delete 实现StorageDeleter接口
+func (s *LocalStorage) Delete(ctx context.Context, name string) error {
+	target, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(target)
+}
+
+// path 返回name在存储目录中的路径，拒绝绝对路径和跳出存储目录的路径
+func (s *LocalStorage) path(name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if name == "" || clean != "/"+name || strings.Contains(name, "\\") {
+		return "", errInvalidStorageName
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(name)), nil
+}
+
+// storageName 为上传的文件生成存储路径
+// 格式为 "年/月/日/随机串.扩展名"，扩展名见storageExt
+func storageName(filename string, head []byte) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return time.Now().Format("2006/01/02") + "/" + hex.EncodeToString(buf) + storageExt(filename, head)
+}
+
+// sniffedExts 内容可识别的类型使用的扩展名
+var sniffedExts = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"image/x-icon":    ".ico",
+	"application/pdf": ".pdf",
+	"audio/mpeg":      ".mp3",
+	"audio/wave":      ".wav",
+	"video/mp4":       ".mp4",
+	"video/webm":      ".webm",
+}
+
+// storageExt 根据文件内容确定保存时的扩展名
+// 扩展名决定了文件被访问时的Content-Type，不能直接使用客户端提交的文件名：
+//   - 内容或扩展名为HTML、SVG、XML、脚本等可执行内容时不保留扩展名，避免存储型XSS
+//   - 内容可以识别时（图片、音视频、PDF）使用与内容一致的扩展名，扩展名与内容不符时按内容改写
+//   - 内容无法识别时（文本、Office文档等）保留原扩展名，但图片等必须能从内容识别的扩展名除外
+func storageExt(filename string, head []byte) string {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(filename, "\\", "/")))
+	if !isSafeExt(ext) {
+		ext = ""
+	}
+	detected := mediaType(http.DetectContentType(head))
+	extType := ""
+	if ext != "" {
+		extType = mediaType(mime.TypeByExtension(ext))
+	}
+
+	switch {
+	case isActiveType(detected):
+		return ""
+	case !isGenericType(detected):
+		if extType == detected {
+			return ext
+		}
+		return sniffedExts[detected]
+	case isActiveType(extType) || isSniffable(extType):
+		return ""
+	}
+	return ext
+}
+
+// isActiveType 判断浏览器打开该类型的文件时是否可能执行脚本
+func isActiveType(t string) bool {
+	switch t {
+	case "text/html", "application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml",
+		"text/javascript", "application/javascript", "application/x-javascript":
+		return true
+	}
+	return false
+}
+
+// isSafeExt 判断扩展名是否只包含字母和数字
+func isSafeExt(ext string) bool {
+	if len(ext) < 2 || len(ext) > 10 {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
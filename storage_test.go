@@ -0,0 +1,91 @@
+package formbuilder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storage_test.go 测试上传文件存储
+
+// TestLocalStorage 测试本地目录存储
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalStorage(dir, "/uploads/")
+
+	t.Run("Save", func(t *testing.T) {
+		url, err := storage.Save(context.Background(), "2024/01/02/a.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "/uploads/2024/01/02/a.txt", url)
+
+		content, err := os.ReadFile(filepath.Join(dir, "2024", "01", "02", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(content))
+	})
+
+	t.Run("NoOverwrite", func(t *testing.T) {
+		_, err := storage.Save(context.Background(), "2024/01/02/a.txt", strings.NewReader("again"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := storage.Save(context.Background(), "2024/01/02/b.txt", strings.NewReader("x"))
+		require.NoError(t, err)
+		require.NoError(t, storage.Delete(context.Background(), "2024/01/02/b.txt"))
+		assert.NoFileExists(t, filepath.Join(dir, "2024", "01", "02", "b.txt"))
+		assert.Error(t, storage.Delete(context.Background(), "../b.txt"))
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "../a.txt", "/etc/a.txt", "a/../../b.txt", `a\b.txt`} {
+			_, err := storage.Save(context.Background(), name, strings.NewReader("x"))
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+// TestStorageName 测试存储路径生成
+func TestStorageName(t *testing.T) {
+	pattern := regexp.MustCompile(`^\d{4}/\d{2}/\d{2}/[0-9a-f]{32}`)
+
+	name := storageName("Photo.JPG", []byte("\xff\xd8\xff\xe0"))
+	assert.Regexp(t, pattern, name)
+	assert.True(t, strings.HasSuffix(name, ".jpg"))
+
+	assert.NotEqual(t, storageName("a.png", pngHeader), storageName("a.png", pngHeader))
+	assert.Regexp(t, pattern.String()+`$`, storageName("../../evil.ph p", []byte("text")))
+	assert.Regexp(t, pattern.String()+`$`, storageName("noext", []byte("text")))
+}
+
+// TestStorageExt 测试按文件内容确定扩展名
+func TestStorageExt(t *testing.T) {
+	cases := []struct {
+		name     string
+		filename string
+		head     []byte
+		ext      string
+	}{
+		{"Matched", "a.png", pngHeader, ".png"},
+		{"Uppercase", "a.PNG", pngHeader, ".png"},
+		{"PolyglotHTML", "a.html", pngHeader, ".png"},
+		{"WrongImageExt", "a.gif", pngHeader, ".png"},
+		{"Text", "a.txt", []byte("hello"), ".txt"},
+		{"Document", "a.docx", []byte("PK\x03\x04"), ".docx"},
+		{"TextAsImage", "a.png", []byte("hello"), ""},
+		{"HTMLContent", "a.html", []byte("<html><script>alert(1)</script>"), ""},
+		{"HTMLExt", "a.htm", []byte("hello"), ""},
+		{"SVG", "a.svg", []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), ""},
+		{"NoExt", "a", pngHeader, ".png"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.ext, storageExt(tc.filename, tc.head))
+		})
+	}
+}
@@ -65,6 +65,26 @@ func (s *Switch) Disabled(disabled bool) *Switch {
 	return s
 }
 
+// Normalize 实现Normalizer接口
+// 设置了ActiveValue/InactiveValue时转换为对应的值（保留其Go类型），否则转换为bool
+func (s *Switch) Normalize(value interface{}) (interface{}, error) {
+	active, hasActive := s.data.Props["active-value"]
+	inactive, hasInactive := s.data.Props["inactive-value"]
+	if !hasActive && !hasInactive {
+		return normalizeBool(value)
+	}
+	if !hasActive {
+		active = true
+	}
+	if !hasInactive {
+		inactive = false
+	}
+	if opt, ok := matchOption([]Option{{Value: active}, {Value: inactive}}, value); ok {
+		return opt.Value, nil
+	}
+	return nil, errNotBool
+}
+
 // GetField 实现Component接口
 func (s *Switch) GetField() string {
 	return s.data.Field
@@ -3,6 +3,9 @@ package formbuilder
 import (
 	"bytes"
 	"html/template"
+	"regexp"
+	"sort"
+	"strings"
 )
 
 // template.go 实现模板和视图生成
@@ -10,19 +13,32 @@ import (
 
 // FormScript 生成表单初始化JavaScript脚本
 // 对应PHP的formScript()方法
+//
+// 规则和配置中JSFunc类型的值输出为函数，其余值（包括FormData回填的值、选项文字等字符串）
+// 一律按JSON数据输出，不会被当作函数执行；
+// 表单API注册为window.$fApi，供跨字段验证等前端函数读取其他字段的值；
+// 设置了action时表单以JSON提交到action，服务端返回的ErrorResponse显示在对应的表单项下
 func (f *Form) FormScript() string {
-	ruleJSON, _ := f.ParseFormRule()
-	configJSON, _ := f.ParseFormConfig()
+	created := ""
+	if f.action != "" {
+		created = `
+    created() {
+        if (!this.option.onSubmit) {
+            this.option.onSubmit = ` + f.submitScript() + `;
+        }
+    },`
+	}
 
 	script := `
 new Vue({
     el: '#app',
     data: {
         fApi: null,
-        rule: ` + ruleJSON + `,
-        option: ` + configJSON + `
-    },
+        rule: ` + jsSource(f.FormRule()) + `,
+        option: ` + jsSource(f.FormConfig()) + `
+    },` + created + `
     mounted() {
+        window.$fApi = this.fApi;
         console.log('Form created:', this.fApi);
     }
 });
@@ -30,6 +46,119 @@ new Vue({
 	return script
 }
 
+// JSFunc JavaScript函数源码
+// FormScript将JSFunc输出为函数，普通字符串始终作为字符串输出，JSON中JSFunc与普通字符串相同。
+// 内置的跨字段验证、远程验证和CustomRule.Validator生成的validator均为JSFunc，
+// 组件属性需要函数时（如事件回调）同样使用JSFunc，其内容必须由服务端控制
+//
+// 使用示例：
+//
+//	NewInput("code", "编码").Props("onBlur", formbuilder.JSFunc("function(e) { console.log(e); }"))
+type JSFunc string
+
+// scriptClosePattern 函数源码中会提前结束<script>标签的内容
+var scriptClosePattern = regexp.MustCompile(`(?i)</script`)
+
+// jsSource 将规则或配置编码为JavaScript表达式
+// JSFunc输出为函数源码，其余值按JSON编码
+func jsSource(v interface{}) string {
+	switch v := v.(type) {
+	case JSFunc:
+		return "(" + scriptClosePattern.ReplaceAllString(string(v), `<\/script`) + ")"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = jsLiteral(k) + ": " + jsSource(v[k])
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	case []map[string]interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = jsSource(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = jsSource(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return jsLiteral(v)
+}
+
+// submitScript 生成表单提交函数
+// 服务端返回的字段错误以追加的验证规则显示在表单项下，字段值被修改后错误消失；
+// 无法对应到表单项的错误以提示框显示。响应包含redirect时跳转到该地址；
+// 签发了CSRF令牌时同时以CSRFHeader请求头提交
+func (f *Form) submitScript() string {
+	return `(function() {
+            var serverErrors = {};
+            var installed = {};
+            var showErrors = function(api, errors, formData) {
+                var general = [];
+                serverErrors = {};
+                Object.keys(errors).forEach(function(field) {
+                    var message = [].concat(errors[field]).join('；');
+                    if (!field || !api.getRule(field)) { general.push(message); return; }
+                    serverErrors[field] = {message: message, value: JSON.stringify(formData[field])};
+                    if (!installed[field]) {
+                        installed[field] = true;
+                        api.updateValidate(field, [{validator: function(rule, value, callback) {
+                            var e = serverErrors[field];
+                            if (e && JSON.stringify(value) === e.value) { callback(new Error(e.message)); } else { callback(); }
+                        }}], true);
+                    }
+                });
+                Object.keys(serverErrors).forEach(function(field) { api.validateField(field, function() {}); });
+                if (general.length) { alert(general.join('\n')); }
+            };
+            return function(formData, api) {
+                var url = ` + jsLiteral(f.action) + `;
+                var method = ` + jsLiteral(strings.ToUpper(withDefault(f.method, "POST"))) + `;
+                var init = {method: method, credentials: 'same-origin', headers: {'Accept': 'application/json'}};` + f.csrfHeaderScript() + `
+                serverErrors = {};
+                if (method === 'GET' || method === 'HEAD') {
+                    var query = [];
+                    Object.keys(formData).forEach(function(key) {
+                        var value = formData[key];
+                        if (value === undefined || value === null) { return; }
+                        [].concat(value).forEach(function(v) {
+                            query.push(encodeURIComponent(Array.isArray(value) ? key + '[]' : key) + '=' + encodeURIComponent(v));
+                        });
+                    });
+                    url += (url.indexOf('?') < 0 ? '?' : '&') + query.join('&');
+                } else {
+                    init.headers['Content-Type'] = 'application/json';
+                    init.body = JSON.stringify(formData);
+                }
+                fetch(url, init).then(function(res) {
+                    return res.json().catch(function() { return {}; }).then(function(body) { return {ok: res.ok, body: body || {}}; });
+                }).then(function(res) {
+                    if (res.ok) {
+                        if (res.body.redirect) { window.location.href = res.body.redirect; }
+                        return;
+                    }
+                    if (res.body.errors) { showErrors(api, res.body.errors, formData); } else { alert(res.body.message || '提交失败'); }
+                }).catch(function() { alert('提交失败'); });
+            };
+        })()`
+}
+
+// csrfHeaderScript 生成在提交请求中附加CSRF令牌请求头的语句
+func (f *Form) csrfHeaderScript() string {
+	if f.csrfToken == "" {
+		return ""
+	}
+	return `
+                init.headers[` + jsLiteral(CSRFHeader) + `] = ` + jsLiteral(f.csrfToken) + `;`
+}
+
 // View 生成完整的HTML页面
 // 对应PHP的view()方法
 func (f *Form) View() (string, error) {
@@ -60,12 +189,12 @@ func (f *Form) View() (string, error) {
 		Title      string
 		Styles     []string
 		Scripts    []string
-		FormScript string
+		FormScript template.JS
 	}{
 		Title:      f.getTitle(),
 		Styles:     f.ui.GetStyles(),
 		Scripts:    f.ui.GetScripts(),
-		FormScript: f.FormScript(),
+		FormScript: template.JS(f.FormScript()), // 脚本由本包生成，按原样输出
 	}
 
 	t, err := template.New("form").Parse(tmpl)
@@ -96,7 +225,7 @@ func (f *Form) Template(templateContent string) (string, error) {
 		Title      string
 		Styles     []string
 		Scripts    []string
-		FormScript string
+		FormScript template.JS
 		FormRule   string
 		FormConfig string
 		Action     string
@@ -105,7 +234,7 @@ func (f *Form) Template(templateContent string) (string, error) {
 		Title:      f.getTitle(),
 		Styles:     f.ui.GetStyles(),
 		Scripts:    f.ui.GetScripts(),
-		FormScript: f.FormScript(),
+		FormScript: template.JS(f.FormScript()),
 		FormRule:   func() string { s, _ := f.ParseFormRule(); return s }(),
 		FormConfig: func() string { s, _ := f.ParseFormConfig(); return s }(),
 		Action:     f.action,
@@ -0,0 +1,93 @@
+package formbuilder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// template_test.go 测试脚本和视图生成
+
+// TestFormScript 测试表单初始化脚本
+func TestFormScript(t *testing.T) {
+	form := createTestForm()
+	script := form.FormScript()
+
+	assert.Contains(t, script, `rule: [{"field": "username"`)
+	assert.Contains(t, script, `option: {"form": {"action": "/test/submit"`)
+	assert.NotContains(t, script, "parseJson")
+	assert.Contains(t, script, "window.$fApi = this.fApi;")
+
+	t.Run("Functions", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewInput("confirm", "确认").Validate(NewEqualTo("password", "不一致")),
+			NewInput("code", "编码").Validate(CustomRule{Validator: "function(rule, value, callback) { callback(); }"}),
+		}, nil)
+		script := form.FormScript()
+		assert.Contains(t, script, `"validator": (function(rule, value, callback) {`)
+		assert.Contains(t, script, `"validator": (function(rule, value, callback) { callback(); })`)
+	})
+
+	t.Run("DataStaysString", func(t *testing.T) {
+		payload := "function(){alert(1)}"
+		form := NewElmForm("/submit", []Component{
+			NewInput("name", "名称"),
+			NewSelect("city", "城市").SetOptions([]Option{NewOption("bj", "$FN:"+payload)}),
+		}, nil)
+		form.FormData(map[string]interface{}{"name": payload})
+		script := form.FormScript()
+		assert.Contains(t, script, `"value": "function(){alert(1)}"`)
+		assert.Contains(t, script, `"label": "$FN:function(){alert(1)}"`)
+		assert.NotContains(t, script, "(function(){alert(1)})")
+	})
+
+	t.Run("NoScriptBreakout", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewInput("code", "编码").Validate(CustomRule{Validator: "function() { return '</script>'; }"}),
+		}, nil)
+		assert.Contains(t, form.FormScript(), `return '<\/script>';`)
+	})
+}
+
+// TestView 测试完整HTML页面
+func TestView(t *testing.T) {
+	t.Run("ScriptNotQuoted", func(t *testing.T) {
+		html, err := createTestForm().View()
+		require.NoError(t, err)
+		assert.Contains(t, html, "new Vue({")
+		assert.False(t, strings.Contains(html, `"\nnew Vue`), "脚本不应被转义为字符串字面量")
+	})
+
+	t.Run("NoScriptBreakout", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewInput("name", "</script><script>alert(1)</script>"),
+		}, nil)
+		html, err := form.View()
+		require.NoError(t, err)
+		assert.NotContains(t, html, "<script>alert(1)")
+	})
+}
+
+// TestFormScriptSubmit 测试表单提交函数
+func TestFormScriptSubmit(t *testing.T) {
+	t.Run("WithAction", func(t *testing.T) {
+		script := NewElmForm("/save", nil, nil).FormScript()
+		assert.Contains(t, script, "if (!this.option.onSubmit)")
+		assert.Contains(t, script, `var url = "/save";`)
+		assert.Contains(t, script, `var method = "POST";`)
+		assert.Contains(t, script, "api.updateValidate(field")
+		assert.Contains(t, script, "res.body.errors")
+	})
+
+	t.Run("MethodUpperCased", func(t *testing.T) {
+		script := NewElmForm("/search", nil, nil).SetMethod("get").FormScript()
+		assert.Contains(t, script, `var method = "GET";`)
+	})
+
+	t.Run("WithoutAction", func(t *testing.T) {
+		script := NewElmForm("", nil, nil).FormScript()
+		assert.NotContains(t, script, "onSubmit")
+	})
+}
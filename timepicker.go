@@ -65,6 +65,16 @@ func (t *TimePicker) Size(size string) *TimePicker {
 	return t
 }
 
+// Normalize 实现Normalizer接口
+// 按value-format解析为time.Time，范围选择时转换为[2]time.Time
+func (t *TimePicker) Normalize(value interface{}) (interface{}, error) {
+	format := propString(t.data.Props, "value-format")
+	if propBool(t.data.Props, "is-range") {
+		return normalizeTimeRange(value, format)
+	}
+	return normalizeTime(value, format)
+}
+
 // GetField 实现Component接口
 func (t *TimePicker) GetField() string {
 	return t.data.Field
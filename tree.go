@@ -1,5 +1,10 @@
 package formbuilder
 
+import (
+	"context"
+	"encoding/json"
+)
+
 // tree.go 实现Tree树形控件组件
 
 // Tree 树形控件组件
@@ -65,6 +70,83 @@ func (t *Tree) CheckOnClickNode(check bool) *Tree {
 	return t
 }
 
+// Normalize 实现Normalizer接口
+// 选中的节点key转换为[]string
+func (t *Tree) Normalize(value interface{}) (interface{}, error) {
+	return normalizeStrings(value)
+}
+
+// checkOptions 实现optionChecker接口
+// 选中的key必须是data中节点的node-key（默认为id），禁用的节点只有在初始值中时才视为有效；
+// 未设置data（数据由前端加载）时不检查，data无法解析时拒绝提交
+func (t *Tree) checkOptions(ctx context.Context, value, defaults interface{}) error {
+	keys, err := t.nodeKeys()
+	if err != nil {
+		return errOptionLookup
+	}
+	if keys == nil {
+		return nil
+	}
+	items, ok := sliceItems(value)
+	if !ok {
+		items = []interface{}{value}
+	}
+	for _, item := range items {
+		key, ok := stringValue(item)
+		if !ok {
+			return errInvalidOption
+		}
+		enabled, ok := keys[key]
+		if !ok || !enabled && !containsOptionValue(defaults, item) {
+			return errInvalidOption
+		}
+	}
+	return nil
+}
+
+// nodeKeys 收集树形数据中节点的key，值表示节点是否未禁用
+// 未设置data时返回nil
+func (t *Tree) nodeKeys() (map[string]bool, error) {
+	data, ok := t.data.Props["data"]
+	if !ok || data == nil {
+		return nil, nil
+	}
+	// 通过JSON统一结构体、map等不同形式的树形数据
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []interface{}
+	if err := json.Unmarshal(raw, &nodes); err != nil {
+		return nil, err
+	}
+
+	nodeKey := withDefault(propString(t.data.Props, "node-key"), "id")
+	props, _ := t.data.Props["props"].(map[string]interface{})
+	childrenKey := withDefault(propString(props, "children"), "children")
+	disabledKey := withDefault(propString(props, "disabled"), "disabled")
+
+	keys := make(map[string]bool)
+	var walk func(nodes []interface{})
+	walk = func(nodes []interface{}) {
+		for _, n := range nodes {
+			node, ok := n.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if key, ok := stringValue(node[nodeKey]); ok {
+				disabled, _ := node[disabledKey].(bool)
+				keys[key] = !disabled
+			}
+			if children, ok := node[childrenKey].([]interface{}); ok {
+				walk(children)
+			}
+		}
+	}
+	walk(nodes)
+	return keys, nil
+}
+
 // GetField 实现Component接口
 func (t *Tree) GetField() string {
 	return t.data.Field
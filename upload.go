@@ -1,10 +1,17 @@
 package formbuilder
 
+import "mime/multipart"
+
 // upload.go 实现Upload文件上传组件
 
 // Upload 文件上传组件
 type Upload struct {
 	Builder[*Upload]
+	storage          Storage           // 上传文件存储，设置后由Mount注册上传接口
+	uploadOptions    UploadOptions     // 上传接口选项
+	imageConstraints *ImageConstraints // 图片约束
+	thumbWidth       int               // 缩略图最大宽度
+	thumbHeight      int               // 缩略图最大高度
 }
 
 // NewUpload 创建文件上传组件
@@ -89,6 +96,16 @@ func (u *Upload) Disabled(disabled bool) *Upload {
 	return u
 }
 
+// Normalize 实现Normalizer接口
+// limit为1时转换为string，否则转换为[]string；multipart上传的文件原样返回
+func (u *Upload) Normalize(value interface{}) (interface{}, error) {
+	if files, ok := value.([]*multipart.FileHeader); ok {
+		return files, nil
+	}
+	limit, _ := propInt(u.data.Props, "limit")
+	return normalizeStringOrStrings(value, limit == 1)
+}
+
 // GetField 实现Component接口
 func (u *Upload) GetField() string {
 	return u.data.Field
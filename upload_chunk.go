@@ -0,0 +1,408 @@
+package formbuilder
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// upload_chunk.go 实现Upload组件的分片上传
+// 大文件在前端按固定大小切片，依次提交到上传接口，接口在ChunkStore中记录进度，
+// 全部分片接收后合并、校验并写入Storage。上传中断后再次选择同一文件时跳过已接收的分片
+//
+// 分片上传与普通上传使用同一个地址，通过op参数区分：
+//   - POST action?op=init      JSON {id, name, size, checksum}，创建或恢复会话，返回 {id, chunkSize, uploaded}
+//   - POST action?op=chunk&id=&index=  请求体为分片内容，X-Chunk-Checksum为分片的SHA-256（可选）
+//   - POST action?op=complete&id=      合并分片，返回与普通上传相同的UploadResponse
+//
+// checksum为整个文件的SHA-256，提供时合并后校验；内置的前端函数不计算整个文件的哈希
+// （浏览器的crypto.subtle不支持流式计算，大文件需要一次读入内存），只供自定义客户端使用
+
+// DefaultChunkSize 默认的分片字节数
+const DefaultChunkSize = 5 << 20
+
+// chunkUploadScript 分片上传的前端函数，作为el-upload的http-request
+// 会话ID按文件名、大小和修改时间保存在localStorage中，用于断点续传；
+// 浏览器支持crypto.subtle时为每个分片附带SHA-256，不支持时（如非HTTPS页面）不附带，服务端只检查分片大小
+const chunkUploadScript = `function(options) {
+    var file = options.file, action = options.action, headers = options.headers || {};
+    var key = 'fb-upload:' + action + ':' + file.name + ':' + file.size + ':' + file.lastModified;
+    var storage = {
+        get: function() { try { return window.localStorage.getItem(key) || ''; } catch (e) { return ''; } },
+        set: function(v) { try { window.localStorage.setItem(key, v); } catch (e) {} },
+        remove: function() { try { window.localStorage.removeItem(key); } catch (e) {} }
+    };
+    var url = function(op, query) { return action + (action.indexOf('?') < 0 ? '?' : '&') + 'op=' + op + (query || ''); };
+    var post = function(target, body, extra) {
+        var h = {};
+        Object.keys(headers).forEach(function(k) { h[k] = headers[k]; });
+        Object.keys(extra || {}).forEach(function(k) { h[k] = extra[k]; });
+        return fetch(target, {method: 'POST', credentials: 'same-origin', headers: h, body: body}).then(function(res) {
+            return res.json().catch(function() { return {}; }).then(function(data) {
+                if (!res.ok) { throw new Error(data.message || '上传失败'); }
+                return data;
+            });
+        });
+    };
+    var digest = function(blob) {
+        if (!window.crypto || !window.crypto.subtle || !blob.arrayBuffer) { return Promise.resolve(''); }
+        return blob.arrayBuffer().then(function(buf) { return window.crypto.subtle.digest('SHA-256', buf); }).then(function(hash) {
+            return Array.prototype.map.call(new Uint8Array(hash), function(b) { return ('0' + b.toString(16)).slice(-2); }).join('');
+        });
+    };
+    var id;
+    return post(url('init'), JSON.stringify({id: storage.get(), name: file.name, size: file.size}), {'Content-Type': 'application/json'}).then(function(res) {
+        id = res.data.id;
+        storage.set(id);
+        var size = res.data.chunkSize, total = Math.ceil(file.size / size), uploaded = {}, done = 0;
+        (res.data.uploaded || []).forEach(function(i) { uploaded[i] = true; done++; });
+        var next = function(i) {
+            if (i >= total) { return Promise.resolve(); }
+            if (uploaded[i]) { return next(i + 1); }
+            var blob = file.slice(i * size, Math.min(file.size, (i + 1) * size));
+            return digest(blob).then(function(sum) {
+                var extra = {'Content-Type': 'application/octet-stream'};
+                if (sum) { extra['X-Chunk-Checksum'] = sum; }
+                return post(url('chunk', '&id=' + encodeURIComponent(id) + '&index=' + i), blob, extra);
+            }).then(function() {
+                done++;
+                if (options.onProgress) { options.onProgress({percent: done / total * 100}); }
+                return next(i + 1);
+            });
+        };
+        return next(0);
+    }).then(function() {
+        return post(url('complete', '&id=' + encodeURIComponent(id)));
+    }).then(function(res) {
+        storage.remove();
+        return res;
+    });
+}`
+
+// Chunked 开启分片上传
+// chunkSize为分片字节数，不大于0时使用DefaultChunkSize；
+// 文件总大小受UploadOptions.MaxSize限制。分片上传通过el-upload的http-request实现，仅支持Element UI。
+// 会话存储的会话数和分片总字节数有上限（见ChunkStoreOptions），
+// 本地目录存储的MaxBytes默认为DefaultMaxFileChunkBytes，应不小于MaxSize并小于磁盘的可用空间
+//
+// 使用示例：
+//
+//	Elm.UploadFile("video", "视频", "/upload/video").
+//	    Accept("video/*").
+//	    Chunked(8 << 20).
+//	    Store(storage, formbuilder.UploadOptions{
+//	        MaxSize: 2 << 30,
+//	        ChunkStore: formbuilder.NewFileChunkStore("./runtime/chunks", formbuilder.ChunkStoreOptions{
+//	            MaxSessions: 100,
+//	            MaxBytes:    20 << 30,
+//	        }),
+//	    })
+func (u *Upload) Chunked(chunkSize int64) *Upload {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	u.data.Props["chunkSize"] = chunkSize
+	u.data.Props["httpRequest"] = JSFunc(chunkUploadScript)
+	return u
+}
+
+// chunkSize 返回分片字节数，未开启分片上传时返回0
+func (u *Upload) chunkSize() int64 {
+	switch v := u.data.Props["chunkSize"].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	}
+	return 0
+}
+
+// chunkInit 创建会话请求
+type chunkInit struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// chunkHandler 分片上传请求的处理
+type chunkHandler struct {
+	upload  *Upload
+	storage Storage
+	opt     UploadOptions
+	mu      sync.Mutex // 串行化会话的读写，避免并发分片丢失进度
+}
+
+// serve 按op分发分片上传请求
+func (h *chunkHandler) serve(w http.ResponseWriter, r *http.Request, op string) {
+	switch op {
+	case "init":
+		h.init(w, r)
+	case "chunk":
+		h.chunk(w, r)
+	case "complete":
+		h.complete(w, r)
+	default:
+		writeUploadError(w, http.StatusBadRequest, "unknown operation")
+	}
+}
+
+// init 创建上传会话，相同文件的未完成会话会被恢复
+func (h *chunkHandler) init(w http.ResponseWriter, r *http.Request) {
+	var req chunkInit
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeUploadError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	if req.Size <= 0 || req.Name == "" {
+		writeUploadError(w, http.StatusBadRequest, "请选择要上传的文件")
+		return
+	}
+	if req.Size > h.opt.MaxSize {
+		writeUploadError(w, http.StatusRequestEntityTooLarge, "文件大小不能超过"+formatSize(h.opt.MaxSize))
+		return
+	}
+
+	ctx := r.Context()
+	chunkSize := h.upload.chunkSize()
+	checksum := strings.ToLower(req.Checksum)
+	if req.ID != "" {
+		session, err := h.opt.ChunkStore.Session(ctx, req.ID)
+		if err == nil && session.Name == req.Name && session.Size == req.Size &&
+			session.ChunkSize == chunkSize && session.Checksum == checksum {
+			writeChunkSession(w, session)
+			return
+		}
+	}
+
+	session := &ChunkSession{
+		ID:        newSessionID(),
+		Name:      req.Name,
+		Size:      req.Size,
+		ChunkSize: chunkSize,
+		Checksum:  checksum,
+		Uploaded:  []int{},
+		CreatedAt: time.Now(),
+	}
+	if err := h.opt.ChunkStore.SaveSession(ctx, session); err != nil {
+		if errors.Is(err, ErrChunkStoreFull) {
+			writeUploadError(w, http.StatusServiceUnavailable, "上传繁忙，请稍后重试")
+			return
+		}
+		writeUploadError(w, http.StatusInternalServerError, "创建上传会话失败")
+		return
+	}
+	writeChunkSession(w, session)
+}
+
+// chunk 接收单个分片
+// 分片大小必须与会话一致，提供X-Chunk-Checksum时校验分片内容
+func (h *chunkHandler) chunk(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(r.URL.Query().Get("index"))
+	if err != nil || index < 0 || index >= session.Total() {
+		writeUploadError(w, http.StatusBadRequest, "分片序号不正确")
+		return
+	}
+
+	expected := session.chunkLength(index)
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, expected))
+	if err != nil || int64(len(data)) != expected {
+		writeUploadError(w, http.StatusBadRequest, "分片大小不正确")
+		return
+	}
+	if sum := r.Header.Get("X-Chunk-Checksum"); sum != "" && !checksumEqual(sum, data) {
+		writeUploadError(w, http.StatusBadRequest, "分片校验失败")
+		return
+	}
+	if err := h.opt.ChunkStore.WriteChunk(ctx, session.ID, index, bytes.NewReader(data)); err != nil {
+		switch {
+		case errors.Is(err, ErrChunkStoreFull):
+			writeUploadError(w, http.StatusServiceUnavailable, "上传繁忙，请稍后重试")
+		case errors.Is(err, ErrSessionNotFound):
+			writeUploadError(w, http.StatusNotFound, "上传会话不存在或已过期")
+		default:
+			writeUploadError(w, http.StatusInternalServerError, "分片保存失败")
+		}
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	session, err = h.opt.ChunkStore.Session(ctx, session.ID)
+	if err != nil {
+		writeUploadError(w, http.StatusNotFound, "上传会话不存在或已过期")
+		return
+	}
+	session.markUploaded(index)
+	if err := h.opt.ChunkStore.SaveSession(ctx, session); err != nil {
+		writeUploadError(w, http.StatusInternalServerError, "分片保存失败")
+		return
+	}
+	writeChunkSession(w, session)
+}
+
+// complete 合并分片，校验文件类型和校验和后写入Storage
+func (h *chunkHandler) complete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	session, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	if !session.Complete() {
+		writeUploadError(w, http.StatusBadRequest, "文件分片不完整")
+		return
+	}
+
+	// 第一遍读取文件头和校验和，通过后再写入存储，避免保存不合法的文件
+	hash := sha256.New()
+	head := &prefixWriter{limit: 512}
+	if err := copyChunks(ctx, h.opt.ChunkStore, session, io.MultiWriter(hash, head)); err != nil {
+		writeUploadError(w, http.StatusInternalServerError, "文件读取失败")
+		return
+	}
+	if !acceptContent(propString(h.upload.data.Props, "accept"), session.Name, head.buf) {
+		h.opt.ChunkStore.Delete(ctx, session.ID)
+		writeUploadError(w, http.StatusUnsupportedMediaType, "不支持的文件类型")
+		return
+	}
+	if session.Checksum != "" && hex.EncodeToString(hash.Sum(nil)) != session.Checksum {
+		h.opt.ChunkStore.Delete(ctx, session.ID)
+		writeUploadError(w, http.StatusBadRequest, "文件校验失败")
+		return
+	}
+
+	file := UploadedFile{Name: session.Name, Size: session.Size}
+	if h.upload.isImageUpload() {
+		chunks := openChunks(ctx, h.opt.ChunkStore, session)
+		cfg, err := h.upload.checkImage(chunks, session.Size)
+		chunks.Close()
+		if err != nil {
+			h.opt.ChunkStore.Delete(ctx, session.ID)
+			writeUploadError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		file.Width, file.Height = cfg.Width, cfg.Height
+	}
+
+	name := storageName(session.Name, head.buf)
+	chunks := openChunks(ctx, h.opt.ChunkStore, session)
+	url, err := h.storage.Save(ctx, name, chunks)
+	chunks.Close()
+	if err != nil {
+		writeUploadError(w, http.StatusInternalServerError, "文件保存失败")
+		return
+	}
+	file.URL = url
+	if h.upload.hasThumbnail() {
+		chunks := openChunks(ctx, h.opt.ChunkStore, session)
+		file.ThumbURL, _, err = h.upload.saveThumbnail(ctx, h.storage, name, chunks)
+		chunks.Close()
+		if err != nil {
+			removeUploads(ctx, h.storage, []string{name})
+			writeUploadError(w, http.StatusInternalServerError, "缩略图生成失败")
+			return
+		}
+	}
+	h.opt.ChunkStore.Delete(ctx, session.ID)
+
+	writeJSON(w, http.StatusOK, UploadResponse{Code: http.StatusOK, Message: "上传成功", Data: file})
+}
+
+// session 读取请求中id参数对应的会话
+func (h *chunkHandler) session(w http.ResponseWriter, r *http.Request) (*ChunkSession, bool) {
+	session, err := h.opt.ChunkStore.Session(r.Context(), r.URL.Query().Get("id"))
+	if errors.Is(err, ErrSessionNotFound) {
+		writeUploadError(w, http.StatusNotFound, "上传会话不存在或已过期")
+		return nil, false
+	}
+	if err != nil {
+		writeUploadError(w, http.StatusInternalServerError, "读取上传会话失败")
+		return nil, false
+	}
+	return session, true
+}
+
+// writeChunkSession 输出会话状态
+func writeChunkSession(w http.ResponseWriter, session *ChunkSession) {
+	writeJSON(w, http.StatusOK, UploadResponse{
+		Code:    http.StatusOK,
+		Message: "ok",
+		Data: map[string]interface{}{
+			"id":        session.ID,
+			"chunkSize": session.ChunkSize,
+			"total":     session.Total(),
+			"uploaded":  session.Uploaded,
+		},
+	})
+}
+
+// copyChunks 按顺序将全部分片写入dst
+func copyChunks(ctx context.Context, store ChunkStore, session *ChunkSession, dst io.Writer) error {
+	for i := 0; i < session.Total(); i++ {
+		chunk, err := store.OpenChunk(ctx, session.ID, i)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, chunk)
+		chunk.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openChunks 返回按顺序读取全部分片的Reader
+// 提前关闭时后台的读取随之结束
+func openChunks(ctx context.Context, store ChunkStore, session *ChunkSession) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(copyChunks(ctx, store, session, pw))
+	}()
+	return pr
+}
+
+// prefixWriter 保留写入内容的前limit个字节
+type prefixWriter struct {
+	buf   []byte
+	limit int
+}
+
+// Write 实现io.Writer接口
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	if rest := p.limit - len(p.buf); rest > 0 {
+		if len(b) < rest {
+			rest = len(b)
+		}
+		p.buf = append(p.buf, b[:rest]...)
+	}
+	return len(b), nil
+}
+
+// checksumEqual 判断数据的SHA-256是否与十六进制的校验和一致
+func checksumEqual(sum string, data []byte) bool {
+	digest := sha256.Sum256(data)
+	return strings.EqualFold(sum, hex.EncodeToString(digest[:]))
+}
+
+// newSessionID 生成32位十六进制的会话ID
+func newSessionID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,191 @@
+package formbuilder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upload_chunk_test.go 测试分片上传
+
+// chunkClient 模拟前端分片上传的测试客户端
+type chunkClient struct {
+	t       *testing.T
+	handler http.Handler
+}
+
+// post 发送请求并返回状态码和响应数据
+func (c *chunkClient) post(query string, body []byte, headers map[string]string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodPost, "/upload?"+query, bytes.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+
+	var resp map[string]interface{}
+	require.NoError(c.t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+// init 创建上传会话
+func (c *chunkClient) init(id, name string, size int, checksum string) (int, map[string]interface{}) {
+	body, _ := json.Marshal(map[string]interface{}{"id": id, "name": name, "size": size, "checksum": checksum})
+	code, resp := c.post("op=init", body, nil)
+	data, _ := resp["data"].(map[string]interface{})
+	if data == nil {
+		data = resp
+	}
+	return code, data
+}
+
+// chunk 上传分片
+func (c *chunkClient) chunk(id string, index int, data []byte) (int, map[string]interface{}) {
+	sum := sha256.Sum256(data)
+	return c.post("op=chunk&id="+id+"&index="+strconv.Itoa(index), data,
+		map[string]string{"X-Chunk-Checksum": hex.EncodeToString(sum[:])})
+}
+
+// TestUploadChunked 测试分片上传组件属性
+func TestUploadChunked(t *testing.T) {
+	props := NewUpload("video", "视频").Chunked(0).Build()["props"].(map[string]interface{})
+	assert.Equal(t, int64(DefaultChunkSize), props["chunkSize"])
+	assert.Equal(t, JSFunc(chunkUploadScript), props["httpRequest"])
+
+	upload := NewUpload("video", "视频").Chunked(1 << 20)
+	assert.Equal(t, int64(1<<20), upload.chunkSize())
+	assert.Equal(t, int64(0), NewUpload("doc", "文档").chunkSize())
+}
+
+// TestChunkUploadHandler 测试分片上传接口
+func TestChunkUploadHandler(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 250))
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	newClient := func(storage Storage, accept string) *chunkClient {
+		upload := NewUpload("doc", "文档").Action("/upload").Chunked(1000)
+		if accept != "" {
+			upload.Accept(accept)
+		}
+		return &chunkClient{t: t, handler: UploadHandler(upload, storage, UploadOptions{MaxSize: 1 << 20})}
+	}
+
+	t.Run("Complete", func(t *testing.T) {
+		storage := &memoryStorage{}
+		client := newClient(storage, "text/plain")
+
+		code, session := client.init("", "a.txt", len(content), checksum)
+		require.Equal(t, http.StatusOK, code)
+		id := session["id"].(string)
+		assert.Equal(t, float64(1000), session["chunkSize"])
+		assert.Equal(t, float64(3), session["total"])
+
+		for i := 0; i < 3; i++ {
+			end := (i + 1) * 1000
+			if end > len(content) {
+				end = len(content)
+			}
+			code, _ := client.chunk(id, i, content[i*1000:end])
+			require.Equal(t, http.StatusOK, code)
+		}
+
+		code, resp := client.post("op=complete&id="+id, nil, nil)
+		require.Equal(t, http.StatusOK, code)
+		data := resp["data"].(map[string]interface{})
+		assert.Equal(t, "a.txt", data["name"])
+		assert.Equal(t, float64(len(content)), data["size"])
+		require.Len(t, storage.files, 1)
+		for _, saved := range storage.files {
+			assert.Equal(t, content, saved)
+		}
+
+		code, _ = client.post("op=complete&id="+id, nil, nil)
+		assert.Equal(t, http.StatusNotFound, code, "完成后会话应被删除")
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		client := newClient(&memoryStorage{}, "")
+		_, session := client.init("", "a.txt", len(content), "")
+		id := session["id"].(string)
+		client.chunk(id, 1, content[1000:2000])
+
+		_, resumed := client.init(id, "a.txt", len(content), "")
+		assert.Equal(t, id, resumed["id"])
+		assert.Equal(t, []interface{}{float64(1)}, resumed["uploaded"])
+
+		_, other := client.init(id, "b.txt", len(content), "")
+		assert.NotEqual(t, id, other["id"], "不同文件不应恢复会话")
+
+		code, resp := client.post("op=complete&id="+id, nil, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "文件分片不完整", resp["message"])
+	})
+
+	t.Run("InvalidChunk", func(t *testing.T) {
+		client := newClient(&memoryStorage{}, "")
+		_, session := client.init("", "a.txt", len(content), "")
+		id := session["id"].(string)
+
+		code, resp := client.chunk(id, 3, content[:500])
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "分片序号不正确", resp["message"])
+
+		code, resp = client.chunk(id, 0, content[:999])
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "分片大小不正确", resp["message"])
+
+		code, resp = client.post("op=chunk&id="+id+"&index=0", content[:1000], map[string]string{"X-Chunk-Checksum": checksum})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "分片校验失败", resp["message"])
+
+		code, _ = client.chunk(newSessionID(), 0, content[:1000])
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		storage := &memoryStorage{}
+		client := newClient(storage, "")
+		_, session := client.init("", "a.txt", 10, strings.Repeat("0", 64))
+		id := session["id"].(string)
+		client.chunk(id, 0, content[:10])
+
+		code, resp := client.post("op=complete&id="+id, nil, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "文件校验失败", resp["message"])
+		assert.Empty(t, storage.files)
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		client := newClient(&memoryStorage{}, "image/*")
+		_, session := client.init("", "a.png", 10, "")
+		id := session["id"].(string)
+		client.chunk(id, 0, content[:10])
+
+		code, _ := client.post("op=complete&id="+id, nil, nil)
+		assert.Equal(t, http.StatusUnsupportedMediaType, code)
+	})
+
+	t.Run("MaxSize", func(t *testing.T) {
+		code, resp := newClient(&memoryStorage{}, "").init("", "a.bin", 2<<20, "")
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+		assert.Equal(t, "文件大小不能超过1MB", resp["message"])
+	})
+
+	t.Run("NotChunked", func(t *testing.T) {
+		handler := UploadHandler(NewUpload("doc", "文档"), &memoryStorage{})
+		req := httptest.NewRequest(http.MethodPost, "/upload?op=init", strings.NewReader(`{}`))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "未开启分片上传时按普通上传处理")
+	})
+}
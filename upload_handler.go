@@ -0,0 +1,343 @@
+package formbuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// upload_handler.go 实现Upload组件的服务端上传接口
+// 按组件自身的accept、limit、name、multiple属性校验上传的文件，
+// 通过Storage保存后返回Element/iView上传组件可以直接使用的JSON
+
+// DefaultUploadMaxSize 单个上传文件默认的最大字节数
+const DefaultUploadMaxSize = 10 << 20
+
+// uploadSuccessScript 上传成功后将接口返回的地址写入文件对象，form-create据此生成字段值
+const uploadSuccessScript = "function(res, file) { if (res && res.data && res.data.url) { file.url = res.data.url;" +
+	" if (res.data.thumbUrl) { file.thumbUrl = res.data.thumbUrl; } } }"
+
+// UploadOptions 上传接口选项
+type UploadOptions struct {
+	// MaxSize 单个文件的最大字节数，为0时使用DefaultUploadMaxSize
+	MaxSize int64
+
+	// ChunkStore 分片上传的会话存储，组件开启Chunked时使用，为nil时使用内存存储
+	ChunkStore ChunkStore
+}
+
+// UploadedFile 已保存的上传文件
+type UploadedFile struct {
+	URL      string `json:"url"`                // 文件访问地址
+	Name     string `json:"name"`               // 原始文件名
+	Size     int64  `json:"size"`               // 文件字节数
+	Width    int    `json:"width,omitempty"`    // 图片宽度，设置了图片约束或缩略图时返回
+	Height   int    `json:"height,omitempty"`   // 图片高度
+	ThumbURL string `json:"thumbUrl,omitempty"` // 缩略图地址
+}
+
+// UploadResponse 上传成功的响应
+// 上传失败时返回非2xx状态码和ErrorResponse，上传组件据此触发on-error
+//
+// 响应示例：
+//
+//	{"code": 200, "message": "上传成功", "data": {"url": "/uploads/2024/01/02/3f2a....jpg", "name": "a.jpg", "size": 1024}}
+type UploadResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"` // 单个文件为UploadedFile，一次上传多个文件时为[]UploadedFile
+}
+
+// Store 设置上传文件的存储，由Form.Mount在组件的action地址注册上传接口
+// 同时设置onSuccess，将接口返回的文件地址作为字段值
+//
+// 使用示例：
+//
+//	storage := formbuilder.NewLocalStorage("./public/uploads", "/uploads")
+//	Elm.UploadImage("cover", "封面", "/upload/cover").
+//	    Store(storage, formbuilder.UploadOptions{MaxSize: 2 << 20})
+func (u *Upload) Store(storage Storage, opts ...UploadOptions) *Upload {
+	u.storage = storage
+	if len(opts) > 0 {
+		u.uploadOptions = opts[0]
+	}
+	if _, ok := u.data.Props["onSuccess"]; !ok {
+		u.data.Props["onSuccess"] = JSFunc(uploadSuccessScript)
+	}
+	return u
+}
+
+// endpoints 实现endpointProvider接口
+func (u *Upload) endpoints() map[string]http.Handler {
+	action := propString(u.data.Props, "action")
+	if u.storage == nil || action == "" {
+		return nil
+	}
+	return map[string]http.Handler{action: UploadHandler(u, u.storage, u.uploadOptions)}
+}
+
+// UploadHandler 返回Upload组件的上传接口
+// 组件开启Chunked时同时处理分片上传请求（见Upload.Chunked）。
+// 普通上传接收multipart/form-data请求，按组件的属性校验文件：
+//   - name: 文件字段名，默认为 "file"
+//   - multiple: 未开启时一次只能上传一个文件
+//   - limit: 一次请求中文件数量的上限（上传组件每个文件单独发送请求，总数由前端限制）
+//   - accept: 文件类型，支持 ".jpg" 形式的扩展名、"image/png" 和 "image/*" 形式的MIME类型，MIME类型按文件内容判断
+//
+// 设置了ImageConstraints或Thumbnail时还会解码图片并检查约束，不符合时返回422；
+// 文件大小超过MaxSize时返回413，类型不符时返回415，保存失败时返回500
+func UploadHandler(u *Upload, storage Storage, opts ...UploadOptions) http.Handler {
+	var opt UploadOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxSize <= 0 {
+		opt.MaxSize = DefaultUploadMaxSize
+	}
+	if opt.ChunkStore == nil {
+		opt.ChunkStore = NewMemoryChunkStore()
+	}
+	chunks := &chunkHandler{upload: u, storage: storage, opt: opt}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeUploadError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if op := r.URL.Query().Get("op"); op != "" && u.chunkSize() > 0 {
+			chunks.serve(w, r, op)
+			return
+		}
+
+		props := u.data.Props
+		name := withDefault(propString(props, "name"), "file")
+		multiple := propBool(props, "multiple")
+		limit, _ := propInt(props, "limit")
+
+		count := int64(1)
+		if multiple && limit > 1 {
+			count = int64(limit)
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, opt.MaxSize*count+1<<20)
+		if err := r.ParseMultipartForm(DefaultMaxMemory); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeUploadError(w, http.StatusRequestEntityTooLarge, "文件大小不能超过"+formatSize(opt.MaxSize))
+				return
+			}
+			writeUploadError(w, http.StatusBadRequest, "invalid multipart body")
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		headers := append(r.MultipartForm.File[name], r.MultipartForm.File[name+"[]"]...)
+		switch {
+		case len(headers) == 0:
+			writeUploadError(w, http.StatusBadRequest, "请选择要上传的文件")
+			return
+		case len(headers) > 1 && !multiple:
+			writeUploadError(w, http.StatusBadRequest, "一次只能上传一个文件")
+			return
+		case limit > 0 && len(headers) > limit:
+			writeUploadError(w, http.StatusBadRequest, fmt.Sprintf("最多只能上传%d个文件", limit))
+			return
+		}
+
+		accept := propString(props, "accept")
+		files := make([]UploadedFile, len(headers))
+		heads := make([][]byte, len(headers))
+		for i, h := range headers {
+			if h.Size > opt.MaxSize {
+				writeUploadError(w, http.StatusRequestEntityTooLarge, "文件大小不能超过"+formatSize(opt.MaxSize))
+				return
+			}
+			head, err := readUploadHead(h)
+			if err != nil {
+				writeUploadError(w, http.StatusBadRequest, "文件读取失败")
+				return
+			}
+			heads[i] = head
+			if !acceptContent(accept, h.Filename, head) {
+				writeUploadError(w, http.StatusUnsupportedMediaType, "不支持的文件类型")
+				return
+			}
+			files[i] = UploadedFile{Name: h.Filename, Size: h.Size}
+			if u.isImageUpload() {
+				cfg, err := checkUploadImage(u, h)
+				if err != nil {
+					writeUploadError(w, http.StatusUnprocessableEntity, err.Error())
+					return
+				}
+				files[i].Width, files[i].Height = cfg.Width, cfg.Height
+			}
+		}
+
+		// 任意一个文件保存失败时删除本次已保存的文件，避免留下无人引用的文件
+		var saved []string
+		for i, h := range headers {
+			name := storageName(h.Filename, heads[i])
+			url, err := saveUpload(r.Context(), storage, name, h)
+			if err != nil {
+				removeUploads(r.Context(), storage, saved)
+				writeUploadError(w, http.StatusInternalServerError, "文件保存失败")
+				return
+			}
+			saved = append(saved, name)
+			files[i].URL = url
+			if u.hasThumbnail() {
+				url, thumb, err := saveUploadThumbnail(r.Context(), u, storage, name, h)
+				if err != nil {
+					removeUploads(r.Context(), storage, saved)
+					writeUploadError(w, http.StatusInternalServerError, "缩略图生成失败")
+					return
+				}
+				saved = append(saved, thumb)
+				files[i].ThumbURL = url
+			}
+		}
+
+		resp := UploadResponse{Code: http.StatusOK, Message: "上传成功", Data: files[0]}
+		if len(files) > 1 {
+			resp.Data = files
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// saveUpload 将上传的文件以name保存到存储中
+func saveUpload(ctx context.Context, storage Storage, name string, h *multipart.FileHeader) (string, error) {
+	file, err := h.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return storage.Save(ctx, name, file)
+}
+
+// checkUploadImage 检查上传的图片
+func checkUploadImage(u *Upload, h *multipart.FileHeader) (image.Config, error) {
+	file, err := h.Open()
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer file.Close()
+	return u.checkImage(file, h.Size)
+}
+
+// saveUploadThumbnail 生成上传图片的缩略图
+func saveUploadThumbnail(ctx context.Context, u *Upload, storage Storage, name string, h *multipart.FileHeader) (string, string, error) {
+	file, err := h.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+	return u.saveThumbnail(ctx, storage, name, file)
+}
+
+// removeUploads 删除已保存的文件，存储未实现StorageDeleter时忽略
+func removeUploads(ctx context.Context, storage Storage, names []string) {
+	deleter, ok := storage.(StorageDeleter)
+	if !ok {
+		return
+	}
+	for _, name := range names {
+		_ = deleter.Delete(ctx, name)
+	}
+}
+
+// writeUploadError 输出上传失败的响应
+func writeUploadError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Code: status, Message: message})
+}
+
+// readUploadHead 读取文件开头用于类型检测的512字节
+func readUploadHead(h *multipart.FileHeader) ([]byte, error) {
+	file, err := h.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+// acceptContent 根据文件名和文件开头的内容判断是否符合accept属性
+// MIME类型按文件内容检测；内容无法识别时（如文本、压缩包格式的文档）使用扩展名对应的类型，
+// 但图片、音视频和PDF必须能从内容识别，避免修改扩展名绕过检查
+func acceptContent(accept, filename string, head []byte) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+
+	ext := strings.ToLower(path.Ext(filename))
+	detected := mediaType(http.DetectContentType(head))
+	types := []string{detected}
+	if isGenericType(detected) {
+		if byExt := mediaType(mime.TypeByExtension(ext)); byExt != "" && !isSniffable(byExt) {
+			types = append(types, byExt)
+		}
+	}
+
+	for _, token := range strings.Split(accept, ",") {
+		token = strings.ToLower(strings.TrimSpace(token))
+		switch {
+		case token == "":
+			continue
+		case strings.HasPrefix(token, "."):
+			if ext == token {
+				return true
+			}
+		default:
+			for _, t := range types {
+				if token == t || (strings.HasSuffix(token, "/*") && strings.HasPrefix(t, strings.TrimSuffix(token, "*"))) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// mediaType 去除Content-Type中的参数
+func mediaType(contentType string) string {
+	t, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// isGenericType 判断是否为无法确定具体格式的检测结果
+func isGenericType(t string) bool {
+	switch t {
+	case "application/octet-stream", "text/plain", "application/zip":
+		return true
+	}
+	return false
+}
+
+// isSniffable 判断该类型的文件是否能从内容识别
+func isSniffable(t string) bool {
+	return strings.HasPrefix(t, "image/") || strings.HasPrefix(t, "audio/") ||
+		strings.HasPrefix(t, "video/") || t == "application/pdf"
+}
+
+// formatSize 格式化字节数
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", size>>10)
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,205 @@
+package formbuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upload_handler_test.go 测试上传接口
+
+// pngHeader PNG文件头，用于文件类型检测
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// memoryStorage 保存在内存中的测试存储
+type memoryStorage struct {
+	files map[string][]byte
+	err   error
+	limit int // 大于0时保存的文件数达到limit后返回错误
+}
+
+// Save 实现Storage接口
+func (s *memoryStorage) Save(ctx context.Context, name string, content io.Reader) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	if s.limit > 0 && len(s.files) >= s.limit {
+		return "", errors.New("disk full")
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", err
+	}
+	if s.files == nil {
+		s.files = make(map[string][]byte)
+	}
+	s.files[name] = data
+	return "/uploads/" + name, nil
+}
+
+// Delete 实现StorageDeleter接口
+func (s *memoryStorage) Delete(ctx context.Context, name string) error {
+	delete(s.files, name)
+	return nil
+}
+
+// uploadFile 测试上传的文件
+type uploadFile struct {
+	field    string
+	filename string
+	content  []byte
+}
+
+// newUploadRequest 创建multipart上传请求
+func newUploadRequest(t *testing.T, files ...uploadFile) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, f := range files {
+		fw, err := mw.CreateFormFile(f.field, f.filename)
+		require.NoError(t, err)
+		_, err = fw.Write(f.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+// TestUploadHandler 测试上传接口
+func TestUploadHandler(t *testing.T) {
+	serve := func(u *Upload, storage Storage, req *http.Request, opts ...UploadOptions) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		UploadHandler(u, storage, opts...).ServeHTTP(rec, req)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec, body
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		storage := &memoryStorage{}
+		upload := Elm.UploadImage("cover", "封面", "/upload")
+		rec, body := serve(upload, storage, newUploadRequest(t, uploadFile{"file", "cover.png", pngHeader}))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		data := body["data"].(map[string]interface{})
+		assert.Equal(t, "cover.png", data["name"])
+		assert.True(t, strings.HasPrefix(data["url"].(string), "/uploads/"))
+		assert.True(t, strings.HasSuffix(data["url"].(string), ".png"))
+		assert.Len(t, storage.files, 1)
+	})
+
+	t.Run("CustomName", func(t *testing.T) {
+		upload := NewUpload("doc", "文档").Action("/upload").Name("attachment")
+		rec, _ := serve(upload, &memoryStorage{}, newUploadRequest(t, uploadFile{"attachment", "a.txt", []byte("text")}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec, body := serve(upload, &memoryStorage{}, newUploadRequest(t, uploadFile{"file", "a.txt", []byte("text")}))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "请选择要上传的文件", body["message"])
+	})
+
+	t.Run("Multiple", func(t *testing.T) {
+		files := []uploadFile{{"file", "a.txt", []byte("a")}, {"file", "b.txt", []byte("b")}, {"file", "c.txt", []byte("c")}}
+
+		rec, body := serve(NewUpload("docs", "文档"), &memoryStorage{}, newUploadRequest(t, files[:2]...))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "一次只能上传一个文件", body["message"])
+
+		upload := NewUpload("docs", "文档").Multiple(true).Limit(2)
+		rec, body = serve(upload, &memoryStorage{}, newUploadRequest(t, files[:2]...))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, body["data"], 2)
+
+		rec, body = serve(upload, &memoryStorage{}, newUploadRequest(t, files...))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "最多只能上传2个文件", body["message"])
+	})
+
+	t.Run("MaxSize", func(t *testing.T) {
+		req := newUploadRequest(t, uploadFile{"file", "a.txt", bytes.Repeat([]byte("a"), 2048)})
+		rec, body := serve(NewUpload("doc", "文档"), &memoryStorage{}, req, UploadOptions{MaxSize: 1024})
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Equal(t, "文件大小不能超过1KB", body["message"])
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		upload := Elm.UploadImage("cover", "封面", "/upload")
+		rec, body := serve(upload, &memoryStorage{}, newUploadRequest(t, uploadFile{"file", "fake.png", []byte("<html><script>alert(1)</script>")}))
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		assert.Equal(t, "不支持的文件类型", body["message"])
+	})
+
+	t.Run("StorageError", func(t *testing.T) {
+		rec, body := serve(NewUpload("doc", "文档"), &memoryStorage{err: errors.New("disk full")}, newUploadRequest(t, uploadFile{"file", "a.txt", []byte("a")}))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "文件保存失败", body["message"])
+	})
+
+	t.Run("PartialFailure", func(t *testing.T) {
+		storage := &memoryStorage{limit: 1}
+		upload := NewUpload("docs", "文档").Multiple(true).Limit(2)
+		rec, _ := serve(upload, storage, newUploadRequest(t, uploadFile{"file", "a.txt", []byte("a")}, uploadFile{"file", "b.txt", []byte("b")}))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, storage.files, "已保存的文件应被删除")
+	})
+
+	t.Run("ExtensionFromContent", func(t *testing.T) {
+		storage := &memoryStorage{}
+		upload := NewUpload("file", "文件").Accept("image/png")
+		rec, body := serve(upload, storage, newUploadRequest(t, uploadFile{"file", "x.html", append(append([]byte{}, pngHeader...), "<script>alert(1)</script>"...)}))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, strings.HasSuffix(body["data"].(map[string]interface{})["url"].(string), ".png"))
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		rec, _ := serve(NewUpload("doc", "文档"), &memoryStorage{}, httptest.NewRequest(http.MethodGet, "/upload", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+// TestAcceptFile 测试文件类型匹配
+func TestAcceptFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		filename string
+		content  []byte
+		expected bool
+	}{
+		{"Empty", "", "a.exe", []byte("MZ"), true},
+		{"Wildcard", "image/*", "a.png", pngHeader, true},
+		{"ExactType", "image/jpeg,image/png", "a.png", pngHeader, true},
+		{"Extension", ".pdf, .PNG", "A.PNG", pngHeader, true},
+		{"SpoofedExtension", "image/*", "a.png", []byte("plain text"), false},
+		{"GenericByExtension", "application/json", "a.json", []byte(`{"a": 1}`), true},
+		{"SVGNotImage", "image/*", "a.svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, acceptContent(tt.accept, tt.filename, tt.content))
+		})
+	}
+}
+
+// TestUploadStore 测试上传存储设置与接口挂载
+func TestUploadStore(t *testing.T) {
+	upload := Elm.UploadImage("cover", "封面", "/upload/cover").Store(&memoryStorage{})
+	assert.Equal(t, JSFunc(uploadSuccessScript), upload.Build()["props"].(map[string]interface{})["onSuccess"])
+
+	form := NewElmForm("/submit", []Component{upload, Elm.UploadFile("doc", "文档", "/upload/doc")}, nil)
+	endpoints := form.Endpoints()
+	assert.Len(t, endpoints, 1)
+	assert.Contains(t, endpoints, "/upload/cover")
+}
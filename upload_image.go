@@ -0,0 +1,200 @@
+package formbuilder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // 注册GIF解码器
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"path"
+	"strings"
+)
+
+// upload_image.go 实现图片上传的服务端校验和缩略图生成
+// 使用标准库解码图片，支持JPEG、PNG和GIF格式
+
+// maxImagePixels 允许处理的图片最大像素数，防止解码超大图片耗尽内存
+// 所有图片校验和缩略图生成前都会先读取图片头检查像素数
+const maxImagePixels = 40000000
+
+// aspectTolerance 宽高比允许的相对误差
+const aspectTolerance = 0.01
+
+// 图片校验失败的错误信息
+var (
+	errNotImage      = errors.New("请上传有效的图片")
+	errImageTooLarge = errors.New("图片尺寸过大")
+	errAspectRatio   = errors.New("图片宽高比不符合要求")
+)
+
+// ImageConstraints 图片上传约束
+// 字段为0时表示不限制
+type ImageConstraints struct {
+	MinWidth    int     // 最小宽度（像素）
+	MinHeight   int     // 最小高度（像素）
+	MaxWidth    int     // 最大宽度（像素）
+	MaxHeight   int     // 最大高度（像素）
+	AspectRatio float64 // 宽高比，如 16.0 / 9，允许1%的误差
+	MaxBytes    int64   // 最大字节数
+}
+
+// check 检查图片尺寸和大小
+func (c ImageConstraints) check(cfg image.Config, size int64) error {
+	switch {
+	case c.MaxBytes > 0 && size > c.MaxBytes:
+		return fmt.Errorf("图片大小不能超过%s", formatSize(c.MaxBytes))
+	case c.MinWidth > 0 && cfg.Width < c.MinWidth:
+		return fmt.Errorf("图片宽度不能小于%d像素", c.MinWidth)
+	case c.MinHeight > 0 && cfg.Height < c.MinHeight:
+		return fmt.Errorf("图片高度不能小于%d像素", c.MinHeight)
+	case c.MaxWidth > 0 && cfg.Width > c.MaxWidth:
+		return fmt.Errorf("图片宽度不能超过%d像素", c.MaxWidth)
+	case c.MaxHeight > 0 && cfg.Height > c.MaxHeight:
+		return fmt.Errorf("图片高度不能超过%d像素", c.MaxHeight)
+	case c.AspectRatio > 0 && cfg.Height > 0 &&
+		math.Abs(float64(cfg.Width)/float64(cfg.Height)-c.AspectRatio) > c.AspectRatio*aspectTolerance:
+		return errAspectRatio
+	}
+	return nil
+}
+
+// ImageConstraints 设置图片约束，由上传接口解码图片后检查
+// 设置后上传的文件必须是可以解码的JPEG、PNG或GIF图片
+//
+// 使用示例：
+//
+//	Elm.UploadImage("banner", "横幅", "/upload/banner").
+//	    ImageConstraints(formbuilder.ImageConstraints{MinWidth: 1200, AspectRatio: 16.0 / 9, MaxBytes: 2 << 20}).
+//	    Store(storage)
+func (u *Upload) ImageConstraints(c ImageConstraints) *Upload {
+	u.imageConstraints = &c
+	return u
+}
+
+// Thumbnail 设置上传接口生成的缩略图尺寸
+// 图片按比例缩小到width×height以内（为0的一边不限制），不会放大；
+// 缩略图地址以thumbUrl返回，并写入上传列表中文件对象的thumbUrl
+func (u *Upload) Thumbnail(width, height int) *Upload {
+	u.thumbWidth = width
+	u.thumbHeight = height
+	return u
+}
+
+// isImageUpload 判断上传接口是否需要解码图片
+func (u *Upload) isImageUpload() bool {
+	return u.imageConstraints != nil || u.hasThumbnail()
+}
+
+// hasThumbnail 判断是否需要生成缩略图
+func (u *Upload) hasThumbnail() bool {
+	return u.thumbWidth > 0 || u.thumbHeight > 0
+}
+
+// checkImage 解码图片头并检查约束，返回图片尺寸
+func (u *Upload) checkImage(r io.Reader, size int64) (image.Config, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return cfg, errNotImage
+	}
+	if !withinPixelLimit(cfg) {
+		return cfg, errImageTooLarge
+	}
+	if u.imageConstraints != nil {
+		if err := u.imageConstraints.check(cfg, size); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// withinPixelLimit 判断图片像素数是否在maxImagePixels以内
+func withinPixelLimit(cfg image.Config) bool {
+	return cfg.Width > 0 && cfg.Height > 0 && int64(cfg.Width)*int64(cfg.Height) <= maxImagePixels
+}
+
+// saveThumbnail 生成缩略图并保存，返回缩略图地址和存储路径
+// 缩略图与原图保存在同一目录，文件名追加 "_thumb"；JPEG原图生成JPEG，其他格式生成PNG
+func (u *Upload) saveThumbnail(ctx context.Context, storage Storage, name string, r io.Reader) (string, string, error) {
+	// 解码前先读取图片头检查像素数，已读取的部分与剩余内容拼接后完整解码
+	var head bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &head))
+	if err != nil {
+		return "", "", err
+	}
+	if !withinPixelLimit(cfg) {
+		return "", "", errImageTooLarge
+	}
+	img, format, err := image.Decode(io.MultiReader(&head, r))
+	if err != nil {
+		return "", "", err
+	}
+	thumb := resizeImage(img, u.thumbWidth, u.thumbHeight)
+
+	var buf bytes.Buffer
+	ext := ".png"
+	if format == "jpeg" {
+		ext = ".jpg"
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	thumbName := strings.TrimSuffix(name, path.Ext(name)) + "_thumb" + ext
+	url, err := storage.Save(ctx, thumbName, &buf)
+	return url, thumbName, err
+}
+
+// resizeImage 将图片按比例缩小到maxWidth×maxHeight以内
+// 使用区域平均采样，图片已经足够小时原样返回
+func resizeImage(src image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	scale := 1.0
+	if maxWidth > 0 && w > maxWidth {
+		scale = math.Min(scale, float64(maxWidth)/float64(w))
+	}
+	if maxHeight > 0 && h > maxHeight {
+		scale = math.Min(scale, float64(maxHeight)/float64(h))
+	}
+	if scale >= 1 {
+		return src
+	}
+	dw := max(1, int(math.Round(float64(w)*scale)))
+	dh := max(1, int(math.Round(float64(h)*scale)))
+
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0, sy1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			sx0, sx1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var sum [4]int
+			for sy := sy0; sy < sy1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := sx0; sx < sx1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					sum[0] += int(p[0])
+					sum[1] += int(p[1])
+					sum[2] += int(p[2])
+					sum[3] += int(p[3])
+				}
+			}
+			n := (sy1 - sy0) * (sx1 - sx0)
+			p := dst.Pix[y*dst.Stride+x*4:]
+			for i := range sum {
+				p[i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
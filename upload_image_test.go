@@ -0,0 +1,168 @@
+package formbuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upload_image_test.go 测试图片上传的校验和缩略图
+
+// encodeTestImage 生成指定尺寸的测试图片
+func encodeTestImage(t *testing.T, format string, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	} else {
+		require.NoError(t, png.Encode(&buf, img))
+	}
+	return buf.Bytes()
+}
+
+// TestImageConstraints 测试图片约束检查
+func TestImageConstraints(t *testing.T) {
+	cfg := image.Config{Width: 1600, Height: 900}
+	tests := []struct {
+		name        string
+		constraints ImageConstraints
+		size        int64
+		message     string
+	}{
+		{"NoLimit", ImageConstraints{}, 1 << 30, ""},
+		{"MaxBytes", ImageConstraints{MaxBytes: 1 << 20}, 2 << 20, "图片大小不能超过1MB"},
+		{"MinWidth", ImageConstraints{MinWidth: 1920}, 1, "图片宽度不能小于1920像素"},
+		{"MinHeight", ImageConstraints{MinHeight: 1080}, 1, "图片高度不能小于1080像素"},
+		{"MaxWidth", ImageConstraints{MaxWidth: 1200}, 1, "图片宽度不能超过1200像素"},
+		{"MaxHeight", ImageConstraints{MaxHeight: 800}, 1, "图片高度不能超过800像素"},
+		{"AspectRatio", ImageConstraints{AspectRatio: 16.0 / 9}, 1, ""},
+		{"WrongAspectRatio", ImageConstraints{AspectRatio: 4.0 / 3}, 1, "图片宽高比不符合要求"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.constraints.check(cfg, tt.size)
+			if tt.message == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.message)
+			}
+		})
+	}
+}
+
+// TestResizeImage 测试缩略图缩放
+func TestResizeImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+	}
+
+	thumb := resizeImage(src, 100, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), thumb.Bounds())
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, thumb.At(50, 25))
+
+	assert.Equal(t, image.Rect(0, 0, 200, 100), resizeImage(src, 0, 100).Bounds())
+	assert.Same(t, src, resizeImage(src, 800, 800), "不应放大图片")
+}
+
+// TestUploadImageHandler 测试上传接口的图片校验和缩略图
+func TestUploadImageHandler(t *testing.T) {
+	serve := func(u *Upload, storage Storage, files ...uploadFile) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		UploadHandler(u, storage).ServeHTTP(rec, newUploadRequest(t, files...))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body
+	}
+
+	t.Run("Constraints", func(t *testing.T) {
+		upload := Elm.UploadImage("cover", "封面", "/upload").
+			ImageConstraints(ImageConstraints{MinWidth: 100, AspectRatio: 2})
+
+		code, body := serve(upload, &memoryStorage{}, uploadFile{"file", "a.png", encodeTestImage(t, "png", 200, 100)})
+		require.Equal(t, http.StatusOK, code)
+		data := body["data"].(map[string]interface{})
+		assert.Equal(t, float64(200), data["width"])
+		assert.Equal(t, float64(100), data["height"])
+		assert.Nil(t, data["thumbUrl"])
+
+		code, body = serve(upload, &memoryStorage{}, uploadFile{"file", "a.png", encodeTestImage(t, "png", 80, 40)})
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, "图片宽度不能小于100像素", body["message"])
+	})
+
+	t.Run("NotDecodable", func(t *testing.T) {
+		upload := NewUpload("cover", "封面").Accept("image/*").ImageConstraints(ImageConstraints{})
+		webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")
+		code, body := serve(upload, &memoryStorage{}, uploadFile{"file", "a.webp", webp})
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, "请上传有效的图片", body["message"])
+	})
+
+	t.Run("TooManyPixels", func(t *testing.T) {
+		// GIF头声明65535×65535的画布，未设置缩略图时也要拒绝
+		gif := []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00;")
+		upload := NewUpload("cover", "封面").Accept("image/*").ImageConstraints(ImageConstraints{})
+		code, body := serve(upload, &memoryStorage{}, uploadFile{"file", "a.gif", gif})
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Equal(t, "图片尺寸过大", body["message"])
+
+		_, _, err := upload.saveThumbnail(context.Background(), &memoryStorage{}, "a.gif", bytes.NewReader(gif))
+		assert.ErrorIs(t, err, errImageTooLarge)
+	})
+
+	t.Run("Thumbnail", func(t *testing.T) {
+		storage := &memoryStorage{}
+		upload := Elm.UploadImage("cover", "封面", "/upload").Thumbnail(50, 50)
+
+		code, body := serve(upload, storage, uploadFile{"file", "a.jpg", encodeTestImage(t, "jpeg", 200, 100)})
+		require.Equal(t, http.StatusOK, code)
+		data := body["data"].(map[string]interface{})
+		url := data["url"].(string)
+		thumbURL := data["thumbUrl"].(string)
+		assert.Equal(t, strings.TrimSuffix(url, ".jpg")+"_thumb.jpg", thumbURL)
+
+		thumb, format, err := image.Decode(bytes.NewReader(storage.files[strings.TrimPrefix(thumbURL, "/uploads/")]))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, image.Rect(0, 0, 50, 25), thumb.Bounds())
+	})
+
+	t.Run("ChunkedThumbnail", func(t *testing.T) {
+		storage := &memoryStorage{}
+		content := encodeTestImage(t, "png", 120, 60)
+		upload := NewUpload("cover", "封面").Accept("image/*").Chunked(256).Thumbnail(30, 0)
+		client := &chunkClient{t: t, handler: UploadHandler(upload, storage)}
+
+		_, session := client.init("", "a.png", len(content), "")
+		id := session["id"].(string)
+		for i := 0; i*256 < len(content); i++ {
+			end := min((i+1)*256, len(content))
+			code, _ := client.chunk(id, i, content[i*256:end])
+			require.Equal(t, http.StatusOK, code)
+		}
+
+		code, resp := client.post("op=complete&id="+id, nil, nil)
+		require.Equal(t, http.StatusOK, code)
+		data := resp["data"].(map[string]interface{})
+		assert.Equal(t, float64(120), data["width"])
+		assert.True(t, strings.HasSuffix(data["thumbUrl"].(string), "_thumb.png"))
+		assert.Len(t, storage.files, 2)
+	})
+}
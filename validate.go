@@ -78,20 +78,32 @@ func (r LengthRule) ToMap() map[string]interface{} {
 //	    Message: "请输入0-100之间的数字",
 //	})
 type RangeRule struct {
-	Min     float64 // 最小值
-	Max     float64 // 最大值
+	Min     float64 // 最小值，为0时不限制
+	Max     float64 // 最大值，为0时不限制
+	MinSet  bool    // Min为0时同样作为下限，如限制不能为负数
+	MaxSet  bool    // Max为0时同样作为上限
 	Message string  // 验证失败提示信息
 	Trigger string  // 触发方式：blur, change
 }
 
+// hasMin 是否限制最小值
+func (r RangeRule) hasMin() bool {
+	return r.Min != 0 || r.MinSet
+}
+
+// hasMax 是否限制最大值
+func (r RangeRule) hasMax() bool {
+	return r.Max != 0 || r.MaxSet
+}
+
 // ToMap 实现ValidateRule接口
 func (r RangeRule) ToMap() map[string]interface{} {
 	rule := make(map[string]interface{})
 	rule["type"] = "number"
-	if r.Min != 0 {
+	if r.hasMin() {
 		rule["min"] = r.Min
 	}
-	if r.Max != 0 {
+	if r.hasMax() {
 		rule["max"] = r.Max
 	}
 	if r.Message != "" {
@@ -103,6 +115,14 @@ func (r RangeRule) ToMap() map[string]interface{} {
 	return rule
 }
 
+// numberRuleTypes 值为数字的组件类型
+// 导入设计器规则和加载定义文件时，这些组件的min、max验证按数值范围（RangeRule）处理
+var numberRuleTypes = map[string]bool{
+	"inputNumber": true,
+	"slider":      true,
+	"rate":        true,
+}
+
 // EmailRule 邮箱验证规则
 // 使用内置的email类型验证
 //
@@ -226,7 +246,7 @@ func (r EnumRule) ToMap() map[string]interface{} {
 //	    },
 //	})
 type CustomRule struct {
-	Validator string                 // JavaScript验证函数字符串
+	Validator string                 // JavaScript验证函数，FormScript中作为JSFunc输出
 	Message   string                 // 验证失败提示信息
 	Trigger   string                 // 触发方式：blur, change
 	Rule      map[string]interface{} // 完全自定义的规则对象（优先级高于Validator）
@@ -242,7 +262,7 @@ func (r CustomRule) ToMap() map[string]interface{} {
 	// 否则使用Validator字符串
 	rule := make(map[string]interface{})
 	if r.Validator != "" {
-		rule["validator"] = r.Validator
+		rule["validator"] = JSFunc(r.Validator)
 	}
 	if r.Message != "" {
 		rule["message"] = r.Message
@@ -254,16 +274,16 @@ func (r CustomRule) ToMap() map[string]interface{} {
 }
 
 // WhitespaceRule 空白字符验证规则
-// 验证字符串是否只包含空白字符（默认不允许）
+// 验证字符串是否只包含空白字符（与async-validator的whitespace语义一致）
 //
 // 使用示例：
 //
 //	input.Validate(WhitespaceRule{
-//	    Whitespace: false,  // 不允许只有空白字符
+//	    Whitespace: true,  // 不允许只有空白字符
 //	    Message: "不能只输入空格",
 //	})
 type WhitespaceRule struct {
-	Whitespace bool   // true=允许空白字符，false=不允许
+	Whitespace bool   // true=拒绝只包含空白字符的值，false=不检查
 	Message    string // 验证失败提示信息
 	Trigger    string // 触发方式：blur, change
 }
@@ -0,0 +1,482 @@
+package formbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// validator.go 实现服务端验证引擎
+// 在Go端按照async-validator的语义执行ValidateRule，
+// 使同一份表单定义同时驱动前端与后端验证
+
+// Checker 服务端验证接口
+// 实现了Checker的ValidateRule会在Form.ValidateData中执行
+// 未实现该接口的规则（如CustomRule）仅在前端生效
+type Checker interface {
+	// Check 验证字段值，验证失败时返回错误
+	Check(value interface{}, vc *ValidateContext) error
+}
+
+// ValidateContext 验证上下文
+// 提供当前字段名和完整的提交数据
+type ValidateContext struct {
+	// Context 请求上下文，供远程验证等需要访问外部资源的规则使用
+	Context context.Context
+
+	// Field 当前验证的字段名
+	Field string
+
+	// Values 完整的提交数据
+	Values map[string]interface{}
+}
+
+// context 返回请求上下文，未设置时返回context.Background()
+func (vc *ValidateContext) context() context.Context {
+	if vc.Context == nil {
+		return context.Background()
+	}
+	return vc.Context
+}
+
+// ValidateData 在服务端验证提交的数据
+// 遍历当前显示的组件（包括children和已激活control分支中的组件），执行其验证规则，
+// 并检查Select、Radio、Checkbox、Cascader、Tree提交的值是否为可选的选项
+// 未激活分支中的字段不参与验证，见StripHidden
+// ValidateData不执行清理器，直接验证传入的值；自行解析请求时应先调用Sanitize，
+// 使验证的值与保存的值一致（ParseRequest已包含此步骤）
+// 验证通过返回nil，否则返回FieldErrors
+//
+// 使用示例：
+//
+//	values = form.Sanitize(values)
+//	if err := form.ValidateData(values); err != nil {
+//	    var errs FieldErrors
+//	    if errors.As(err, &errs) {
+//	        fmt.Println(errs.ByField())
+//	    }
+//	}
+func (f *Form) ValidateData(values map[string]interface{}) error {
+	return f.ValidateDataContext(context.Background(), values)
+}
+
+// ValidateDataContext 在服务端验证提交的数据
+// ctx会传递给远程验证等规则，用于取消和超时控制
+func (f *Form) ValidateDataContext(ctx context.Context, values map[string]interface{}) error {
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	var errs FieldErrors
+	f.eachActiveComponent(f.rules, values, func(c Component, data *ComponentData) {
+		if data.Field == "" {
+			return
+		}
+		errs = append(errs, checkRules(ctx, data.Field, data.Validate, values)...)
+		if checker, ok := c.(optionChecker); ok && !isEmptyValue(values[data.Field]) {
+			if err := checker.checkOptions(ctx, values[data.Field], f.initialValue(data)); err != nil {
+				errs = append(errs, &FieldError{Field: data.Field, Rule: "option", Message: err.Error()})
+			}
+		}
+	})
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkRules 对单个字段执行全部可在服务端执行的规则
+func checkRules(ctx context.Context, field string, rules []ValidateRule, values map[string]interface{}) FieldErrors {
+	var errs FieldErrors
+	vc := &ValidateContext{Context: ctx, Field: field, Values: values}
+	value := values[field]
+
+	for _, rule := range rules {
+		checker, ok := rule.(Checker)
+		if !ok {
+			continue
+		}
+		if err := checker.Check(value, vc); err != nil {
+			errs = append(errs, toFieldError(field, err))
+		}
+	}
+	return errs
+}
+
+// toFieldError 将Checker返回的错误转换为FieldError
+func toFieldError(field string, err error) *FieldError {
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		result := *fe
+		result.Field = field
+		return &result
+	}
+	return &FieldError{Field: field, Message: err.Error()}
+}
+
+// Check 实现Checker接口
+// 值为nil、空字符串、空数组时验证失败
+func (r RequiredRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return newRuleError("required", r.Message, "此项必填")
+	}
+	return nil
+}
+
+// Check 实现Checker接口
+// 与async-validator一致：正则不做隐式锚定，空值跳过
+// 无法被Go正则引擎编译的表达式（如JS的先行断言）验证失败，而不是跳过，
+// 只需在前端校验的表达式请改用CustomRule
+func (r PatternRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	re, err := compilePattern(r.Pattern)
+	if err != nil {
+		return newRuleError("pattern", "", "正则表达式无效").
+			withParams(map[string]interface{}{"pattern": r.Pattern, "error": err.Error()})
+	}
+	str, ok := stringValue(value)
+	if !ok || !re.MatchString(str) {
+		return newRuleError("pattern", r.Message, "格式不正确").
+			withParams(map[string]interface{}{"pattern": r.Pattern})
+	}
+	return nil
+}
+
+// Check 实现Checker接口
+// 字符串按字符数计算（与async-validator处理代理对的方式一致），数组按元素个数计算
+func (r LengthRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	length, ok := valueLength(value)
+	if !ok {
+		return newRuleError("length", r.Message, "长度不符合要求").
+			withParams(map[string]interface{}{"min": r.Min, "max": r.Max})
+	}
+	if (r.Min > 0 && length < r.Min) || (r.Max > 0 && length > r.Max) {
+		return newRuleError("length", r.Message, "长度不符合要求").
+			withParams(map[string]interface{}{"min": r.Min, "max": r.Max})
+	}
+	return nil
+}
+
+// Check 实现Checker接口
+// 值必须为数字或数字字符串，Min/Max为0且未设置MinSet/MaxSet时表示不限制（与ToMap的输出一致）
+// 数字字符串按十进制文本精确比较（如ParseRequest为InputNumber保留的json.Number）
+func (r RangeRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	num, ok := ratValue(value)
+	if !ok {
+		return newRuleError("range", r.Message, "请输入数字").
+			withParams(map[string]interface{}{"min": r.Min, "max": r.Max})
+	}
+	if (r.hasMin() && num.Cmp(new(big.Rat).SetFloat64(r.Min)) < 0) ||
+		(r.hasMax() && num.Cmp(new(big.Rat).SetFloat64(r.Max)) > 0) {
+		return newRuleError("range", r.Message, "数值超出范围").
+			withParams(map[string]interface{}{"min": r.Min, "max": r.Max})
+	}
+	return nil
+}
+
+// emailPattern 与async-validator相同的邮箱正则
+var emailPattern = regexp.MustCompile(`^(([^<>()\[\]\\.,;:\s@"]+(\.[^<>()\[\]\\.,;:\s@"]+)*)|(".+"))@((\[[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\])|(([a-zA-Z\-0-9\x{00A0}-\x{D7FF}\x{F900}-\x{FDCF}\x{FDF0}-\x{FFEF}]+\.)+[a-zA-Z\x{00A0}-\x{D7FF}\x{F900}-\x{FDCF}\x{FDF0}-\x{FFEF}]{2,}))$`)
+
+// Check 实现Checker接口
+func (r EmailRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok || !emailPattern.MatchString(str) {
+		return newRuleError("email", r.Message, "请输入正确的邮箱地址")
+	}
+	return nil
+}
+
+// Check 实现Checker接口
+// 支持http、https、ftp协议以及协议相对地址（//host/path）
+func (r URLRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	str, ok := value.(string)
+	if !ok || !isURL(str) {
+		return newRuleError("url", r.Message, "请输入正确的URL地址")
+	}
+	return nil
+}
+
+// Check 实现Checker接口
+// 接受time.Time、毫秒时间戳以及常见格式的日期字符串
+func (r DateRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	if _, ok := parseDateValue(value); !ok {
+		return newRuleError("date", r.Message, "请输入正确的日期")
+	}
+	return nil
+}
+
+// Check 实现Checker接口
+func (r EnumRule) Check(value interface{}, vc *ValidateContext) error {
+	if isEmptyValue(value) {
+		return nil
+	}
+	for _, e := range r.Enum {
+		if valuesEqual(value, e) {
+			return nil
+		}
+	}
+	return newRuleError("enum", r.Message, "值不在允许范围内").
+		withParams(map[string]interface{}{"enum": r.Enum})
+}
+
+// Check 实现Checker接口
+// 与async-validator一致：Whitespace为true时拒绝只包含空白字符的字符串
+func (r WhitespaceRule) Check(value interface{}, vc *ValidateContext) error {
+	if !r.Whitespace {
+		return nil
+	}
+	if str, ok := value.(string); ok && str != "" && strings.TrimSpace(str) == "" {
+		return newRuleError("whitespace", r.Message, "不能只输入空白字符")
+	}
+	return nil
+}
+
+// 以下为服务端验证使用的值处理辅助函数
+
+// isEmptyValue 判断值是否为空
+// nil、空字符串、空数组/切片/map视为空
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok {
+		return s == ""
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// stringValue 将字符串或数字转换为字符串
+func stringValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case *big.Rat:
+		if v == nil {
+			return "", false
+		}
+		return decimalString(v), true
+	case bool:
+		return "", false
+	}
+	if num, ok := toFloat(value); ok {
+		return strconv.FormatFloat(num, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// valueLength 计算字符串的字符数或数组的元素个数
+func valueLength(value interface{}) (int, bool) {
+	if s, ok := value.(string); ok {
+		return utf8.RuneCountInString(s), true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), true
+	}
+	if s, ok := stringValue(value); ok {
+		return utf8.RuneCountInString(s), true
+	}
+	return 0, false
+}
+
+// toFloat 将数字或数字字符串转换为float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case *big.Rat:
+		if v == nil {
+			return 0, false
+		}
+		f, _ := v.Float64()
+		return f, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// ratValue 将数字或数字字符串转换为*big.Rat
+// 字符串按十进制文本解析，不经过float64；NaN、Inf和分数形式（如"1/3"）不是数字
+func ratValue(value interface{}) (*big.Rat, bool) {
+	var text string
+	switch v := value.(type) {
+	case *big.Rat:
+		return v, v != nil
+	case string:
+		text = v
+	case json.Number:
+		text = v.String()
+	default:
+		f, ok := toFloat(value)
+		if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(f), true
+	}
+	text = strings.TrimSpace(text)
+	if strings.Contains(text, "/") {
+		return nil, false
+	}
+	return new(big.Rat).SetString(text)
+}
+
+// isNumber 判断值是否为Go数字类型
+func isNumber(value interface{}) bool {
+	if _, ok := value.(string); ok {
+		return false
+	}
+	_, ok := toFloat(value)
+	return ok
+}
+
+// valuesEqual 判断两个值是否相等
+// 不同Go数字类型之间按数值比较（如JSON解析得到的float64与int）
+func valuesEqual(a, b interface{}) bool {
+	if isNumber(a) && isNumber(b) {
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		return fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// dateLayouts 解析日期字符串时尝试的格式
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"2006-01",
+	"15:04:05",
+	"15:04",
+}
+
+// parseDateValue 将值解析为时间
+// 支持time.Time、毫秒时间戳和dateLayouts中的字符串格式
+func parseDateValue(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, !v.IsZero()
+	case string:
+		s := strings.TrimSpace(v)
+		for _, layout := range dateLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+	if ms, ok := toFloat(value); ok {
+		return time.UnixMilli(int64(ms)), true
+	}
+	return time.Time{}, false
+}
+
+// isURL 判断字符串是否为合法的URL
+func isURL(s string) bool {
+	if strings.ContainsAny(s, " \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ftp":
+		return true
+	case "":
+		return strings.HasPrefix(s, "//")
+	}
+	return false
+}
+
+// patternCache 缓存已编译的正则表达式
+var patternCache sync.Map
+
+// compilePattern 编译并缓存正则表达式
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,175 @@
+package formbuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validator_test.go 测试服务端验证引擎
+
+// TestRuleCheck 测试各验证规则的服务端执行
+func TestRuleCheck(t *testing.T) {
+	vc := &ValidateContext{Field: "f", Values: map[string]interface{}{}}
+
+	cases := []struct {
+		name  string
+		rule  Checker
+		value interface{}
+		valid bool
+	}{
+		{"RequiredNil", RequiredRule{}, nil, false},
+		{"RequiredEmptyString", RequiredRule{}, "", false},
+		{"RequiredEmptySlice", RequiredRule{}, []interface{}{}, false},
+		{"RequiredZero", RequiredRule{}, 0, true},
+		{"RequiredFalse", RequiredRule{}, false, true},
+		{"RequiredString", RequiredRule{}, "a", true},
+
+		{"PatternMatch", PatternRule{Pattern: "^1[3-9]\\d{9}$"}, "13800138000", true},
+		{"PatternMismatch", PatternRule{Pattern: "^1[3-9]\\d{9}$"}, "12345", false},
+		{"PatternUnanchored", PatternRule{Pattern: "\\d+"}, "abc123", true},
+		{"PatternNumber", PatternRule{Pattern: "^\\d+$"}, float64(42), true},
+		{"PatternEmptySkipped", PatternRule{Pattern: "^\\d+$"}, "", true},
+		{"PatternUnsupportedFails", PatternRule{Pattern: "^(?=a)"}, "b", false},
+
+		{"LengthOK", LengthRule{Min: 2, Max: 4}, "abc", true},
+		{"LengthTooShort", LengthRule{Min: 2}, "a", false},
+		{"LengthTooLong", LengthRule{Max: 2}, "abc", false},
+		{"LengthRunes", LengthRule{Max: 2}, "中文", true},
+		{"LengthSlice", LengthRule{Max: 2}, []interface{}{1, 2, 3}, false},
+
+		{"RangeOK", RangeRule{Min: 1, Max: 10}, float64(5), true},
+		{"RangeBelow", RangeRule{Min: 1, Max: 10}, 0, false},
+		{"RangeAbove", RangeRule{Min: 1, Max: 10}, 11, false},
+		{"RangeZeroMinUnbounded", RangeRule{Max: 10}, -100, true},
+		{"RangeZeroMinSet", RangeRule{MinSet: true}, -1, false},
+		{"RangeZeroMaxSet", RangeRule{MaxSet: true}, 1, false},
+		{"RangeNumericString", RangeRule{Max: 10}, "5", true},
+		{"RangeNumericStringAbove", RangeRule{Max: 10}, " 10.5 ", false},
+		{"RangeJSONNumber", RangeRule{Min: 1, Max: 10}, json.Number("9007199254740993"), false},
+		{"RangeFractionString", RangeRule{Max: 10}, "1/2", false},
+		{"RangeNotNumber", RangeRule{Max: 10}, "abc", false},
+
+		{"EmailOK", EmailRule{}, "user@example.com", true},
+		{"EmailBad", EmailRule{}, "user@", false},
+		{"EmailNotString", EmailRule{}, 1, false},
+
+		{"URLOK", URLRule{}, "https://example.com/path?q=1", true},
+		{"URLProtocolRelative", URLRule{}, "//example.com", true},
+		{"URLBadScheme", URLRule{}, "javascript://alert(1)", false},
+		{"URLNoHost", URLRule{}, "example", false},
+
+		{"DateString", DateRule{}, "2024-01-02", true},
+		{"DateTime", DateRule{}, time.Now(), true},
+		{"DateTimestamp", DateRule{}, float64(1700000000000), true},
+		{"DateBad", DateRule{}, "not a date", false},
+
+		{"EnumOK", EnumRule{Enum: []interface{}{"male", "female"}}, "male", true},
+		{"EnumNumeric", EnumRule{Enum: []interface{}{1, 2}}, float64(2), true},
+		{"EnumBad", EnumRule{Enum: []interface{}{"male", "female"}}, "other", false},
+
+		{"WhitespaceRejected", WhitespaceRule{Whitespace: true}, "   ", false},
+		{"WhitespaceDisabled", WhitespaceRule{Whitespace: false}, "   ", true},
+		{"WhitespaceText", WhitespaceRule{Whitespace: true}, " a ", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.Check(tc.value, vc)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+// TestNilDecimal 测试nil的*big.Rat不作为数字处理
+func TestNilDecimal(t *testing.T) {
+	var nilRat *big.Rat
+
+	_, ok := toFloat(nilRat)
+	assert.False(t, ok)
+	_, ok = stringValue(nilRat)
+	assert.False(t, ok)
+	assert.False(t, isNumber(nilRat))
+	assert.False(t, valuesEqual(nilRat, 0))
+	assert.NoError(t, RangeRule{Max: 10}.Check(nilRat, &ValidateContext{Field: "f"}), "nil按空值跳过")
+}
+
+// TestRuleCheckMessage 测试错误信息
+func TestRuleCheckMessage(t *testing.T) {
+	t.Run("CustomMessage", func(t *testing.T) {
+		err := RequiredRule{Message: "请输入用户名"}.Check(nil, &ValidateContext{})
+		var fe *FieldError
+		require.True(t, errors.As(err, &fe))
+		assert.Equal(t, "required", fe.Rule)
+		assert.Equal(t, "请输入用户名", fe.Message)
+	})
+
+	t.Run("DefaultMessage", func(t *testing.T) {
+		err := EmailRule{}.Check("bad", &ValidateContext{})
+		var fe *FieldError
+		require.True(t, errors.As(err, &fe))
+		assert.Equal(t, "请输入正确的邮箱地址", fe.Message)
+	})
+}
+
+// TestFormValidateData 测试Form.ValidateData
+func TestFormValidateData(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		form := createTestForm()
+		err := form.ValidateData(map[string]interface{}{"username": "admin"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("PerFieldErrors", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewInput("username", "用户名").Required(),
+			Email("email", "邮箱").Required(),
+			NewInput("nickname", "昵称"),
+		}, nil)
+
+		err := form.ValidateData(map[string]interface{}{"email": "bad"})
+		require.Error(t, err)
+
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Equal(t, map[string][]string{
+			"username": {"此项必填"},
+			"email":    {"请输入正确的邮箱地址"},
+		}, errs.ByField())
+		assert.Len(t, errs.Get("email"), 1)
+		assert.Equal(t, "email", errs.Get("email")[0].Rule)
+	})
+
+	t.Run("ControlAndChildren", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewRadio("type", "类型", "1").Control([]ControlRule{
+				{Value: "1", Rule: []Component{NewInput("a", "A").Required()}},
+			}),
+			NewInput("group", "分组").Children([]Component{
+				NewInput("b", "B").Validate(NewLength(2, 0, "至少2个字符")),
+			}),
+		}, nil)
+
+		err := form.ValidateData(map[string]interface{}{"type": "1", "b": "x"})
+		var errs FieldErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Len(t, errs.Get("a"), 1)
+		assert.Equal(t, "至少2个字符", errs.Get("b")[0].Message)
+	})
+
+	t.Run("CustomRuleSkipped", func(t *testing.T) {
+		form := NewElmForm("/submit", []Component{
+			NewInput("code", "编码").Validate(CustomRule{Validator: "function(){}"}),
+		}, nil)
+		assert.NoError(t, form.ValidateData(nil))
+	})
+}